@@ -176,7 +176,7 @@ const AssessmentSession: React.FC = () => {
     onSessionFinished: () => {
       console.log('Speaker diarization session finished');
       // Send final consolidated transcript to server
-      syncConsolidatedTranscriptToServer();
+      syncConsolidatedTranscriptToServer(true);
     }
   });
   
@@ -194,6 +194,103 @@ const AssessmentSession: React.FC = () => {
     participantsRef.current = participants;
   }, [participants]);
 
+  // Live assessment events over Server-Sent Events. fetch is used instead of EventSource
+  // so the auth header can be sent; polling only runs while the stream is down.
+  const eventStreamConnectedRef = useRef(false);
+  useEffect(() => {
+    if (!sessionId) return;
+
+    const controller = new AbortController();
+    let lastEventId = '';
+
+    const handleEvent = (type: string, data: any) => {
+      switch (type) {
+        case 'participant.assessed':
+          setParticipants(prev => prev.map(p =>
+            data.participant_id === p.id.toString() ? { ...p, assessmentResults: data.results } : p
+          ));
+          break;
+        case 'group.assessed':
+          setGroupAssessment(data);
+          break;
+        case 'session.consolidated':
+          setParticipants(prev => prev.map(p => {
+            const assessment = (data.assessments || []).find((a: any) => a.participant_id === p.id.toString());
+            return assessment ? { ...p, assessmentResults: assessment.results } : p;
+          }));
+          if (data.group_assessment) {
+            setGroupAssessment(data.group_assessment);
+          }
+          break;
+        case 'job.failed':
+          console.error(`Assessment job ${data.id} failed:`, data.last_error);
+          break;
+        default:
+          console.log(`[Session event] ${type}`, data);
+      }
+    };
+
+    const connect = async () => {
+      while (!controller.signal.aborted) {
+        try {
+          const headers: Record<string, string> = {
+            'Accept': 'text/event-stream',
+            'Authorization': `Bearer ${localStorage.getItem('authToken')}`
+          };
+          if (lastEventId) {
+            headers['Last-Event-ID'] = lastEventId;
+          }
+          const response = await fetch(`/api/v1/sessions/${sessionId}/events`, { headers, signal: controller.signal });
+          if (!response.ok || !response.body) {
+            throw new Error(`event stream returned ${response.status}`);
+          }
+          eventStreamConnectedRef.current = true;
+
+          const reader = response.body.getReader();
+          const decoder = new TextDecoder();
+          let buffer = '';
+          while (true) {
+            const { done, value } = await reader.read();
+            if (done) break;
+            buffer += decoder.decode(value, { stream: true });
+
+            let boundary;
+            while ((boundary = buffer.indexOf('\n\n')) >= 0) {
+              const frame = buffer.slice(0, boundary);
+              buffer = buffer.slice(boundary + 2);
+
+              let eventId = '';
+              let eventType = 'message';
+              let data = '';
+              for (const line of frame.split('\n')) {
+                if (line.startsWith('id: ')) eventId = line.slice(4);
+                else if (line.startsWith('event: ')) eventType = line.slice(7);
+                else if (line.startsWith('data: ')) data += line.slice(6);
+              }
+              // Comment-only frames are heartbeats
+              if (!data) continue;
+              if (eventId) lastEventId = eventId;
+              handleEvent(eventType, JSON.parse(data).data);
+            }
+          }
+        } catch (error) {
+          if (controller.signal.aborted) return;
+          console.error('Session event stream error:', error);
+        }
+
+        // Reconnect and replay from the last event received
+        eventStreamConnectedRef.current = false;
+        await new Promise(resolve => setTimeout(resolve, 5000));
+      }
+    };
+
+    connect();
+    return () => {
+      controller.abort();
+      eventStreamConnectedRef.current = false;
+    };
+  }, [sessionId]);
+
   const fetchAssessmentData = async () => {
     if (!id) return;
     
@@ -591,7 +688,9 @@ const AssessmentSession: React.FC = () => {
         body: JSON.stringify({
           session_id: sessionId,
           conversation: consolidatedConversation,
-          timestamp: Date.now()
+          timestamp: Date.now(),
+          // Manual requests are assessed even if the transcript barely changed
+          final: true
         })
       });
 
@@ -679,8 +778,9 @@ const AssessmentSession: React.FC = () => {
     startSession();
   };
 
-  // Sync consolidated transcript to server (non-blocking)
-  const syncConsolidatedTranscriptToServer = () => {
+  // Sync consolidated transcript to server (non-blocking). Periodic syncs are only
+  // re-assessed once the transcript has grown; the final sync is always assessed.
+  const syncConsolidatedTranscriptToServer = (final = false) => {
     // Use setTimeout to make it fully async and non-blocking
     setTimeout(async () => {
       try {
@@ -725,7 +825,8 @@ const AssessmentSession: React.FC = () => {
           body: JSON.stringify({
             session_id: sessionId,
             conversation: consolidatedConversation,
-            timestamp: Date.now()
+            timestamp: Date.now(),
+            final
           })
         });
         
@@ -816,7 +917,7 @@ const AssessmentSession: React.FC = () => {
     }
 
     // Send final consolidated transcript to server
-    syncConsolidatedTranscriptToServer();
+    syncConsolidatedTranscriptToServer(true);
 
     // Stop audio recording and submit for async transcription
     try {
@@ -830,7 +931,7 @@ const AssessmentSession: React.FC = () => {
         setRecordedAudioBlob(recordedAudio.blob);
 
         // Submit for async transcription
-        await submitAsyncTranscription(recordedAudio.blob);
+        await submitAsyncTranscription(recordedAudio.blob, recordedAudio.duration);
       } else {
         console.warn('No audio recorded or recording failed');
       }
@@ -842,7 +943,7 @@ const AssessmentSession: React.FC = () => {
   };
 
   // Submit audio for async transcription
-  const submitAsyncTranscription = async (audioBlob: Blob) => {
+  const submitAsyncTranscription = async (audioBlob: Blob, durationMs?: number) => {
     if (!sessionId) {
       console.error('No session ID available for async transcription');
       return;
@@ -853,8 +954,12 @@ const AssessmentSession: React.FC = () => {
       console.log('Submitting audio for async transcription...');
 
       // Prepare form data
+      // Fields go before the audio so the server can check them before streaming it on
       const formData = new FormData();
       formData.append('session_id', sessionId);
+      if (durationMs) {
+        formData.append('duration_ms', String(Math.round(durationMs)));
+      }
 
       // Determine file extension from MIME type
       let fileExtension = 'webm';
@@ -909,6 +1014,14 @@ const AssessmentSession: React.FC = () => {
         body: formData
       });
 
+      if (response.status === 413 || response.status === 415) {
+        // Too large/long or not a supported audio format; retrying won't help
+        const result = await response.json().catch(() => ({}));
+        console.error('Async transcription rejected:', result.error || response.statusText);
+        setAsyncTranscriptionStatus('error');
+        return;
+      }
+
       if (!response.ok) {
         throw new Error(`Failed to submit async transcription: ${response.statusText}`);
       }
@@ -955,11 +1068,19 @@ const AssessmentSession: React.FC = () => {
           if (result.status === 'completed' && result.segments) {
             // Update transcript with improved results
             setSpeakerSegments(result.segments);
+
+            // Speaker names follow once the server has identified every speaker
+            if (!result.speakers_named && attempts < maxAttempts) {
+              setTimeout(poll, 5000);
+              return;
+            }
             setAsyncTranscriptionProgress(100);
             setAsyncTranscriptionStatus('completed');
 
-            // Sync improved transcript to backend for re-assessment
-            await syncImprovedTranscript(result.segments);
+            // The server queues re-assessment of the improved transcript itself
+            if (result.assessment_job_id) {
+              pollForConsolidatedAssessmentResults(sessionId);
+            }
           } else if (result.status === 'error') {
             console.error('Async transcription failed:', result.error);
             setAsyncTranscriptionStatus('error');
@@ -995,57 +1116,14 @@ const AssessmentSession: React.FC = () => {
     poll();
   };
 
-  // Sync improved transcript to backend for re-assessment
-  const syncImprovedTranscript = async (segments: any[]) => {
-    if (!sessionId) return;
-
-    try {
-      console.log('Syncing improved transcript for re-assessment...');
-
-      // Build unified transcript from segments
-      let unifiedTranscript = '';
-      for (const seg of segments) {
-        unifiedTranscript += `[${seg.name}]: ${seg.text}\n`;
-      }
-
-      // Format for backend consolidated transcript API
-      const conversation = [{
-        participant_id: 'unified',
-        participant_name: 'All Speakers',
-        role: 'Group',
-        transcript: unifiedTranscript
-      }];
-
-      // Sync consolidated transcript
-      const response = await fetch('/api/v1/sessions/consolidated-transcript-sync', {
-        method: 'POST',
-        headers: {
-          'Content-Type': 'application/json',
-          'Authorization': `Bearer ${localStorage.getItem('authToken')}`
-        },
-        body: JSON.stringify({
-          session_id: sessionId,
-          conversation: conversation
-        })
-      });
-
-      if (!response.ok) {
-        throw new Error(`Failed to sync improved transcript: ${response.statusText}`);
-      }
-
-      console.log('Improved transcript synced successfully');
-
-      // Trigger re-assessment with improved transcript
-      pollForConsolidatedAssessmentResults(sessionId);
-    } catch (error) {
-      console.error('Error syncing improved transcript:', error);
-    }
-  };
-
   // Poll for consolidated assessment results
   const pollForConsolidatedAssessmentResults = async (sessionId: string) => {
+    if (eventStreamConnectedRef.current) {
+      console.log('Live event stream connected - results will be pushed, skipping polling');
+      return;
+    }
     console.log(`Starting to poll for consolidated assessment results for session ${sessionId}...`);
-    
+
     const maxAttempts = 6; // Poll for 1 minute (6 attempts × 10 seconds)
     let attempts = 0;
     
@@ -1065,7 +1143,13 @@ const AssessmentSession: React.FC = () => {
         if (response.ok) {
           const result = await response.json();
           console.log(`Consolidated assessment results received:`, result);
-          
+
+          // The assessment job ended without results - stop polling
+          if (result.status === 'failed' || result.status === 'cancelled') {
+            console.error(`Assessment job ${result.status}:`, result.error);
+            return;
+          }
+
           // Update all participants with their assessment results
           if (result.assessments && Array.isArray(result.assessments)) {
             console.log('Received consolidated assessment results:', result);
@@ -1088,6 +1172,12 @@ const AssessmentSession: React.FC = () => {
             // Don't auto-switch to assessment tab - let user control navigation
             // setActiveMainTab('assessment');
           }
+
+          // Partial results are published as each participant finishes - keep polling for the rest
+          if (result.partial && attempts < maxAttempts) {
+            console.log(`Partial assessment results, still pending: ${(result.pending || []).join(', ')}`);
+            setTimeout(poll, 10000);
+          }
           return;
         } else if (response.status === 404) {
           // Results not ready yet, continue polling if we haven't exceeded max attempts
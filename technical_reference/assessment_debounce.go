@@ -0,0 +1,162 @@
+package assessment
+
+import (
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// DebounceConfig controls when a growing live transcript is re-assessed
+type DebounceConfig struct {
+	// MinGrowth is how many characters a transcript must grow by since its last
+	// assessment before it is assessed again
+	MinGrowth int
+	// IdleDelay re-assesses smaller growth once the transcript stops growing for this
+	// long; 0 waits for the session to close
+	IdleDelay time.Duration
+}
+
+// DebounceConfigFromEnv reads ASSESSMENT_REASSESS_MIN_CHARS and ASSESSMENT_REASSESS_IDLE
+// (a Go duration such as "2m")
+func DebounceConfigFromEnv() DebounceConfig {
+	config := DebounceConfig{
+		MinGrowth: 600,
+		IdleDelay: 2 * time.Minute,
+	}
+	if growth, err := strconv.Atoi(os.Getenv("ASSESSMENT_REASSESS_MIN_CHARS")); err == nil && growth >= 0 {
+		config.MinGrowth = growth
+	}
+	if idle, err := time.ParseDuration(os.Getenv("ASSESSMENT_REASSESS_IDLE")); err == nil && idle >= 0 {
+		config.IdleDelay = idle
+	}
+	return config
+}
+
+// TranscriptDebouncer decides when a growing transcript is worth re-assessing. It only
+// remembers lengths within this process; after a restart the first check of each
+// transcript assesses it, and the job queue's payload deduplication drops repeats.
+type TranscriptDebouncer struct {
+	mu     sync.Mutex
+	config DebounceConfig
+	onIdle func(sessionID, participantID string)
+	state  map[debounceKey]*debounceState
+}
+
+type debounceKey struct {
+	sessionID     string
+	participantID string
+}
+
+type debounceState struct {
+	assessed int
+	length   int
+	timer    *time.Timer
+}
+
+// NewTranscriptDebouncer creates a debouncer. onIdle, if set, runs when a transcript with
+// unassessed growth has been quiet for IdleDelay.
+func NewTranscriptDebouncer(config DebounceConfig, onIdle func(sessionID, participantID string)) *TranscriptDebouncer {
+	return &TranscriptDebouncer{
+		config: config,
+		onIdle: onIdle,
+		state:  make(map[debounceKey]*debounceState),
+	}
+}
+
+// Observe records a transcript's current length in characters and reports whether it
+// has grown enough since it was last assessed to assess it now
+func (d *TranscriptDebouncer) Observe(sessionID, participantID string, length int) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	key := debounceKey{sessionID, participantID}
+	state := d.get(key)
+	state.length = length
+	if length-state.assessed >= d.config.MinGrowth {
+		return true
+	}
+
+	if d.onIdle != nil && d.config.IdleDelay > 0 && length > state.assessed {
+		if state.timer != nil {
+			state.timer.Stop()
+		}
+		state.timer = time.AfterFunc(d.config.IdleDelay, func() { d.idle(key) })
+	}
+	return false
+}
+
+// Flush reports whether a transcript has any growth since it was last assessed, for
+// when no more is coming
+func (d *TranscriptDebouncer) Flush(sessionID, participantID string, length int) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	state := d.get(debounceKey{sessionID, participantID})
+	state.length = length
+	return length > state.assessed
+}
+
+// Assessed records that a transcript of the given length has been queued for assessment
+func (d *TranscriptDebouncer) Assessed(sessionID, participantID string, length int) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	state := d.get(debounceKey{sessionID, participantID})
+	state.assessed = max(state.assessed, length)
+	if state.timer != nil {
+		state.timer.Stop()
+		state.timer = nil
+	}
+}
+
+// Reset forgets that a transcript was assessed, so its next check assesses it again.
+// It is used when the assessment that was queued failed or was cancelled.
+func (d *TranscriptDebouncer) Reset(sessionID, participantID string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if state, ok := d.state[debounceKey{sessionID, participantID}]; ok {
+		state.assessed = 0
+	}
+}
+
+// Forget drops everything remembered about a session's transcripts
+func (d *TranscriptDebouncer) Forget(sessionID string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for key, state := range d.state {
+		if key.sessionID != sessionID {
+			continue
+		}
+		if state.timer != nil {
+			state.timer.Stop()
+		}
+		delete(d.state, key)
+	}
+}
+
+// get returns a transcript's state, creating it if needed. Callers hold d.mu.
+func (d *TranscriptDebouncer) get(key debounceKey) *debounceState {
+	state, ok := d.state[key]
+	if !ok {
+		state = &debounceState{}
+		d.state[key] = state
+	}
+	return state
+}
+
+func (d *TranscriptDebouncer) idle(key debounceKey) {
+	d.mu.Lock()
+	state, ok := d.state[key]
+	pending := ok && state.length > state.assessed
+	if ok {
+		state.timer = nil
+	}
+	d.mu.Unlock()
+
+	if pending {
+		d.onIdle(key.sessionID, key.participantID)
+	}
+}
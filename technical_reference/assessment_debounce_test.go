@@ -0,0 +1,106 @@
+package assessment
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTranscriptDebouncer(t *testing.T) {
+	type check struct {
+		action string // "observe", "flush", "assessed" or "reset"
+		length int
+		want   bool
+	}
+	tests := []struct {
+		name   string
+		checks []check
+	}{
+		{
+			name: "growth below the threshold is held back",
+			checks: []check{
+				{action: "observe", length: 50, want: false},
+				{action: "observe", length: 99, want: false},
+				{action: "observe", length: 100, want: true},
+			},
+		},
+		{
+			name: "growth is counted from the last assessment",
+			checks: []check{
+				{action: "observe", length: 120, want: true},
+				{action: "assessed", length: 120},
+				{action: "observe", length: 200, want: false},
+				{action: "observe", length: 220, want: true},
+			},
+		},
+		{
+			name: "flush assesses any growth",
+			checks: []check{
+				{action: "assessed", length: 120},
+				{action: "flush", length: 120, want: false},
+				{action: "flush", length: 121, want: true},
+			},
+		},
+		{
+			name: "reset after a failed assessment",
+			checks: []check{
+				{action: "assessed", length: 120},
+				{action: "flush", length: 120, want: false},
+				{action: "reset"},
+				{action: "flush", length: 120, want: true},
+				{action: "observe", length: 120, want: true},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewTranscriptDebouncer(DebounceConfig{MinGrowth: 100}, nil)
+			for i, check := range tt.checks {
+				var got bool
+				switch check.action {
+				case "observe":
+					got = d.Observe("session", "p1", check.length)
+				case "flush":
+					got = d.Flush("session", "p1", check.length)
+				case "assessed":
+					d.Assessed("session", "p1", check.length)
+					continue
+				case "reset":
+					d.Reset("session", "p1")
+					continue
+				}
+				if got != check.want {
+					t.Fatalf("check %d: %s(%d) = %v, want %v", i, check.action, check.length, got, check.want)
+				}
+			}
+		})
+	}
+}
+
+func TestTranscriptDebouncerIdle(t *testing.T) {
+	idle := make(chan string, 1)
+	d := NewTranscriptDebouncer(DebounceConfig{MinGrowth: 100, IdleDelay: 20 * time.Millisecond}, func(sessionID, participantID string) {
+		idle <- participantID
+	})
+
+	if d.Observe("session", "p1", 10) {
+		t.Fatal("short transcript assessed straight away")
+	}
+	select {
+	case participantID := <-idle:
+		if participantID != "p1" {
+			t.Fatalf("idle callback for %q, want p1", participantID)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("idle callback never ran")
+	}
+
+	// Once assessed, nothing is pending for the timer
+	d.Observe("session", "p2", 10)
+	d.Assessed("session", "p2", 10)
+	select {
+	case participantID := <-idle:
+		t.Fatalf("idle callback for assessed transcript %q", participantID)
+	case <-time.After(60 * time.Millisecond):
+	}
+}
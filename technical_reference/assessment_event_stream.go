@@ -0,0 +1,141 @@
+package assessment
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// EventStream keeps a short per-session history of events and fans new events out to
+// live subscribers, so a reconnecting client can resume from its last event ID. The
+// history is process-local; a client that reconnects to another instance replays
+// whatever that instance has seen.
+type EventStream struct {
+	mu        sync.Mutex
+	history   int
+	retention time.Duration
+	buffer    int
+	sessions  map[string]*sessionEvents
+}
+
+type sessionEvents struct {
+	events      []AssessmentEvent
+	subscribers map[*EventSubscription]struct{}
+	updated     time.Time
+}
+
+// EventSubscription receives a session's events until it is closed
+type EventSubscription struct {
+	stream    *EventStream
+	sessionID string
+	events    chan AssessmentEvent
+	closed    bool
+}
+
+// NewEventStream creates a stream keeping up to history events per session for
+// retention after the session's last event
+func NewEventStream(history int, retention time.Duration) *EventStream {
+	return &EventStream{
+		history:   history,
+		retention: retention,
+		buffer:    64,
+		sessions:  make(map[string]*sessionEvents),
+	}
+}
+
+// Publish records the event and sends it to the session's subscribers. A subscriber
+// that has fallen a whole buffer behind is closed; it catches up by reconnecting.
+func (s *EventStream) Publish(ctx context.Context, event AssessmentEvent) {
+	if event.SessionID == "" {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	s.prune(now)
+
+	session := s.session(event.SessionID)
+	session.updated = now
+	session.events = append(session.events, event)
+	if len(session.events) > s.history {
+		session.events = append([]AssessmentEvent(nil), session.events[len(session.events)-s.history:]...)
+	}
+
+	for subscriber := range session.subscribers {
+		select {
+		case subscriber.events <- event:
+		default:
+			s.closeLocked(subscriber)
+		}
+	}
+}
+
+// Subscribe starts receiving a session's events. It returns the recorded events after
+// lastEventID; an empty or no longer recorded lastEventID replays the whole history.
+func (s *EventStream) Subscribe(sessionID, lastEventID string) ([]AssessmentEvent, *EventSubscription) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session := s.session(sessionID)
+	replay := session.events
+	if lastEventID != "" {
+		for i, event := range session.events {
+			if event.ID == lastEventID {
+				replay = session.events[i+1:]
+				break
+			}
+		}
+	}
+
+	subscription := &EventSubscription{
+		stream:    s,
+		sessionID: sessionID,
+		events:    make(chan AssessmentEvent, s.buffer),
+	}
+	session.subscribers[subscription] = struct{}{}
+	return append([]AssessmentEvent(nil), replay...), subscription
+}
+
+// session returns a session's events, creating them if needed. Callers hold s.mu.
+func (s *EventStream) session(sessionID string) *sessionEvents {
+	session, ok := s.sessions[sessionID]
+	if !ok {
+		session = &sessionEvents{subscribers: make(map[*EventSubscription]struct{}), updated: time.Now()}
+		s.sessions[sessionID] = session
+	}
+	return session
+}
+
+// prune forgets sessions nobody is watching that have been quiet for the retention period
+func (s *EventStream) prune(now time.Time) {
+	for sessionID, session := range s.sessions {
+		if len(session.subscribers) == 0 && now.Sub(session.updated) > s.retention {
+			delete(s.sessions, sessionID)
+		}
+	}
+}
+
+func (s *EventStream) closeLocked(subscription *EventSubscription) {
+	if subscription.closed {
+		return
+	}
+	subscription.closed = true
+	close(subscription.events)
+	if session, ok := s.sessions[subscription.sessionID]; ok {
+		delete(session.subscribers, subscription)
+	}
+}
+
+// Events returns the channel of new events. It is closed when the subscription ends.
+func (sub *EventSubscription) Events() <-chan AssessmentEvent {
+	return sub.events
+}
+
+// Close stops the subscription
+func (sub *EventSubscription) Close() {
+	sub.stream.mu.Lock()
+	defer sub.stream.mu.Unlock()
+	sub.stream.closeLocked(sub)
+}
@@ -0,0 +1,90 @@
+package assessment
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// eventIDs returns the IDs of events, in order
+func eventIDs(events []AssessmentEvent) []string {
+	ids := []string{}
+	for _, event := range events {
+		ids = append(ids, event.ID)
+	}
+	return ids
+}
+
+func TestEventStreamReplay(t *testing.T) {
+	tests := []struct {
+		name        string
+		history     int
+		lastEventID string
+		want        []string
+	}{
+		{name: "new subscriber", history: 10, want: []string{"e1", "e2", "e3"}},
+		{name: "resumes after last event", history: 10, lastEventID: "e1", want: []string{"e2", "e3"}},
+		{name: "up to date", history: 10, lastEventID: "e3", want: []string{}},
+		{name: "unknown last event", history: 10, lastEventID: "elsewhere", want: []string{"e1", "e2", "e3"}},
+		{name: "last event dropped from history", history: 2, lastEventID: "e1", want: []string{"e2", "e3"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			stream := NewEventStream(tt.history, time.Hour)
+			for _, id := range []string{"e1", "e2", "e3"} {
+				stream.Publish(ctx, AssessmentEvent{ID: id, Type: EventParticipantAssessed, SessionID: "session"})
+			}
+			stream.Publish(ctx, AssessmentEvent{ID: "other", Type: EventParticipantAssessed, SessionID: "other-session"})
+
+			replay, subscription := stream.Subscribe("session", tt.lastEventID)
+			defer subscription.Close()
+			got := eventIDs(replay)
+			if len(got) != len(tt.want) {
+				t.Fatalf("replayed %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("replayed %v, want %v", got, tt.want)
+				}
+			}
+
+			// Events published after subscribing arrive live, and only the session's
+			stream.Publish(ctx, AssessmentEvent{ID: "other-2", Type: EventParticipantAssessed, SessionID: "other-session"})
+			stream.Publish(ctx, AssessmentEvent{ID: "e4", Type: EventGroupAssessed, SessionID: "session"})
+			select {
+			case event := <-subscription.Events():
+				if event.ID != "e4" {
+					t.Errorf("live event %s, want e4", event.ID)
+				}
+			case <-time.After(time.Second):
+				t.Fatal("live event not delivered")
+			}
+		})
+	}
+}
+
+func TestEventStreamClosesSlowSubscriber(t *testing.T) {
+	ctx := context.Background()
+	stream := NewEventStream(10, time.Hour)
+	stream.buffer = 1
+	_, subscription := stream.Subscribe("session", "")
+
+	stream.Publish(ctx, AssessmentEvent{ID: "e1", SessionID: "session"})
+	stream.Publish(ctx, AssessmentEvent{ID: "e2", SessionID: "session"})
+
+	if event := <-subscription.Events(); event.ID != "e1" {
+		t.Fatalf("first event %s, want e1", event.ID)
+	}
+	if _, open := <-subscription.Events(); open {
+		t.Fatal("subscriber a whole buffer behind was not closed")
+	}
+	// The history still lets it resume from its last event
+	replay, resumed := stream.Subscribe("session", "e1")
+	defer resumed.Close()
+	if ids := eventIDs(replay); len(ids) != 1 || ids[0] != "e2" {
+		t.Errorf("replayed %v after reconnecting, want [e2]", ids)
+	}
+	subscription.Close()
+}
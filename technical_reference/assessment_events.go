@@ -0,0 +1,111 @@
+package assessment
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Event types published to webhooks and session event streams
+const (
+	EventParticipantAssessed    = "participant.assessed"
+	EventGroupAssessed          = "group.assessed"
+	EventSessionConsolidated    = "session.consolidated"
+	EventTranscriptionSubmitted = "transcription.submitted"
+	EventTranscriptionCompleted = "transcription.completed"
+	EventTranscriptionFailed    = "transcription.failed"
+	EventJobFailed              = "job.failed"
+	EventPing                   = "ping"
+)
+
+// AssessmentEvent is something that happened to a session's assessment
+type AssessmentEvent struct {
+	ID         string    `json:"id"`
+	Type       string    `json:"type"`
+	SessionID  string    `json:"session_id"`
+	OccurredAt time.Time `json:"occurred_at"`
+	Data       any       `json:"data"`
+}
+
+// NewAssessmentEvent creates an event with a fresh ID
+func NewAssessmentEvent(eventType, sessionID string, data any) AssessmentEvent {
+	return AssessmentEvent{
+		ID:         newRecordID(),
+		Type:       eventType,
+		SessionID:  sessionID,
+		OccurredAt: time.Now().UTC(),
+		Data:       data,
+	}
+}
+
+// EventPublisher receives assessment events. Publish must not block on slow consumers.
+type EventPublisher interface {
+	Publish(ctx context.Context, event AssessmentEvent)
+}
+
+// EventBus fans events out to every subscribed publisher
+type EventBus struct {
+	mu          sync.RWMutex
+	subscribers []EventPublisher
+}
+
+// NewEventBus creates a bus with no subscribers
+func NewEventBus() *EventBus {
+	return &EventBus{}
+}
+
+// Subscribe adds a publisher that receives every later event
+func (b *EventBus) Subscribe(publisher EventPublisher) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.subscribers = append(b.subscribers, publisher)
+}
+
+// Publish delivers the event to every subscriber
+func (b *EventBus) Publish(ctx context.Context, event AssessmentEvent) {
+	b.mu.RLock()
+	subscribers := append([]EventPublisher(nil), b.subscribers...)
+	b.mu.RUnlock()
+
+	for _, subscriber := range subscribers {
+		subscriber.Publish(ctx, event)
+	}
+}
+
+// PublishingResultRepository publishes an event each time a result is stored
+type PublishingResultRepository struct {
+	ResultRepository
+	publisher EventPublisher
+}
+
+// NewPublishingResultRepository wraps a repository so stored results are published
+func NewPublishingResultRepository(inner ResultRepository, publisher EventPublisher) *PublishingResultRepository {
+	return &PublishingResultRepository{ResultRepository: inner, publisher: publisher}
+}
+
+func (r *PublishingResultRepository) SaveParticipantResult(ctx context.Context, result *AssessmentResponse) error {
+	if err := r.ResultRepository.SaveParticipantResult(ctx, result); err != nil {
+		return err
+	}
+	r.publisher.Publish(ctx, NewAssessmentEvent(EventParticipantAssessed, result.SessionID, result))
+	return nil
+}
+
+func (r *PublishingResultRepository) SaveGroupResult(ctx context.Context, result *GroupAssessmentResponse) error {
+	if err := r.ResultRepository.SaveGroupResult(ctx, result); err != nil {
+		return err
+	}
+	r.publisher.Publish(ctx, NewAssessmentEvent(EventGroupAssessed, result.SessionID, result))
+	return nil
+}
+
+func (r *PublishingResultRepository) SaveConsolidatedResult(ctx context.Context, result *ConsolidatedResult) error {
+	if err := r.ResultRepository.SaveConsolidatedResult(ctx, result); err != nil {
+		return err
+	}
+	// Partial results are announced by their participant and group events
+	if !result.Partial {
+		r.publisher.Publish(ctx, NewAssessmentEvent(EventSessionConsolidated, result.SessionID, result))
+	}
+	return nil
+}
@@ -0,0 +1,1027 @@
+package assessment
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Job types handled by the assessment workers
+const (
+	JobTypeParticipantAssessment  = "participant_assessment"
+	JobTypeConsolidatedAssessment = "consolidated_assessment"
+)
+
+// Job statuses. Succeeded, failed and cancelled are terminal; a failed job has
+// exhausted its attempts and stays in the store as the dead-letter record.
+const (
+	JobStatusQueued    = "queued"
+	JobStatusRunning   = "running"
+	JobStatusSucceeded = "succeeded"
+	JobStatusFailed    = "failed"
+	JobStatusCancelled = "cancelled"
+)
+
+// jobTransitions lists the statuses each status may move to
+var jobTransitions = map[string][]string{
+	JobStatusQueued:  {JobStatusRunning, JobStatusCancelled},
+	JobStatusRunning: {JobStatusQueued, JobStatusSucceeded, JobStatusFailed, JobStatusCancelled},
+}
+
+// CanTransitionJob reports whether a job may move from one status to another
+func CanTransitionJob(from, to string) bool {
+	for _, allowed := range jobTransitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+// IsTerminalJobStatus reports whether a status is final
+func IsTerminalJobStatus(status string) bool {
+	return len(jobTransitions[status]) == 0
+}
+
+var (
+	// ErrNoJobAvailable is returned by JobStore.Claim when nothing is ready to run
+	ErrNoJobAvailable = errors.New("no job available")
+	// ErrJobNotFound is returned when a job ID is unknown
+	ErrJobNotFound = errors.New("job not found")
+	// ErrQueueFull is returned when the queue is at capacity
+	ErrQueueFull = errors.New("assessment queue is full")
+	// ErrInvalidJobTransition is returned when a status change is not allowed from the job's current status
+	ErrInvalidJobTransition = errors.New("invalid job status transition")
+	// ErrIdempotencyKeyReused is returned when an idempotency key is sent again with a different payload
+	ErrIdempotencyKeyReused = errors.New("idempotency key was already used with a different payload")
+	// ErrJobLeaseLost is returned when a job was reclaimed from the worker that held its lease
+	ErrJobLeaseLost = errors.New("job lease held by another worker")
+)
+
+// JobAttemptError records why one attempt of a job failed
+type JobAttemptError struct {
+	Attempt  int       `json:"attempt"`
+	Message  string    `json:"message"`
+	FailedAt time.Time `json:"failed_at"`
+}
+
+// Job is a unit of background work persisted in a JobStore
+type Job struct {
+	ID            string          `json:"id"`
+	Type          string          `json:"type"`
+	SessionID     string          `json:"session_id"`
+	ParticipantID string          `json:"participant_id,omitempty"`
+	Payload       json.RawMessage `json:"-"`
+	Status        string          `json:"status"`
+	Attempts      int             `json:"attempts"`
+	MaxAttempts   int             `json:"max_attempts"`
+	RunAt         time.Time       `json:"run_at"`
+	LastError     string          `json:"last_error,omitempty"`
+	// IdempotencyKey is the client's Idempotency-Key, or a hash of the payload
+	IdempotencyKey string `json:"idempotency_key,omitempty"`
+	// Errors holds one entry per failed attempt, oldest first
+	Errors     []JobAttemptError `json:"errors,omitempty"`
+	CreatedAt  time.Time         `json:"created_at"`
+	StartedAt  *time.Time        `json:"started_at,omitempty"`
+	DeadlineAt *time.Time        `json:"deadline_at,omitempty"`
+	FinishedAt *time.Time        `json:"finished_at,omitempty"`
+	UpdatedAt  time.Time         `json:"updated_at"`
+	// LeaseOwner is the queue instance running the job; it renews LeaseExpiresAt
+	// while the job runs so other instances leave the job alone
+	LeaseOwner     string     `json:"-"`
+	LeaseExpiresAt *time.Time `json:"lease_expires_at,omitempty"`
+}
+
+// transition moves the job to a new status, stamping timestamps
+func (j *Job) transition(to string, now time.Time) error {
+	if !CanTransitionJob(j.Status, to) {
+		return fmt.Errorf("%w: %s -> %s", ErrInvalidJobTransition, j.Status, to)
+	}
+
+	j.Status = to
+	j.UpdatedAt = now
+	switch {
+	case to == JobStatusRunning:
+		j.Attempts++
+		j.StartedAt = &now
+	case to == JobStatusQueued:
+		j.DeadlineAt = nil
+		j.LeaseOwner = ""
+		j.LeaseExpiresAt = nil
+	case IsTerminalJobStatus(to):
+		j.FinishedAt = &now
+		j.LeaseExpiresAt = nil
+	}
+	return nil
+}
+
+// recordError appends the error for the current attempt
+func (j *Job) recordError(jobErr error, now time.Time) {
+	j.LastError = jobErr.Error()
+	j.Errors = append(j.Errors, JobAttemptError{Attempt: j.Attempts, Message: j.LastError, FailedAt: now})
+}
+
+// JobStore persists queued jobs
+type JobStore interface {
+	Enqueue(ctx context.Context, job *Job) error
+	// Claim moves the oldest queued job that is due to running and returns it
+	Claim(ctx context.Context, now time.Time) (*Job, error)
+	// Update applies change to the stored job and saves it; change returns an error
+	// (typically ErrInvalidJobTransition) to leave the job untouched
+	Update(ctx context.Context, jobID string, change func(job *Job) error) (*Job, error)
+	Get(ctx context.Context, jobID string) (*Job, error)
+	// ListJobs returns a session's jobs, oldest first
+	ListJobs(ctx context.Context, sessionID string) ([]*Job, error)
+	ListJobsByStatus(ctx context.Context, status string) ([]*Job, error)
+	CountPending(ctx context.Context) (int, error)
+}
+
+// RetryPolicy controls how failed jobs are retried
+type RetryPolicy struct {
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+}
+
+// Backoff returns the delay before the given attempt number is retried
+func (p RetryPolicy) Backoff(attempt int) time.Duration {
+	delay := p.InitialBackoff
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+		if delay >= p.MaxBackoff {
+			return p.MaxBackoff
+		}
+	}
+	return delay
+}
+
+// QueueConfig configures the worker pool
+type QueueConfig struct {
+	Workers      int
+	MaxQueued    int // Enqueue fails with ErrQueueFull beyond this many pending jobs; 0 means unbounded
+	PollInterval time.Duration
+	Retry        RetryPolicy
+	// JobTimeout bounds each attempt; Timeouts overrides it per job type
+	JobTimeout time.Duration
+	Timeouts   map[string]time.Duration
+	// IdempotencyWindow is how long EnqueueIdempotent returns an earlier job for the same key
+	IdempotencyWindow time.Duration
+	// OrphanCheckInterval is how often running jobs are checked for a worker that is gone
+	OrphanCheckInterval time.Duration
+	// LeaseDuration is how long a running job stays leased to its worker without a renewal.
+	// Workers renew a third of the way through, so a job is reclaimed only once its worker
+	// has missed several renewals.
+	LeaseDuration time.Duration
+}
+
+// QueueConfigFromEnv reads ASSESSMENT_WORKERS, ASSESSMENT_QUEUE_MAX, ASSESSMENT_JOB_MAX_ATTEMPTS
+// ASSESSMENT_JOB_TIMEOUT (a Go duration such as "10m"; consolidated jobs keep their longer budget)
+// ASSESSMENT_IDEMPOTENCY_WINDOW, ASSESSMENT_ORPHAN_CHECK_INTERVAL and ASSESSMENT_JOB_LEASE
+func QueueConfigFromEnv() QueueConfig {
+	config := QueueConfig{
+		Workers:      4,
+		MaxQueued:    100,
+		PollInterval: 2 * time.Second,
+		Retry: RetryPolicy{
+			MaxAttempts:    3,
+			InitialBackoff: 30 * time.Second,
+			MaxBackoff:     10 * time.Minute,
+		},
+		JobTimeout: 10 * time.Minute,
+		Timeouts: map[string]time.Duration{
+			// Consolidated jobs run one LLM call per participant plus the group assessment
+			JobTypeConsolidatedAssessment: 30 * time.Minute,
+		},
+		IdempotencyWindow:   10 * time.Minute,
+		OrphanCheckInterval: time.Minute,
+		LeaseDuration:       time.Minute,
+	}
+
+	if workers, err := strconv.Atoi(os.Getenv("ASSESSMENT_WORKERS")); err == nil && workers > 0 {
+		config.Workers = workers
+	}
+	if maxQueued, err := strconv.Atoi(os.Getenv("ASSESSMENT_QUEUE_MAX")); err == nil && maxQueued >= 0 {
+		config.MaxQueued = maxQueued
+	}
+	if attempts, err := strconv.Atoi(os.Getenv("ASSESSMENT_JOB_MAX_ATTEMPTS")); err == nil && attempts > 0 {
+		config.Retry.MaxAttempts = attempts
+	}
+	if timeout, err := time.ParseDuration(os.Getenv("ASSESSMENT_JOB_TIMEOUT")); err == nil && timeout > 0 {
+		config.JobTimeout = timeout
+	}
+	if window, err := time.ParseDuration(os.Getenv("ASSESSMENT_IDEMPOTENCY_WINDOW")); err == nil && window >= 0 {
+		config.IdempotencyWindow = window
+	}
+	if interval, err := time.ParseDuration(os.Getenv("ASSESSMENT_ORPHAN_CHECK_INTERVAL")); err == nil && interval > 0 {
+		config.OrphanCheckInterval = interval
+	}
+	if lease, err := time.ParseDuration(os.Getenv("ASSESSMENT_JOB_LEASE")); err == nil && lease > 0 {
+		config.LeaseDuration = lease
+	}
+
+	return config
+}
+
+// JobHandler runs a job; a returned error schedules a retry or dead-letters the job.
+// ctx is cancelled when the job is cancelled, passes its deadline or the queue shuts down.
+type JobHandler func(ctx context.Context, job *Job) error
+
+var (
+	// errJobCancelled is the cancellation cause when a running job is cancelled
+	errJobCancelled = errors.New("job cancelled")
+	// errQueueShutdown is the cancellation cause when shutdown interrupts a running job
+	errQueueShutdown = errors.New("queue shutting down")
+)
+
+// anyOwner lets a state change apply whoever holds the job's lease
+const anyOwner = ""
+
+// JobQueue runs persisted jobs on a bounded pool of workers
+type JobQueue struct {
+	// instance identifies this queue as the owner of the job leases it takes
+	instance string
+	store    JobStore
+	config   QueueConfig
+	logger   *AssessmentLogger
+	handlers map[string]JobHandler
+	wake     chan struct{}
+	wg       sync.WaitGroup
+
+	// stop ends the worker loops; in-flight jobs keep running until Shutdown interrupts them
+	stop context.CancelFunc
+
+	mu      sync.Mutex
+	running map[string]context.CancelCauseFunc
+
+	observers []func(ctx context.Context, job *Job)
+
+	// idempotent serializes duplicate checks with the enqueue that follows them
+	idempotent sync.Mutex
+}
+
+// NewJobQueue creates a queue; register handlers before calling Start
+func NewJobQueue(store JobStore, config QueueConfig, logger *AssessmentLogger) *JobQueue {
+	if config.Workers <= 0 {
+		config.Workers = 1
+	}
+	if config.PollInterval <= 0 {
+		config.PollInterval = 2 * time.Second
+	}
+	if config.Retry.MaxAttempts <= 0 {
+		config.Retry.MaxAttempts = 1
+	}
+	if config.JobTimeout <= 0 {
+		config.JobTimeout = 15 * time.Minute
+	}
+	if config.LeaseDuration <= 0 {
+		config.LeaseDuration = time.Minute
+	}
+
+	return &JobQueue{
+		instance: newRecordID(),
+		store:    store,
+		config:   config,
+		logger:   logger,
+		handlers: make(map[string]JobHandler),
+		wake:     make(chan struct{}, 1),
+		stop:     func() {},
+		running:  make(map[string]context.CancelCauseFunc),
+	}
+}
+
+// Register sets the handler for a job type
+func (q *JobQueue) Register(jobType string, handler JobHandler) {
+	q.handlers[jobType] = handler
+}
+
+// OnTransition registers fn to run after a job is retried, finishes or is cancelled.
+// Register observers before calling Start.
+func (q *JobQueue) OnTransition(fn func(ctx context.Context, job *Job)) {
+	q.observers = append(q.observers, fn)
+}
+
+// notify runs the transition observers for an updated job
+func (q *JobQueue) notify(ctx context.Context, job *Job, err error) error {
+	if err != nil {
+		return err
+	}
+	for _, observer := range q.observers {
+		observer(ctx, job)
+	}
+	return nil
+}
+
+// timeoutFor returns the deadline budget for a job type
+func (q *JobQueue) timeoutFor(jobType string) time.Duration {
+	if timeout, ok := q.config.Timeouts[jobType]; ok && timeout > 0 {
+		return timeout
+	}
+	return q.config.JobTimeout
+}
+
+// Enqueue persists a new job and wakes a worker
+func (q *JobQueue) Enqueue(ctx context.Context, jobType, sessionID, participantID string, payload any) (*Job, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode job payload: %w", err)
+	}
+	return q.enqueue(ctx, jobType, sessionID, participantID, "", data)
+}
+
+// FindIdempotent returns the job an earlier submission with the same key created within
+// the idempotency window. An empty key matches submissions with the same payload.
+// Failed and cancelled jobs are ignored so that a resubmission runs again. It returns
+// ErrJobNotFound when there is no such job and ErrIdempotencyKeyReused when the key
+// was used for a different payload.
+func (q *JobQueue) FindIdempotent(ctx context.Context, jobType, sessionID, participantID, key string, payload any) (*Job, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode job payload: %w", err)
+	}
+	return q.findIdempotent(ctx, jobType, sessionID, participantID, idempotencyKey(key, data), data)
+}
+
+// EnqueueIdempotent enqueues a job unless FindIdempotent finds one, in which case that
+// job is returned with duplicate set. Concurrent submissions within this process are
+// deduplicated; submissions racing on other instances may both run.
+func (q *JobQueue) EnqueueIdempotent(ctx context.Context, jobType, sessionID, participantID, key string, payload any) (job *Job, duplicate bool, err error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to encode job payload: %w", err)
+	}
+	key = idempotencyKey(key, data)
+
+	q.idempotent.Lock()
+	defer q.idempotent.Unlock()
+
+	existing, err := q.findIdempotent(ctx, jobType, sessionID, participantID, key, data)
+	if err == nil {
+		return existing, true, nil
+	} else if !errors.Is(err, ErrJobNotFound) {
+		return nil, false, err
+	}
+
+	job, err = q.enqueue(ctx, jobType, sessionID, participantID, key, data)
+	return job, false, err
+}
+
+func (q *JobQueue) findIdempotent(ctx context.Context, jobType, sessionID, participantID, key string, data []byte) (*Job, error) {
+	jobs, err := q.store.ListJobs(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	since := time.Now().UTC().Add(-q.config.IdempotencyWindow)
+	for i := len(jobs) - 1; i >= 0; i-- {
+		job := jobs[i]
+		if job.CreatedAt.Before(since) {
+			break
+		}
+		if job.Type != jobType || job.ParticipantID != participantID || job.IdempotencyKey != key ||
+			job.Status == JobStatusFailed || job.Status == JobStatusCancelled {
+			continue
+		}
+		if !bytes.Equal(job.Payload, data) {
+			return nil, ErrIdempotencyKeyReused
+		}
+		return job, nil
+	}
+	return nil, ErrJobNotFound
+}
+
+// idempotencyKey returns the client key, or a hash of the payload when there is none
+func idempotencyKey(key string, data []byte) string {
+	if key != "" {
+		return key
+	}
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+func (q *JobQueue) enqueue(ctx context.Context, jobType, sessionID, participantID, key string, data []byte) (*Job, error) {
+	if q.config.MaxQueued > 0 {
+		pending, err := q.store.CountPending(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if pending >= q.config.MaxQueued {
+			return nil, ErrQueueFull
+		}
+	}
+
+	now := time.Now().UTC()
+	job := &Job{
+		ID:             newRecordID(),
+		Type:           jobType,
+		SessionID:      sessionID,
+		ParticipantID:  participantID,
+		Payload:        data,
+		Status:         JobStatusQueued,
+		MaxAttempts:    q.config.Retry.MaxAttempts,
+		RunAt:          now,
+		IdempotencyKey: key,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+	if err := q.store.Enqueue(ctx, job); err != nil {
+		return nil, err
+	}
+
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+	return job, nil
+}
+
+// Get returns a job by ID
+func (q *JobQueue) Get(ctx context.Context, jobID string) (*Job, error) {
+	return q.store.Get(ctx, jobID)
+}
+
+// Latest returns the most recently created job of a type for a session, optionally
+// narrowed to a participant; it returns ErrJobNotFound when there is none
+func (q *JobQueue) Latest(ctx context.Context, sessionID, jobType, participantID string) (*Job, error) {
+	jobs, err := q.store.ListJobs(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	for i := len(jobs) - 1; i >= 0; i-- {
+		if jobs[i].Type == jobType && (participantID == "" || jobs[i].ParticipantID == participantID) {
+			return jobs[i], nil
+		}
+	}
+	return nil, ErrJobNotFound
+}
+
+// Cancel moves a queued or running job to cancelled and interrupts it if it is
+// running in this process. Workers in other processes notice within PollInterval.
+func (q *JobQueue) Cancel(ctx context.Context, jobID string) (*Job, error) {
+	job, err := q.store.Update(ctx, jobID, func(job *Job) error {
+		return job.transition(JobStatusCancelled, time.Now().UTC())
+	})
+	if err := q.notify(ctx, job, err); err != nil {
+		return nil, err
+	}
+
+	q.mu.Lock()
+	if cancel, ok := q.running[jobID]; ok {
+		cancel(errJobCancelled)
+	}
+	q.mu.Unlock()
+	return job, nil
+}
+
+// CancelMatching cancels a session's unfinished jobs, optionally narrowed to a job type
+// and participant, and returns the jobs it cancelled
+func (q *JobQueue) CancelMatching(ctx context.Context, sessionID, jobType, participantID string) ([]*Job, error) {
+	jobs, err := q.store.ListJobs(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	cancelled := []*Job{}
+	for _, job := range jobs {
+		if IsTerminalJobStatus(job.Status) ||
+			(jobType != "" && job.Type != jobType) ||
+			(participantID != "" && job.ParticipantID != participantID) {
+			continue
+		}
+		updated, err := q.Cancel(ctx, job.ID)
+		if errors.Is(err, ErrInvalidJobTransition) {
+			// Finished between listing and cancelling
+			continue
+		} else if err != nil {
+			return cancelled, err
+		}
+		cancelled = append(cancelled, updated)
+	}
+	return cancelled, nil
+}
+
+// checkLease rejects a state change by a worker that no longer holds the job's lease
+func checkLease(job *Job, owner string) error {
+	if owner != anyOwner && job.LeaseOwner != owner {
+		return fmt.Errorf("%w: job %s", ErrJobLeaseLost, job.ID)
+	}
+	return nil
+}
+
+// complete marks a running job held by owner as succeeded
+func (q *JobQueue) complete(ctx context.Context, jobID, owner string) error {
+	job, err := q.store.Update(ctx, jobID, func(job *Job) error {
+		if err := checkLease(job, owner); err != nil {
+			return err
+		}
+		if err := job.transition(JobStatusSucceeded, time.Now().UTC()); err != nil {
+			return err
+		}
+		job.LastError = ""
+		return nil
+	})
+	return q.notify(ctx, job, err)
+}
+
+// retry records the failure and puts a running job held by owner back in the queue to run at runAt
+func (q *JobQueue) retry(ctx context.Context, jobID, owner string, runAt time.Time, jobErr error) error {
+	job, err := q.store.Update(ctx, jobID, func(job *Job) error {
+		if err := checkLease(job, owner); err != nil {
+			return err
+		}
+		now := time.Now().UTC()
+		if err := job.transition(JobStatusQueued, now); err != nil {
+			return err
+		}
+		job.recordError(jobErr, now)
+		job.RunAt = runAt
+		return nil
+	})
+	return q.notify(ctx, job, err)
+}
+
+// fail records the failure and parks a job held by owner that cannot be retried
+func (q *JobQueue) fail(ctx context.Context, jobID, owner string, jobErr error) error {
+	job, err := q.store.Update(ctx, jobID, func(job *Job) error {
+		if err := checkLease(job, owner); err != nil {
+			return err
+		}
+		now := time.Now().UTC()
+		if err := job.transition(JobStatusFailed, now); err != nil {
+			return err
+		}
+		job.recordError(jobErr, now)
+		return nil
+	})
+	return q.notify(ctx, job, err)
+}
+
+// requeue puts a job held by owner and interrupted by shutdown back in the queue without spending an attempt
+func (q *JobQueue) requeue(ctx context.Context, jobID, owner string) error {
+	_, err := q.store.Update(ctx, jobID, func(job *Job) error {
+		if err := checkLease(job, owner); err != nil {
+			return err
+		}
+		now := time.Now().UTC()
+		if err := job.transition(JobStatusQueued, now); err != nil {
+			return err
+		}
+		job.Attempts--
+		job.RunAt = now
+		return nil
+	})
+	return err
+}
+
+// Start recovers jobs orphaned by a previous process and launches the workers. Orphans
+// are looked for again every OrphanCheckInterval, since a job claimed just before a crash
+// only counts as orphaned once its lease expires, which may be after a quick restart.
+// Workers stop claiming jobs when ctx is cancelled or Shutdown is called.
+func (q *JobQueue) Start(ctx context.Context) {
+	q.recoverOrphaned(ctx)
+
+	workerCtx, stop := context.WithCancel(ctx)
+	q.stop = stop
+	for i := 0; i < q.config.Workers; i++ {
+		q.wg.Add(1)
+		go q.work(workerCtx, i)
+	}
+	if q.config.OrphanCheckInterval > 0 {
+		q.wg.Add(1)
+		go q.watchOrphans(workerCtx)
+	}
+}
+
+// watchOrphans runs recoverOrphaned every OrphanCheckInterval until ctx is cancelled
+func (q *JobQueue) watchOrphans(ctx context.Context) {
+	defer q.wg.Done()
+
+	ticker := time.NewTicker(q.config.OrphanCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			q.recoverOrphaned(ctx)
+		}
+	}
+}
+
+// Shutdown stops claiming new jobs and waits for in-flight jobs to drain. If ctx
+// expires first, in-flight jobs are interrupted and put back in the queue so the
+// next process resumes them; Shutdown then returns ctx's error.
+func (q *JobQueue) Shutdown(ctx context.Context) error {
+	q.stop()
+
+	drained := make(chan struct{})
+	go func() {
+		q.wg.Wait()
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+		return nil
+	case <-ctx.Done():
+	}
+
+	q.mu.Lock()
+	q.logger.Warn("shutdown deadline reached, requeueing in-flight jobs", slog.Int("jobs", len(q.running)))
+	for _, cancel := range q.running {
+		cancel(errQueueShutdown)
+	}
+	q.mu.Unlock()
+
+	<-drained
+	return ctx.Err()
+}
+
+// recoverOrphaned requeues running jobs whose lease expired, which means the worker
+// that claimed them stopped renewing it. Jobs held by a live worker in any instance
+// keep being renewed and are left alone. A job without a lease, claimed by a worker
+// that died before taking one, counts as orphaned once its deadline passes, or its
+// timeout from when it started.
+func (q *JobQueue) recoverOrphaned(ctx context.Context) {
+	jobs, err := q.store.ListJobsByStatus(ctx, JobStatusRunning)
+	if err != nil {
+		q.logger.Error("failed to list running jobs", slog.String("error", err.Error()))
+		return
+	}
+
+	now := time.Now().UTC()
+	for _, job := range jobs {
+		expires := job.LeaseExpiresAt
+		if expires == nil {
+			expires = job.DeadlineAt
+		}
+		if expires == nil && job.StartedAt != nil {
+			started := job.StartedAt.Add(q.timeoutFor(job.Type))
+			expires = &started
+		}
+		if expires == nil || expires.After(now) {
+			continue
+		}
+		q.mu.Lock()
+		_, local := q.running[job.ID]
+		q.mu.Unlock()
+		if local {
+			continue
+		}
+		interrupted := errors.New("worker stopped before the job finished")
+		// Guarded on the lease owner read above, so a job reclaimed meanwhile is skipped
+		if job.Attempts >= job.MaxAttempts {
+			err = q.fail(ctx, job.ID, job.LeaseOwner, interrupted)
+		} else {
+			err = q.retry(ctx, job.ID, job.LeaseOwner, now, interrupted)
+		}
+		if errors.Is(err, ErrJobLeaseLost) || errors.Is(err, ErrInvalidJobTransition) {
+			continue
+		} else if err != nil {
+			q.logger.Error("failed to recover orphaned job", slog.String("job_id", job.ID), slog.String("error", err.Error()))
+			continue
+		}
+		q.logger.Info("recovered orphaned job", slog.String("job_id", job.ID), slog.String("session_id", job.SessionID))
+	}
+}
+
+func (q *JobQueue) work(ctx context.Context, worker int) {
+	defer q.wg.Done()
+
+	ticker := time.NewTicker(q.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		// Drain every due job before sleeping again
+		for q.runNext(ctx, worker) {
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-q.wake:
+		case <-ticker.C:
+		}
+	}
+}
+
+// runNext claims and runs one job, reporting whether a job was found
+func (q *JobQueue) runNext(ctx context.Context, worker int) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+
+	job, err := q.store.Claim(ctx, time.Now().UTC())
+	if errors.Is(err, ErrNoJobAvailable) {
+		return false
+	} else if err != nil {
+		q.logger.Error("failed to claim job", slog.Int("worker", worker), slog.String("error", err.Error()))
+		return false
+	}
+
+	// The job and its bookkeeping outlive the worker loop so a stopping worker can drain it
+	storeCtx := context.WithoutCancel(ctx)
+
+	logger := q.logger.ForParticipant(job.SessionID, job.ParticipantID).With(
+		slog.String("job_id", job.ID),
+		slog.String("job_type", job.Type),
+		slog.Int("attempt", job.Attempts),
+	)
+
+	handler, ok := q.handlers[job.Type]
+	if !ok {
+		logger.Error("no handler registered for job type")
+		q.fail(storeCtx, job.ID, anyOwner, fmt.Errorf("no handler for job type %q", job.Type))
+		return true
+	}
+
+	timeout := q.timeoutFor(job.Type)
+	now := time.Now().UTC()
+	deadline := now.Add(timeout)
+	leaseExpires := now.Add(q.config.LeaseDuration)
+	if _, err := q.store.Update(storeCtx, job.ID, func(stored *Job) error {
+		if stored.Status != JobStatusRunning {
+			return fmt.Errorf("%w: job %s is %s", ErrInvalidJobTransition, stored.ID, stored.Status)
+		}
+		stored.DeadlineAt = &deadline
+		stored.LeaseOwner = q.instance
+		stored.LeaseExpiresAt = &leaseExpires
+		return nil
+	}); err != nil {
+		// Without a lease the job counts as orphaned only once its deadline passes
+		logger.Warn("failed to take job lease", slog.String("error", err.Error()))
+	} else {
+		job.LeaseOwner = q.instance
+	}
+	job.DeadlineAt = &deadline
+
+	jobCtx, cancel := context.WithCancelCause(storeCtx)
+	jobCtx, cancelDeadline := context.WithDeadline(jobCtx, deadline)
+	defer cancelDeadline()
+	q.track(job.ID, cancel)
+	defer q.untrack(job.ID)
+	go q.watchJob(jobCtx, job.ID, cancel)
+
+	logger.Info("job started", slog.Time("deadline", deadline))
+	err = q.runHandler(jobCtx, handler, job)
+	if err == nil && jobCtx.Err() != nil {
+		// A handler that swallowed the interruption still did not finish its work
+		err = jobCtx.Err()
+	}
+	if errors.Is(jobCtx.Err(), context.DeadlineExceeded) && err != nil {
+		err = fmt.Errorf("job exceeded its %s deadline: %w", timeout, err)
+	}
+
+	var stateErr error
+	switch cause := context.Cause(jobCtx); {
+	case errors.Is(cause, errJobCancelled):
+		logger.Info("job cancelled")
+		return true
+	case errors.Is(cause, ErrJobLeaseLost):
+		logger.Warn("job lease lost, leaving the job to the worker that reclaimed it")
+		return true
+	case errors.Is(cause, errQueueShutdown):
+		stateErr = q.requeue(storeCtx, job.ID, job.LeaseOwner)
+		if stateErr == nil {
+			logger.Info("job interrupted by shutdown, requeued")
+		}
+	case err == nil:
+		stateErr = q.complete(storeCtx, job.ID, job.LeaseOwner)
+		if stateErr == nil {
+			logger.Info("job succeeded")
+		}
+	case job.Attempts >= job.MaxAttempts:
+		stateErr = q.fail(storeCtx, job.ID, job.LeaseOwner, err)
+		if stateErr == nil {
+			logger.Error("job failed, attempts exhausted", slog.String("error", err.Error()))
+		}
+	default:
+		delay := q.config.Retry.Backoff(job.Attempts)
+		stateErr = q.retry(storeCtx, job.ID, job.LeaseOwner, time.Now().UTC().Add(delay), err)
+		if stateErr == nil {
+			logger.Warn("job failed, retrying", slog.String("error", err.Error()), slog.Duration("retry_in", delay))
+		}
+	}
+
+	// A job cancelled while it ran keeps its cancelled status
+	if errors.Is(stateErr, ErrInvalidJobTransition) {
+		logger.Info("job finished after it was cancelled")
+	} else if errors.Is(stateErr, ErrJobLeaseLost) {
+		logger.Warn("job finished after its lease was reclaimed, result not recorded")
+	} else if stateErr != nil {
+		logger.Error("failed to update job status", slog.String("error", stateErr.Error()))
+	}
+	return true
+}
+
+func (q *JobQueue) track(jobID string, cancel context.CancelCauseFunc) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.running[jobID] = cancel
+}
+
+func (q *JobQueue) untrack(jobID string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if cancel, ok := q.running[jobID]; ok {
+		cancel(nil)
+		delete(q.running, jobID)
+	}
+}
+
+// watchJob renews a running job's lease and interrupts the job when another process
+// cancels it in the store, or reclaims it because a renewal was missed
+func (q *JobQueue) watchJob(ctx context.Context, jobID string, cancel context.CancelCauseFunc) {
+	renewEvery := q.config.LeaseDuration / 3
+	interval := q.config.PollInterval
+	if renewEvery < interval {
+		interval = renewEvery
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	renewed := time.Now()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		job, err := q.store.Get(ctx, jobID)
+		if err != nil {
+			continue
+		}
+		if job.Status == JobStatusCancelled {
+			cancel(errJobCancelled)
+			return
+		}
+		// A job whose lease was never taken has no owner until it finishes
+		if job.Status != JobStatusRunning || (job.LeaseOwner != "" && job.LeaseOwner != q.instance) {
+			cancel(ErrJobLeaseLost)
+			return
+		}
+		if job.LeaseOwner == "" || time.Since(renewed) < renewEvery {
+			continue
+		}
+
+		expires := time.Now().UTC().Add(q.config.LeaseDuration)
+		_, err = q.store.Update(ctx, jobID, func(stored *Job) error {
+			if stored.Status != JobStatusRunning {
+				return fmt.Errorf("%w: job %s is %s", ErrInvalidJobTransition, stored.ID, stored.Status)
+			}
+			if err := checkLease(stored, q.instance); err != nil {
+				return err
+			}
+			stored.LeaseExpiresAt = &expires
+			return nil
+		})
+		if errors.Is(err, ErrJobLeaseLost) {
+			cancel(ErrJobLeaseLost)
+			return
+		} else if err != nil {
+			// A cancellation is picked up on the next tick; other errors retry the renewal
+			continue
+		}
+		renewed = time.Now()
+	}
+}
+
+// runHandler runs a handler, converting a panic into a job error
+func (q *JobQueue) runHandler(ctx context.Context, handler JobHandler, job *Job) (err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("job panicked: %v", recovered)
+		}
+	}()
+	return handler(context.WithValue(ctx, jobContextKey{}, job), job)
+}
+
+type jobContextKey struct{}
+
+// JobFromContext returns the job whose handler is running with ctx
+func JobFromContext(ctx context.Context) (*Job, bool) {
+	job, ok := ctx.Value(jobContextKey{}).(*Job)
+	return job, ok
+}
+
+// MemoryJobStore is a process-local JobStore for demos and local development
+type MemoryJobStore struct {
+	mu   sync.Mutex
+	jobs map[string]*Job
+}
+
+// NewMemoryJobStore creates an empty in-memory job store
+func NewMemoryJobStore() *MemoryJobStore {
+	return &MemoryJobStore{jobs: make(map[string]*Job)}
+}
+
+func (s *MemoryJobStore) Enqueue(ctx context.Context, job *Job) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.jobs[job.ID] = copyJob(job)
+	return nil
+}
+
+func (s *MemoryJobStore) Claim(ctx context.Context, now time.Time) (*Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	due := []*Job{}
+	for _, job := range s.jobs {
+		if job.Status == JobStatusQueued && !job.RunAt.After(now) {
+			due = append(due, job)
+		}
+	}
+	if len(due) == 0 {
+		return nil, ErrNoJobAvailable
+	}
+	sort.Slice(due, func(i, j int) bool { return due[i].RunAt.Before(due[j].RunAt) })
+
+	job := due[0]
+	if err := job.transition(JobStatusRunning, now); err != nil {
+		return nil, err
+	}
+	return copyJob(job), nil
+}
+
+func (s *MemoryJobStore) Update(ctx context.Context, jobID string, change func(job *Job) error) (*Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stored, ok := s.jobs[jobID]
+	if !ok {
+		return nil, ErrJobNotFound
+	}
+
+	// Apply the change to a copy so a rejected change leaves the stored job untouched
+	updated := copyJob(stored)
+	if err := change(updated); err != nil {
+		return nil, err
+	}
+	s.jobs[jobID] = updated
+	return copyJob(updated), nil
+}
+
+func (s *MemoryJobStore) Get(ctx context.Context, jobID string) (*Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	job, ok := s.jobs[jobID]
+	if !ok {
+		return nil, ErrJobNotFound
+	}
+	return copyJob(job), nil
+}
+
+func (s *MemoryJobStore) ListJobs(ctx context.Context, sessionID string) ([]*Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	jobs := []*Job{}
+	for _, job := range s.jobs {
+		if job.SessionID == sessionID {
+			jobs = append(jobs, copyJob(job))
+		}
+	}
+	sort.Slice(jobs, func(i, j int) bool { return jobs[i].CreatedAt.Before(jobs[j].CreatedAt) })
+	return jobs, nil
+}
+
+func (s *MemoryJobStore) ListJobsByStatus(ctx context.Context, status string) ([]*Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	jobs := []*Job{}
+	for _, job := range s.jobs {
+		if job.Status == status {
+			jobs = append(jobs, copyJob(job))
+		}
+	}
+	sort.Slice(jobs, func(i, j int) bool { return jobs[i].CreatedAt.Before(jobs[j].CreatedAt) })
+	return jobs, nil
+}
+
+func (s *MemoryJobStore) CountPending(ctx context.Context) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	pending := 0
+	for _, job := range s.jobs {
+		if job.Status == JobStatusQueued || job.Status == JobStatusRunning {
+			pending++
+		}
+	}
+	return pending, nil
+}
+
+// copyJob returns a copy that shares no slices with the original
+func copyJob(job *Job) *Job {
+	copied := *job
+	copied.Errors = append([]JobAttemptError(nil), job.Errors...)
+	return &copied
+}
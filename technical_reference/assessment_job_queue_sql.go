@@ -0,0 +1,189 @@
+package assessment
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// SQLJobStore is a JobStore backed by the assessment_jobs table.
+// Updates are guarded on the previous status and lease owner so several processes can share one queue.
+type SQLJobStore struct {
+	db      *sql.DB
+	dialect SQLDialect
+}
+
+// NewSQLJobStore creates a job store on an open database
+func NewSQLJobStore(db *sql.DB, dialect SQLDialect) *SQLJobStore {
+	return &SQLJobStore{db: db, dialect: dialect}
+}
+
+// Migrate applies the embedded migrations that have not been applied yet
+func (s *SQLJobStore) Migrate(ctx context.Context) error {
+	return migrate(ctx, s.db, s.dialect)
+}
+
+const jobColumns = `id, job_type, session_id, participant_id, payload_json, status, attempts, max_attempts, run_at, last_error, idempotency_key, errors_json, created_at, started_at, deadline_at, finished_at, updated_at, lease_owner, lease_expires_at`
+
+func (s *SQLJobStore) Enqueue(ctx context.Context, job *Job) error {
+	errorsJSON, err := json.Marshal(job.Errors)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.db.ExecContext(ctx, rebind(s.dialect, `
+		INSERT INTO assessment_jobs (`+jobColumns+`)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`),
+		job.ID, job.Type, job.SessionID, job.ParticipantID, string(job.Payload), job.Status,
+		job.Attempts, job.MaxAttempts, job.RunAt, job.LastError, job.IdempotencyKey, string(errorsJSON),
+		job.CreatedAt, job.StartedAt, job.DeadlineAt, job.FinishedAt, job.UpdatedAt, job.LeaseOwner, job.LeaseExpiresAt)
+	if err != nil {
+		return fmt.Errorf("failed to enqueue job: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLJobStore) Claim(ctx context.Context, now time.Time) (*Job, error) {
+	// Another worker may claim the same row first; Update then reports an invalid
+	// transition and the next candidate is tried
+	for i := 0; i < 5; i++ {
+		var jobID string
+		err := s.db.QueryRowContext(ctx, rebind(s.dialect, `
+			SELECT id FROM assessment_jobs
+			WHERE status = ? AND run_at <= ?
+			ORDER BY run_at
+			LIMIT 1`), JobStatusQueued, now).Scan(&jobID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNoJobAvailable
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to find queued job: %w", err)
+		}
+
+		job, err := s.Update(ctx, jobID, func(job *Job) error {
+			return job.transition(JobStatusRunning, now)
+		})
+		if errors.Is(err, ErrInvalidJobTransition) || errors.Is(err, ErrJobNotFound) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		return job, nil
+	}
+	return nil, ErrNoJobAvailable
+}
+
+func (s *SQLJobStore) Update(ctx context.Context, jobID string, change func(job *Job) error) (*Job, error) {
+	job, err := s.Get(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+	previousStatus, previousOwner := job.Status, job.LeaseOwner
+	if err := change(job); err != nil {
+		return nil, err
+	}
+
+	errorsJSON, err := json.Marshal(job.Errors)
+	if err != nil {
+		return nil, err
+	}
+
+	// Guard on the status and lease owner we read so concurrent transitions cannot both win
+	result, err := s.db.ExecContext(ctx, rebind(s.dialect, `
+		UPDATE assessment_jobs
+		SET status = ?, attempts = ?, run_at = ?, last_error = ?, errors_json = ?,
+			started_at = ?, deadline_at = ?, finished_at = ?, updated_at = ?,
+			lease_owner = ?, lease_expires_at = ?
+		WHERE id = ? AND status = ? AND lease_owner = ?`),
+		job.Status, job.Attempts, job.RunAt, job.LastError, string(errorsJSON),
+		job.StartedAt, job.DeadlineAt, job.FinishedAt, job.UpdatedAt,
+		job.LeaseOwner, job.LeaseExpiresAt, jobID, previousStatus, previousOwner)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update job: %w", err)
+	}
+	if updated, err := result.RowsAffected(); err == nil && updated == 0 {
+		return nil, fmt.Errorf("%w: job %s changed concurrently", ErrInvalidJobTransition, jobID)
+	}
+	return job, nil
+}
+
+func (s *SQLJobStore) Get(ctx context.Context, jobID string) (*Job, error) {
+	job, err := scanJob(s.db.QueryRowContext(ctx, rebind(s.dialect, `
+		SELECT `+jobColumns+` FROM assessment_jobs WHERE id = ?`), jobID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrJobNotFound
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to load job: %w", err)
+	}
+	return job, nil
+}
+
+func (s *SQLJobStore) ListJobs(ctx context.Context, sessionID string) ([]*Job, error) {
+	return s.listJobs(ctx, `session_id = ?`, sessionID)
+}
+
+func (s *SQLJobStore) ListJobsByStatus(ctx context.Context, status string) ([]*Job, error) {
+	return s.listJobs(ctx, `status = ?`, status)
+}
+
+func (s *SQLJobStore) listJobs(ctx context.Context, where string, args ...any) ([]*Job, error) {
+	rows, err := s.db.QueryContext(ctx, rebind(s.dialect, `
+		SELECT `+jobColumns+` FROM assessment_jobs
+		WHERE `+where+`
+		ORDER BY created_at`), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list jobs: %w", err)
+	}
+	defer rows.Close()
+
+	jobs := []*Job{}
+	for rows.Next() {
+		job, err := scanJob(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load job: %w", err)
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, rows.Err()
+}
+
+func (s *SQLJobStore) CountPending(ctx context.Context) (int, error) {
+	var pending int
+	err := s.db.QueryRowContext(ctx, rebind(s.dialect, `
+		SELECT COUNT(*) FROM assessment_jobs WHERE status IN (?, ?)`), JobStatusQueued, JobStatusRunning).Scan(&pending)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count pending jobs: %w", err)
+	}
+	return pending, nil
+}
+
+func scanJob(row rowScanner) (*Job, error) {
+	var job Job
+	var payload, errorsJSON string
+	var startedAt, deadlineAt, finishedAt, leaseExpiresAt sql.NullTime
+	if err := row.Scan(&job.ID, &job.Type, &job.SessionID, &job.ParticipantID, &payload, &job.Status,
+		&job.Attempts, &job.MaxAttempts, &job.RunAt, &job.LastError, &job.IdempotencyKey, &errorsJSON,
+		&job.CreatedAt, &startedAt, &deadlineAt, &finishedAt, &job.UpdatedAt, &job.LeaseOwner, &leaseExpiresAt); err != nil {
+		return nil, err
+	}
+
+	job.Payload = []byte(payload)
+	if err := json.Unmarshal([]byte(errorsJSON), &job.Errors); err != nil {
+		return nil, fmt.Errorf("failed to decode job errors: %w", err)
+	}
+	if startedAt.Valid {
+		job.StartedAt = &startedAt.Time
+	}
+	if deadlineAt.Valid {
+		job.DeadlineAt = &deadlineAt.Time
+	}
+	if finishedAt.Valid {
+		job.FinishedAt = &finishedAt.Time
+	}
+	if leaseExpiresAt.Valid {
+		job.LeaseExpiresAt = &leaseExpiresAt.Time
+	}
+	return &job, nil
+}
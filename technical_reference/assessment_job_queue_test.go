@@ -0,0 +1,734 @@
+package assessment
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+// newTestSQLiteDB opens a migrated in-memory SQLite database that is closed with the test
+func newTestSQLiteDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Each connection to :memory: is a separate database
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+
+	if err := migrate(context.Background(), db, DialectSQLite); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+// testJobStores lists the JobStore implementations the queue tests run against
+var testJobStores = []struct {
+	name string
+	open func(t *testing.T) JobStore
+}{
+	{name: "memory", open: func(t *testing.T) JobStore { return NewMemoryJobStore() }},
+	{name: "sqlite", open: func(t *testing.T) JobStore { return NewSQLJobStore(newTestSQLiteDB(t), DialectSQLite) }},
+}
+
+// newTestJobQueue returns a queue on store with fast polling and backoff and a discarded log
+func newTestJobQueue(store JobStore, config QueueConfig) *JobQueue {
+	config.PollInterval = 5 * time.Millisecond
+	if config.Retry.InitialBackoff == 0 {
+		config.Retry.InitialBackoff = time.Millisecond
+		config.Retry.MaxBackoff = time.Millisecond
+	}
+	return NewJobQueue(store, config, NewAssessmentLogger(io.Discard, LoggingConfig{}))
+}
+
+// waitForJobStatus polls until the job reaches one of statuses
+func waitForJobStatus(t *testing.T, q *JobQueue, jobID string, statuses ...string) *Job {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		job, err := q.Get(context.Background(), jobID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, status := range statuses {
+			if job.Status == status {
+				return job
+			}
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("job %s is %s, want one of %v", jobID, job.Status, statuses)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestCanTransitionJob(t *testing.T) {
+	tests := []struct {
+		from, to string
+		want     bool
+	}{
+		{from: JobStatusQueued, to: JobStatusRunning, want: true},
+		{from: JobStatusQueued, to: JobStatusCancelled, want: true},
+		{from: JobStatusQueued, to: JobStatusSucceeded},
+		{from: JobStatusRunning, to: JobStatusQueued, want: true},
+		{from: JobStatusRunning, to: JobStatusSucceeded, want: true},
+		{from: JobStatusRunning, to: JobStatusFailed, want: true},
+		{from: JobStatusRunning, to: JobStatusCancelled, want: true},
+		{from: JobStatusSucceeded, to: JobStatusQueued},
+		{from: JobStatusFailed, to: JobStatusRunning},
+		{from: JobStatusCancelled, to: JobStatusRunning},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.from+" to "+tt.to, func(t *testing.T) {
+			if got := CanTransitionJob(tt.from, tt.to); got != tt.want {
+				t.Errorf("CanTransitionJob(%s, %s) = %v, want %v", tt.from, tt.to, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := RetryPolicy{InitialBackoff: 30 * time.Second, MaxBackoff: 5 * time.Minute}
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{attempt: 1, want: 30 * time.Second},
+		{attempt: 2, want: time.Minute},
+		{attempt: 3, want: 2 * time.Minute},
+		{attempt: 4, want: 4 * time.Minute},
+		{attempt: 5, want: 5 * time.Minute},
+		{attempt: 20, want: 5 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		if got := policy.Backoff(tt.attempt); got != tt.want {
+			t.Errorf("Backoff(%d) = %s, want %s", tt.attempt, got, tt.want)
+		}
+	}
+}
+
+func TestJobQueueRunsJobs(t *testing.T) {
+	errTransient := errors.New("provider unavailable")
+	tests := []struct {
+		name         string
+		maxAttempts  int
+		failures     int // attempts that fail before one succeeds
+		panics       bool
+		wantStatus   string
+		wantAttempts int
+	}{
+		{name: "succeeds first time", maxAttempts: 3, wantStatus: JobStatusSucceeded, wantAttempts: 1},
+		{name: "succeeds on retry", maxAttempts: 3, failures: 2, wantStatus: JobStatusSucceeded, wantAttempts: 3},
+		{name: "dead-lettered after exhausting attempts", maxAttempts: 2, failures: 5, wantStatus: JobStatusFailed, wantAttempts: 2},
+		{name: "panic counts as a failed attempt", maxAttempts: 1, failures: 1, panics: true, wantStatus: JobStatusFailed, wantAttempts: 1},
+	}
+
+	for _, store := range testJobStores {
+		for _, tt := range tests {
+			t.Run(store.name+"/"+tt.name, func(t *testing.T) {
+				q := newTestJobQueue(store.open(t), QueueConfig{Workers: 2, Retry: RetryPolicy{MaxAttempts: tt.maxAttempts}})
+				q.Register(JobTypeParticipantAssessment, func(ctx context.Context, job *Job) error {
+					if job.Attempts > tt.failures {
+						return nil
+					}
+					if tt.panics {
+						panic("nil transcript")
+					}
+					return errTransient
+				})
+				finished := make(chan *Job, tt.maxAttempts)
+				q.OnTransition(func(ctx context.Context, job *Job) {
+					if IsTerminalJobStatus(job.Status) {
+						finished <- job
+					}
+				})
+
+				ctx, cancel := context.WithCancel(context.Background())
+				defer cancel()
+				q.Start(ctx)
+				defer q.Shutdown(context.Background())
+
+				job, err := q.Enqueue(ctx, JobTypeParticipantAssessment, "session", "participant", map[string]string{"transcript": "hi"})
+				if err != nil {
+					t.Fatal(err)
+				}
+				job = waitForJobStatus(t, q, job.ID, JobStatusSucceeded, JobStatusFailed)
+
+				if job.Status != tt.wantStatus || job.Attempts != tt.wantAttempts {
+					t.Fatalf("job %s after %d attempts, want %s after %d", job.Status, job.Attempts, tt.wantStatus, tt.wantAttempts)
+				}
+				if want := tt.wantAttempts; tt.wantStatus == JobStatusSucceeded {
+					want--
+					if job.LastError != "" {
+						t.Errorf("succeeded job kept last error %q", job.LastError)
+					}
+				} else if len(job.Errors) != want || job.LastError == "" {
+					t.Errorf("errors %+v, want one per attempt", job.Errors)
+				}
+				if job.StartedAt == nil || job.FinishedAt == nil {
+					t.Errorf("started %v, finished %v, want both stamped", job.StartedAt, job.FinishedAt)
+				}
+				if notified := <-finished; notified.ID != job.ID || notified.Status != tt.wantStatus {
+					t.Errorf("observer saw %s %s", notified.ID, notified.Status)
+				}
+			})
+		}
+	}
+}
+
+func TestJobQueueRespectsCapacity(t *testing.T) {
+	for _, store := range testJobStores {
+		t.Run(store.name, func(t *testing.T) {
+			ctx := context.Background()
+			q := newTestJobQueue(store.open(t), QueueConfig{MaxQueued: 2})
+
+			for i := 0; i < 2; i++ {
+				if _, err := q.Enqueue(ctx, JobTypeParticipantAssessment, "session", "participant", i); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if _, err := q.Enqueue(ctx, JobTypeParticipantAssessment, "session", "participant", 3); !errors.Is(err, ErrQueueFull) {
+				t.Fatalf("third job: %v, want %v", err, ErrQueueFull)
+			}
+		})
+	}
+}
+
+func TestJobStoreClaim(t *testing.T) {
+	for _, store := range testJobStores {
+		t.Run(store.name, func(t *testing.T) {
+			ctx := context.Background()
+			s := store.open(t)
+			now := time.Now().UTC()
+
+			for _, job := range []*Job{
+				{ID: "later", RunAt: now.Add(time.Hour)},
+				{ID: "second", RunAt: now.Add(-time.Minute)},
+				{ID: "first", RunAt: now.Add(-time.Hour)},
+			} {
+				job.Type, job.SessionID, job.Status, job.MaxAttempts = JobTypeParticipantAssessment, "session", JobStatusQueued, 1
+				job.Payload, job.CreatedAt, job.UpdatedAt = []byte(`{}`), now, now
+				if err := s.Enqueue(ctx, job); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			for _, want := range []string{"first", "second"} {
+				job, err := s.Claim(ctx, now)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if job.ID != want || job.Status != JobStatusRunning || job.Attempts != 1 {
+					t.Fatalf("claimed %s (%s, attempt %d), want %s running on attempt 1", job.ID, job.Status, job.Attempts, want)
+				}
+			}
+			if job, err := s.Claim(ctx, now); !errors.Is(err, ErrNoJobAvailable) {
+				t.Fatalf("claimed %+v (%v) before it was due", job, err)
+			}
+		})
+	}
+}
+
+func TestJobStoreUpdateIsStatusGuarded(t *testing.T) {
+	for _, store := range testJobStores {
+		t.Run(store.name, func(t *testing.T) {
+			ctx := context.Background()
+			s := store.open(t)
+			now := time.Now().UTC()
+			job := &Job{ID: "job", Type: JobTypeParticipantAssessment, SessionID: "session", Payload: []byte(`{}`),
+				Status: JobStatusQueued, MaxAttempts: 1, RunAt: now, CreatedAt: now, UpdatedAt: now}
+			if err := s.Enqueue(ctx, job); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := s.Claim(ctx, now); err != nil {
+				t.Fatal(err)
+			}
+
+			// Every finishing transition races from running; only one may win
+			targets := []string{JobStatusSucceeded, JobStatusFailed, JobStatusCancelled}
+			results := make(chan error, len(targets))
+			var wg sync.WaitGroup
+			for _, to := range targets {
+				wg.Add(1)
+				go func(to string) {
+					defer wg.Done()
+					_, err := s.Update(ctx, job.ID, func(job *Job) error {
+						return job.transition(to, time.Now().UTC())
+					})
+					results <- err
+				}(to)
+			}
+			wg.Wait()
+			close(results)
+
+			won := 0
+			for err := range results {
+				if err == nil {
+					won++
+				} else if !errors.Is(err, ErrInvalidJobTransition) {
+					t.Errorf("losing update: %v, want %v", err, ErrInvalidJobTransition)
+				}
+			}
+			if won != 1 {
+				t.Fatalf("%d transitions from running won, want 1", won)
+			}
+
+			stored, err := s.Get(ctx, job.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			rejected := errors.New("rejected")
+			if _, err := s.Update(ctx, job.ID, func(job *Job) error {
+				job.LastError = "overwritten"
+				return rejected
+			}); !errors.Is(err, rejected) {
+				t.Fatalf("rejected change: %v", err)
+			}
+			if after, _ := s.Get(ctx, job.ID); after.LastError != stored.LastError || after.Status != stored.Status {
+				t.Errorf("rejected change was saved: %+v", after)
+			}
+			if _, err := s.Update(ctx, "missing", func(job *Job) error { return nil }); !errors.Is(err, ErrJobNotFound) {
+				t.Errorf("unknown job: %v, want %v", err, ErrJobNotFound)
+			}
+		})
+	}
+}
+
+func TestJobQueueCancelMatching(t *testing.T) {
+	tests := []struct {
+		name          string
+		sessionID     string
+		jobType       string
+		participantID string
+		want          int
+	}{
+		{name: "whole session", sessionID: "session-a", want: 3},
+		{name: "one participant", sessionID: "session-a", jobType: JobTypeParticipantAssessment, participantID: "p1", want: 1},
+		{name: "one job type", sessionID: "session-a", jobType: JobTypeConsolidatedAssessment, want: 1},
+		{name: "no matching jobs", sessionID: "session-c"},
+	}
+
+	for _, store := range testJobStores {
+		for _, tt := range tests {
+			t.Run(store.name+"/"+tt.name, func(t *testing.T) {
+				ctx := context.Background()
+				q := newTestJobQueue(store.open(t), QueueConfig{})
+				for _, job := range []struct{ sessionID, jobType, participantID string }{
+					{"session-a", JobTypeParticipantAssessment, "p1"},
+					{"session-a", JobTypeParticipantAssessment, "p2"},
+					{"session-a", JobTypeConsolidatedAssessment, ""},
+					{"session-b", JobTypeParticipantAssessment, "p1"},
+				} {
+					if _, err := q.Enqueue(ctx, job.jobType, job.sessionID, job.participantID, job); err != nil {
+						t.Fatal(err)
+					}
+				}
+
+				cancelled, err := q.CancelMatching(ctx, tt.sessionID, tt.jobType, tt.participantID)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if len(cancelled) != tt.want {
+					t.Fatalf("cancelled %d jobs, want %d", len(cancelled), tt.want)
+				}
+				for _, job := range cancelled {
+					if job.Status != JobStatusCancelled || job.FinishedAt == nil {
+						t.Errorf("job %s is %s, finished %v", job.ID, job.Status, job.FinishedAt)
+					}
+					if _, err := q.Cancel(ctx, job.ID); !errors.Is(err, ErrInvalidJobTransition) {
+						t.Errorf("cancelling twice: %v, want %v", err, ErrInvalidJobTransition)
+					}
+				}
+				if others, _ := q.store.ListJobs(ctx, "session-b"); others[0].Status != JobStatusQueued {
+					t.Errorf("other session's job is %s", others[0].Status)
+				}
+			})
+		}
+	}
+}
+
+func TestJobQueueCancelInterruptsRunningJob(t *testing.T) {
+	for _, store := range testJobStores {
+		t.Run(store.name, func(t *testing.T) {
+			q := newTestJobQueue(store.open(t), QueueConfig{Retry: RetryPolicy{MaxAttempts: 3}})
+			started := make(chan struct{})
+			interrupted := make(chan error, 1)
+			q.Register(JobTypeParticipantAssessment, func(ctx context.Context, job *Job) error {
+				close(started)
+				<-ctx.Done()
+				interrupted <- context.Cause(ctx)
+				return ctx.Err()
+			})
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			q.Start(ctx)
+			defer q.Shutdown(context.Background())
+
+			job, err := q.Enqueue(ctx, JobTypeParticipantAssessment, "session", "participant", "payload")
+			if err != nil {
+				t.Fatal(err)
+			}
+			<-started
+			if _, err := q.Cancel(ctx, job.ID); err != nil {
+				t.Fatal(err)
+			}
+
+			if cause := <-interrupted; !errors.Is(cause, errJobCancelled) {
+				t.Errorf("handler interrupted by %v, want %v", cause, errJobCancelled)
+			}
+			// The interrupted handler's error must not turn the cancellation into a retry
+			time.Sleep(20 * time.Millisecond)
+			if job, _ = q.Get(ctx, job.ID); job.Status != JobStatusCancelled || job.Attempts != 1 {
+				t.Errorf("job %s after %d attempts, want cancelled after 1", job.Status, job.Attempts)
+			}
+		})
+	}
+}
+
+func TestJobQueueShutdown(t *testing.T) {
+	tests := []struct {
+		name         string
+		block        bool // the handler runs until it is interrupted
+		wantErr      error
+		wantStatus   string
+		wantAttempts int
+	}{
+		{name: "drains in-flight jobs", wantStatus: JobStatusSucceeded, wantAttempts: 1},
+		{name: "requeues jobs still running at the deadline", block: true, wantErr: context.DeadlineExceeded, wantStatus: JobStatusQueued},
+	}
+
+	for _, store := range testJobStores {
+		for _, tt := range tests {
+			t.Run(store.name+"/"+tt.name, func(t *testing.T) {
+				s := store.open(t)
+				q := newTestJobQueue(s, QueueConfig{})
+				started := make(chan struct{})
+				q.Register(JobTypeParticipantAssessment, func(ctx context.Context, job *Job) error {
+					close(started)
+					if !tt.block {
+						time.Sleep(20 * time.Millisecond)
+						return nil
+					}
+					<-ctx.Done()
+					return ctx.Err()
+				})
+				q.Start(context.Background())
+
+				job, err := q.Enqueue(context.Background(), JobTypeParticipantAssessment, "session", "participant", "payload")
+				if err != nil {
+					t.Fatal(err)
+				}
+				<-started
+
+				shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+				defer cancel()
+				if !tt.block {
+					shutdownCtx = context.Background()
+				}
+				if err := q.Shutdown(shutdownCtx); !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Shutdown() = %v, want %v", err, tt.wantErr)
+				}
+
+				job, err = s.Get(context.Background(), job.ID)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if job.Status != tt.wantStatus || job.Attempts != tt.wantAttempts {
+					t.Fatalf("job %s after %d attempts, want %s after %d", job.Status, job.Attempts, tt.wantStatus, tt.wantAttempts)
+				}
+				if tt.wantStatus != JobStatusQueued {
+					return
+				}
+				if job.LeaseOwner != "" || job.LeaseExpiresAt != nil || len(job.Errors) != 0 {
+					t.Errorf("requeued job kept lease %q %v or errors %v", job.LeaseOwner, job.LeaseExpiresAt, job.Errors)
+				}
+
+				// The next process resumes the job
+				next := newTestJobQueue(s, QueueConfig{})
+				next.Register(JobTypeParticipantAssessment, func(ctx context.Context, job *Job) error { return nil })
+				next.Start(context.Background())
+				defer next.Shutdown(context.Background())
+				if job = waitForJobStatus(t, next, job.ID, JobStatusSucceeded); job.Attempts != 1 {
+					t.Errorf("resumed job took %d attempts, want 1", job.Attempts)
+				}
+			})
+		}
+	}
+}
+
+func TestJobQueueRecoversOrphanedJobs(t *testing.T) {
+	now := time.Now().UTC()
+	past, future, longAgo := now.Add(-time.Minute), now.Add(time.Minute), now.Add(-time.Hour)
+	tests := []struct {
+		name       string
+		job        Job
+		wantStatus string
+	}{
+		{
+			name:       "lease expired",
+			job:        Job{Attempts: 1, LeaseOwner: "stopped", LeaseExpiresAt: &past, DeadlineAt: &future},
+			wantStatus: JobStatusQueued,
+		},
+		{
+			name:       "lease expired on the last attempt",
+			job:        Job{Attempts: 2, LeaseOwner: "stopped", LeaseExpiresAt: &past, DeadlineAt: &future},
+			wantStatus: JobStatusFailed,
+		},
+		{
+			name:       "lease renewed by another instance",
+			job:        Job{Attempts: 1, LeaseOwner: "busy", LeaseExpiresAt: &future, DeadlineAt: &past},
+			wantStatus: JobStatusRunning,
+		},
+		{
+			name:       "no lease, deadline passed",
+			job:        Job{Attempts: 1, DeadlineAt: &past},
+			wantStatus: JobStatusQueued,
+		},
+		{
+			name:       "no lease, deadline ahead",
+			job:        Job{Attempts: 1, DeadlineAt: &future},
+			wantStatus: JobStatusRunning,
+		},
+		{
+			name:       "no lease or deadline, timed out since it started",
+			job:        Job{Attempts: 1, StartedAt: &longAgo},
+			wantStatus: JobStatusQueued,
+		},
+	}
+
+	for _, store := range testJobStores {
+		for _, tt := range tests {
+			t.Run(store.name+"/"+tt.name, func(t *testing.T) {
+				ctx := context.Background()
+				s := store.open(t)
+				q := newTestJobQueue(s, QueueConfig{JobTimeout: time.Minute})
+
+				job := tt.job
+				job.ID, job.Type, job.SessionID, job.Payload = "orphan", JobTypeParticipantAssessment, "session", []byte(`{}`)
+				job.Status, job.MaxAttempts, job.RunAt, job.CreatedAt, job.UpdatedAt = JobStatusRunning, 2, longAgo, longAgo, longAgo
+				if job.StartedAt == nil {
+					job.StartedAt = &now
+				}
+				if err := s.Enqueue(ctx, &job); err != nil {
+					t.Fatal(err)
+				}
+
+				q.recoverOrphaned(ctx)
+
+				recovered, err := s.Get(ctx, job.ID)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if recovered.Status != tt.wantStatus {
+					t.Fatalf("job is %s, want %s", recovered.Status, tt.wantStatus)
+				}
+				if tt.wantStatus != JobStatusRunning && (recovered.LeaseExpiresAt != nil || len(recovered.Errors) != 1) {
+					t.Errorf("recovered job kept lease %v or has errors %v", recovered.LeaseExpiresAt, recovered.Errors)
+				}
+			})
+		}
+	}
+}
+
+func TestJobQueueRejectsResultsAfterLeaseLost(t *testing.T) {
+	for _, store := range testJobStores {
+		t.Run(store.name, func(t *testing.T) {
+			ctx := context.Background()
+			s := store.open(t)
+			stalled := newTestJobQueue(s, QueueConfig{Retry: RetryPolicy{MaxAttempts: 3}})
+			reclaiming := newTestJobQueue(s, QueueConfig{Retry: RetryPolicy{MaxAttempts: 3}})
+
+			job, err := stalled.Enqueue(ctx, JobTypeParticipantAssessment, "session", "participant", "payload")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := s.Claim(ctx, time.Now().UTC()); err != nil {
+				t.Fatal(err)
+			}
+			expired := time.Now().UTC().Add(-time.Second)
+			if _, err := s.Update(ctx, job.ID, func(job *Job) error {
+				job.LeaseOwner, job.LeaseExpiresAt = stalled.instance, &expired
+				return nil
+			}); err != nil {
+				t.Fatal(err)
+			}
+
+			// The stalled worker missed its renewals, so another instance reclaims and reruns the job
+			reclaiming.recoverOrphaned(ctx)
+			if _, err := s.Claim(ctx, time.Now().UTC()); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := s.Update(ctx, job.ID, func(job *Job) error {
+				job.LeaseOwner = reclaiming.instance
+				return nil
+			}); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := stalled.complete(ctx, job.ID, stalled.instance); !errors.Is(err, ErrJobLeaseLost) {
+				t.Fatalf("stalled worker's result: %v, want %v", err, ErrJobLeaseLost)
+			}
+			if err := reclaiming.complete(ctx, job.ID, reclaiming.instance); err != nil {
+				t.Fatal(err)
+			}
+			if job, _ = s.Get(ctx, job.ID); job.Status != JobStatusSucceeded || job.Attempts != 2 {
+				t.Errorf("job %s after %d attempts, want succeeded after 2", job.Status, job.Attempts)
+			}
+		})
+	}
+}
+
+func TestJobQueueRenewsLeaseOfLongJobs(t *testing.T) {
+	for _, store := range testJobStores {
+		t.Run(store.name, func(t *testing.T) {
+			s := store.open(t)
+			config := QueueConfig{LeaseDuration: 150 * time.Millisecond, OrphanCheckInterval: 10 * time.Millisecond}
+			var mu sync.Mutex
+			runs := 0
+			handler := func(ctx context.Context, job *Job) error {
+				mu.Lock()
+				runs++
+				mu.Unlock()
+				select {
+				case <-ctx.Done():
+					return ctx.Err()
+				case <-time.After(600 * time.Millisecond):
+					return nil
+				}
+			}
+
+			// Two instances share the store; the one not running the job watches for orphans
+			first, second := newTestJobQueue(s, config), newTestJobQueue(s, config)
+			first.Register(JobTypeParticipantAssessment, handler)
+			second.Register(JobTypeParticipantAssessment, handler)
+			job, err := first.Enqueue(context.Background(), JobTypeParticipantAssessment, "session", "participant", "payload")
+			if err != nil {
+				t.Fatal(err)
+			}
+			first.Start(context.Background())
+			defer first.Shutdown(context.Background())
+			waitForJobStatus(t, first, job.ID, JobStatusRunning)
+			second.Start(context.Background())
+			defer second.Shutdown(context.Background())
+
+			job = waitForJobStatus(t, first, job.ID, JobStatusSucceeded, JobStatusFailed)
+			mu.Lock()
+			defer mu.Unlock()
+			if job.Status != JobStatusSucceeded || job.Attempts != 1 || runs != 1 {
+				t.Errorf("job %s after %d attempts and %d runs, want succeeded after one", job.Status, job.Attempts, runs)
+			}
+		})
+	}
+}
+
+func TestJobQueueEnqueueIdempotent(t *testing.T) {
+	type submission struct {
+		key           string
+		participantID string
+		payload       string
+	}
+	first := submission{key: "sync-1", participantID: "p1", payload: "transcript v1"}
+	tests := []struct {
+		name          string
+		window        time.Duration
+		finishFirst   string // status the first job is moved to before resubmitting
+		second        submission
+		wantDuplicate bool
+		wantErr       error
+	}{
+		{name: "same key and payload", window: time.Minute, second: first, wantDuplicate: true},
+		{name: "same key, different payload", window: time.Minute, second: submission{key: "sync-1", participantID: "p1", payload: "transcript v2"}, wantErr: ErrIdempotencyKeyReused},
+		{name: "different key", window: time.Minute, second: submission{key: "sync-2", participantID: "p1", payload: "transcript v1"}},
+		{name: "other participant", window: time.Minute, second: submission{key: "sync-1", participantID: "p2", payload: "transcript v1"}},
+		{name: "earlier job succeeded", window: time.Minute, finishFirst: JobStatusSucceeded, second: first, wantDuplicate: true},
+		{name: "earlier job failed", window: time.Minute, finishFirst: JobStatusFailed, second: first},
+		{name: "earlier job cancelled", window: time.Minute, finishFirst: JobStatusCancelled, second: first},
+		{name: "outside the window", second: first},
+	}
+
+	for _, store := range testJobStores {
+		for _, tt := range tests {
+			t.Run(store.name+"/"+tt.name, func(t *testing.T) {
+				ctx := context.Background()
+				s := store.open(t)
+				q := newTestJobQueue(s, QueueConfig{IdempotencyWindow: tt.window})
+
+				original, duplicate, err := q.EnqueueIdempotent(ctx, JobTypeParticipantAssessment, "session", first.participantID, first.key, first.payload)
+				if err != nil || duplicate {
+					t.Fatalf("first submission: duplicate %v, error %v", duplicate, err)
+				}
+				if tt.finishFirst != "" {
+					if _, err := s.Claim(ctx, time.Now().UTC()); err != nil {
+						t.Fatal(err)
+					}
+					if _, err := s.Update(ctx, original.ID, func(job *Job) error {
+						return job.transition(tt.finishFirst, time.Now().UTC())
+					}); err != nil {
+						t.Fatal(err)
+					}
+				}
+
+				job, duplicate, err := q.EnqueueIdempotent(ctx, JobTypeParticipantAssessment, "session", tt.second.participantID, tt.second.key, tt.second.payload)
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("second submission: %v, want %v", err, tt.wantErr)
+				}
+				if tt.wantErr != nil {
+					return
+				}
+				if duplicate != tt.wantDuplicate || (job.ID == original.ID) != tt.wantDuplicate {
+					t.Fatalf("second submission got job %s (duplicate %v), first was %s; want duplicate %v", job.ID, duplicate, original.ID, tt.wantDuplicate)
+				}
+			})
+		}
+	}
+}
+
+func TestJobQueueEnqueueIdempotentHashesPayloadWithoutKey(t *testing.T) {
+	for _, store := range testJobStores {
+		t.Run(store.name, func(t *testing.T) {
+			ctx := context.Background()
+			q := newTestJobQueue(store.open(t), QueueConfig{IdempotencyWindow: time.Minute})
+
+			// Concurrent retries of the same sync collapse to one job
+			jobs := make(chan *Job, 5)
+			var wg sync.WaitGroup
+			for i := 0; i < cap(jobs); i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					job, _, err := q.EnqueueIdempotent(ctx, JobTypeConsolidatedAssessment, "session", "", "", "transcript v1")
+					if err != nil {
+						t.Error(err)
+						return
+					}
+					jobs <- job
+				}()
+			}
+			wg.Wait()
+			close(jobs)
+
+			ids := map[string]bool{}
+			for job := range jobs {
+				ids[job.ID] = true
+			}
+			if len(ids) != 1 {
+				t.Fatalf("%d jobs created for one payload, want 1", len(ids))
+			}
+
+			changed, duplicate, err := q.EnqueueIdempotent(ctx, JobTypeConsolidatedAssessment, "session", "", "", "transcript v2")
+			if err != nil || duplicate || ids[changed.ID] {
+				t.Fatalf("changed payload: job %s, duplicate %v, error %v; want a new job", changed.ID, duplicate, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,454 @@
+package assessment
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrResultNotFound is returned when a repository has no record for the requested key
+var ErrResultNotFound = errors.New("assessment result not found")
+
+// TranscriptRecord is a transcript received for a participant in a session
+type TranscriptRecord struct {
+	SessionID       string    `json:"session_id"`
+	ParticipantID   string    `json:"participant_id"`
+	ParticipantName string    `json:"participant_name,omitempty"`
+	Role            string    `json:"role,omitempty"`
+	Transcript      string    `json:"transcript"`
+	ClientTimestamp int64     `json:"timestamp"`
+	ReceivedAt      time.Time `json:"received_at"`
+}
+
+// TranscriptChunk is one delta of a participant's live transcript. Chunks are keyed by
+// the client timestamp, so a resent chunk is stored once.
+type TranscriptChunk struct {
+	SessionID       string    `json:"session_id"`
+	ParticipantID   string    `json:"participant_id"`
+	ParticipantName string    `json:"participant_name,omitempty"`
+	Role            string    `json:"role,omitempty"`
+	Text            string    `json:"text"`
+	ClientTimestamp int64     `json:"timestamp"`
+	ReceivedAt      time.Time `json:"received_at"`
+}
+
+// AssembleTranscript joins chunks, ordered by client timestamp, into the full transcript
+func AssembleTranscript(chunks []TranscriptChunk) string {
+	ordered := append([]TranscriptChunk(nil), chunks...)
+	sort.SliceStable(ordered, func(i, j int) bool { return ordered[i].ClientTimestamp < ordered[j].ClientTimestamp })
+
+	parts := make([]string, 0, len(ordered))
+	for _, chunk := range ordered {
+		if text := strings.TrimSpace(chunk.Text); text != "" {
+			parts = append(parts, text)
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
+// ConsolidatedParticipantResult is one participant's entry in a consolidated result
+type ConsolidatedParticipantResult struct {
+	ParticipantID   string             `json:"participant_id"`
+	ParticipantName string             `json:"participant_name,omitempty"`
+	Results         []AssessmentResult `json:"results"`
+}
+
+// ConsolidatedResult is the combined participant and group outcome for a session
+type ConsolidatedResult struct {
+	SessionID       string                          `json:"session_id"`
+	Type            string                          `json:"type,omitempty"`
+	Assessments     []ConsolidatedParticipantResult `json:"assessments"`
+	GroupAssessment *GroupAssessmentResponse        `json:"group_assessment"`
+	Timestamp       int64                           `json:"timestamp"`
+	// Partial is set while assessments are still running; Pending lists the participant IDs
+	// (and "group") not finished yet
+	Partial bool     `json:"partial,omitempty"`
+	Pending []string `json:"pending,omitempty"`
+}
+
+// TranscriptionJob tracks an async transcription submitted for a session
+type TranscriptionJob struct {
+	SessionID         string         `json:"session_id"`
+	TranscriptionID   string         `json:"transcription_id"`
+	FileID            string         `json:"file_id"`
+	SpeakerMapping    map[int]string `json:"speaker_mapping,omitempty"`
+	ManualCorrections map[int]string `json:"manual_corrections,omitempty"`
+	// SpeakerBindings binds speaker numbers to the IDs of participants on the session
+	// roster; a bound speaker is that participant whatever it is named
+	SpeakerBindings map[int]string `json:"speaker_bindings,omitempty"`
+	// Status is TranscriptionProcessing until Soniox reports completion or failure
+	Status       string `json:"status"`
+	ErrorMessage string `json:"error_message,omitempty"`
+	// WebhookSecret authenticates Soniox's completion callback; empty when the job is polled
+	WebhookSecret string `json:"-"`
+	// Tokens is the finished transcript, stored on completion so reads don't go to Soniox
+	Tokens []TranscriptToken `json:"tokens,omitempty"`
+	// Participants is the roster the transcript's speakers are bound to
+	Participants []SessionParticipant `json:"participants,omitempty"`
+	// SpeakerNames is every diarized speaker's name as resolved by ResolveSpeakerNames;
+	// SpeakersNamedAt is nil until that has run
+	SpeakerNames    []SpeakerName `json:"speaker_names,omitempty"`
+	SpeakersNamedAt *time.Time    `json:"speakers_named_at,omitempty"`
+	// AssessmentJobID is the consolidated assessment queued from the finished transcript
+	AssessmentJobID string     `json:"assessment_job_id,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+	CompletedAt     *time.Time `json:"completed_at,omitempty"`
+	// When the file and transcription were deleted from the transcriber. Only
+	// RecordRemoteDeletion sets them; saving the job keeps them while the transcription
+	// stays the same.
+	FileDeletedAt          *time.Time `json:"file_deleted_at,omitempty"`
+	TranscriptionDeletedAt *time.Time `json:"transcription_deleted_at,omitempty"`
+}
+
+// ResultRepository persists transcripts, assessment results and transcription jobs
+type ResultRepository interface {
+	SaveTranscript(ctx context.Context, record TranscriptRecord) error
+	ListTranscripts(ctx context.Context, sessionID string) ([]TranscriptRecord, error)
+
+	// AppendTranscriptChunk stores a chunk and reports false when one with the same
+	// session, participant and client timestamp is already stored
+	AppendTranscriptChunk(ctx context.Context, chunk TranscriptChunk) (bool, error)
+	// ListTranscriptChunks returns a session's chunks ordered by client timestamp; an
+	// empty participantID returns every participant's chunks
+	ListTranscriptChunks(ctx context.Context, sessionID, participantID string) ([]TranscriptChunk, error)
+
+	// SaveParticipantResult stores the result as a new run, sets result.RunID, and makes
+	// it the official result unless a reviewer pinned another run
+	SaveParticipantResult(ctx context.Context, result *AssessmentResponse) error
+	// GetParticipantResult returns the official result
+	GetParticipantResult(ctx context.Context, sessionID, participantID string) (*AssessmentResponse, error)
+	ListParticipantResults(ctx context.Context, sessionID string) ([]*AssessmentResponse, error)
+
+	// ListAssessmentRuns returns every run of a participant, oldest first
+	ListAssessmentRuns(ctx context.Context, sessionID, participantID string) ([]*AssessmentRun, error)
+	GetAssessmentRun(ctx context.Context, sessionID, participantID, runID string) (*AssessmentRun, error)
+	// SetOfficialRun pins a run as the official result; an empty runID unpins, making
+	// the newest run official again
+	SetOfficialRun(ctx context.Context, sessionID, participantID, runID string) error
+
+	SaveGroupResult(ctx context.Context, result *GroupAssessmentResponse) error
+	GetGroupResult(ctx context.Context, sessionID string) (*GroupAssessmentResponse, error)
+
+	SaveConsolidatedResult(ctx context.Context, result *ConsolidatedResult) error
+	GetConsolidatedResult(ctx context.Context, sessionID string) (*ConsolidatedResult, error)
+
+	SaveTranscriptionJob(ctx context.Context, job TranscriptionJob) error
+	GetTranscriptionJob(ctx context.Context, sessionID string) (*TranscriptionJob, error)
+	// ListTranscriptionJobsWithRemoteData returns the jobs whose file or transcription
+	// hasn't been deleted from the transcriber
+	ListTranscriptionJobsWithRemoteData(ctx context.Context) ([]TranscriptionJob, error)
+
+	// RecordRemoteDeletion appends to the deletion log, marks the resource deleted on the
+	// session's job if the job still refers to it, and clears it from the pending deletions
+	RecordRemoteDeletion(ctx context.Context, deletion RemoteDeletion) error
+	// SavePendingRemoteDeletions records remote data no job may refer to any more, so it
+	// is deleted even if the first attempt fails; a resource already pending is kept as is
+	SavePendingRemoteDeletions(ctx context.Context, pending []PendingRemoteDeletion) error
+	// ListPendingRemoteDeletions returns the remote data awaiting deletion, oldest first
+	ListPendingRemoteDeletions(ctx context.Context) ([]PendingRemoteDeletion, error)
+	// ListRemoteDeletions returns a session's deletion log, oldest first
+	ListRemoteDeletions(ctx context.Context, sessionID string) ([]RemoteDeletion, error)
+}
+
+// MemoryResultRepository is a process-local ResultRepository for demos and local development
+type MemoryResultRepository struct {
+	mu                 sync.RWMutex
+	transcripts        map[string][]TranscriptRecord
+	chunks             map[string][]TranscriptChunk
+	participantResults map[string]map[string]*AssessmentResponse
+	runs               map[string]map[string][]*AssessmentRun
+	pinnedRuns         map[string]map[string]string
+	groupResults       map[string]*GroupAssessmentResponse
+	consolidated       map[string]*ConsolidatedResult
+	transcriptionJobs  map[string]*TranscriptionJob
+	remoteDeletions    map[string][]RemoteDeletion
+	pendingDeletions   map[string]PendingRemoteDeletion
+}
+
+// NewMemoryResultRepository creates an empty in-memory repository
+func NewMemoryResultRepository() *MemoryResultRepository {
+	return &MemoryResultRepository{
+		transcripts:        make(map[string][]TranscriptRecord),
+		chunks:             make(map[string][]TranscriptChunk),
+		participantResults: make(map[string]map[string]*AssessmentResponse),
+		runs:               make(map[string]map[string][]*AssessmentRun),
+		pinnedRuns:         make(map[string]map[string]string),
+		groupResults:       make(map[string]*GroupAssessmentResponse),
+		consolidated:       make(map[string]*ConsolidatedResult),
+		transcriptionJobs:  make(map[string]*TranscriptionJob),
+		remoteDeletions:    make(map[string][]RemoteDeletion),
+		pendingDeletions:   make(map[string]PendingRemoteDeletion),
+	}
+}
+
+func (r *MemoryResultRepository) SaveTranscript(ctx context.Context, record TranscriptRecord) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.transcripts[record.SessionID] = append(r.transcripts[record.SessionID], record)
+	return nil
+}
+
+func (r *MemoryResultRepository) ListTranscripts(ctx context.Context, sessionID string) ([]TranscriptRecord, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return append([]TranscriptRecord(nil), r.transcripts[sessionID]...), nil
+}
+
+func (r *MemoryResultRepository) AppendTranscriptChunk(ctx context.Context, chunk TranscriptChunk) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, existing := range r.chunks[chunk.SessionID] {
+		if existing.ParticipantID == chunk.ParticipantID && existing.ClientTimestamp == chunk.ClientTimestamp {
+			return false, nil
+		}
+	}
+	r.chunks[chunk.SessionID] = append(r.chunks[chunk.SessionID], chunk)
+	return true, nil
+}
+
+func (r *MemoryResultRepository) ListTranscriptChunks(ctx context.Context, sessionID, participantID string) ([]TranscriptChunk, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	chunks := []TranscriptChunk{}
+	for _, chunk := range r.chunks[sessionID] {
+		if participantID == "" || chunk.ParticipantID == participantID {
+			chunks = append(chunks, chunk)
+		}
+	}
+	sort.SliceStable(chunks, func(i, j int) bool { return chunks[i].ClientTimestamp < chunks[j].ClientTimestamp })
+	return chunks, nil
+}
+
+func (r *MemoryResultRepository) SaveParticipantResult(ctx context.Context, result *AssessmentResponse) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	result.RunID = newRecordID()
+	if r.runs[result.SessionID] == nil {
+		r.runs[result.SessionID] = make(map[string][]*AssessmentRun)
+	}
+	r.runs[result.SessionID][result.ParticipantID] = append(r.runs[result.SessionID][result.ParticipantID], &AssessmentRun{
+		ID:            result.RunID,
+		SessionID:     result.SessionID,
+		ParticipantID: result.ParticipantID,
+		Result:        result,
+		CreatedAt:     time.Now().UTC(),
+	})
+
+	if r.pinnedRuns[result.SessionID][result.ParticipantID] == "" {
+		r.setParticipantResult(result)
+	}
+	return nil
+}
+
+// setParticipantResult makes result the official one. Callers hold r.mu.
+func (r *MemoryResultRepository) setParticipantResult(result *AssessmentResponse) {
+	if r.participantResults[result.SessionID] == nil {
+		r.participantResults[result.SessionID] = make(map[string]*AssessmentResponse)
+	}
+	r.participantResults[result.SessionID][result.ParticipantID] = result
+}
+
+func (r *MemoryResultRepository) ListAssessmentRuns(ctx context.Context, sessionID, participantID string) ([]*AssessmentRun, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	officialID := ""
+	if official, ok := r.participantResults[sessionID][participantID]; ok {
+		officialID = official.RunID
+	}
+	pinned := r.pinnedRuns[sessionID][participantID] != ""
+
+	runs := make([]*AssessmentRun, 0, len(r.runs[sessionID][participantID]))
+	for _, stored := range r.runs[sessionID][participantID] {
+		run := *stored
+		run.Official = run.ID == officialID
+		run.Pinned = run.Official && pinned
+		runs = append(runs, &run)
+	}
+	return runs, nil
+}
+
+func (r *MemoryResultRepository) GetAssessmentRun(ctx context.Context, sessionID, participantID, runID string) (*AssessmentRun, error) {
+	runs, err := r.ListAssessmentRuns(ctx, sessionID, participantID)
+	if err != nil {
+		return nil, err
+	}
+	for _, run := range runs {
+		if run.ID == runID {
+			return run, nil
+		}
+	}
+	return nil, ErrResultNotFound
+}
+
+func (r *MemoryResultRepository) SetOfficialRun(ctx context.Context, sessionID, participantID, runID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	runs := r.runs[sessionID][participantID]
+	if len(runs) == 0 {
+		return ErrResultNotFound
+	}
+
+	official := runs[len(runs)-1]
+	if runID != "" {
+		official = nil
+		for _, run := range runs {
+			if run.ID == runID {
+				official = run
+			}
+		}
+		if official == nil {
+			return ErrResultNotFound
+		}
+	}
+
+	if r.pinnedRuns[sessionID] == nil {
+		r.pinnedRuns[sessionID] = make(map[string]string)
+	}
+	r.pinnedRuns[sessionID][participantID] = runID
+	r.setParticipantResult(official.Result)
+	return nil
+}
+
+func (r *MemoryResultRepository) GetParticipantResult(ctx context.Context, sessionID, participantID string) (*AssessmentResponse, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if result, ok := r.participantResults[sessionID][participantID]; ok {
+		return result, nil
+	}
+	return nil, ErrResultNotFound
+}
+
+func (r *MemoryResultRepository) ListParticipantResults(ctx context.Context, sessionID string) ([]*AssessmentResponse, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	results := make([]*AssessmentResponse, 0, len(r.participantResults[sessionID]))
+	for _, result := range r.participantResults[sessionID] {
+		results = append(results, result)
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].ParticipantID < results[j].ParticipantID })
+	return results, nil
+}
+
+func (r *MemoryResultRepository) SaveGroupResult(ctx context.Context, result *GroupAssessmentResponse) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.groupResults[result.SessionID] = result
+	return nil
+}
+
+func (r *MemoryResultRepository) GetGroupResult(ctx context.Context, sessionID string) (*GroupAssessmentResponse, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if result, ok := r.groupResults[sessionID]; ok {
+		return result, nil
+	}
+	return nil, ErrResultNotFound
+}
+
+func (r *MemoryResultRepository) SaveConsolidatedResult(ctx context.Context, result *ConsolidatedResult) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.consolidated[result.SessionID] = result
+	return nil
+}
+
+func (r *MemoryResultRepository) GetConsolidatedResult(ctx context.Context, sessionID string) (*ConsolidatedResult, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if result, ok := r.consolidated[sessionID]; ok {
+		return result, nil
+	}
+	return nil, ErrResultNotFound
+}
+
+func (r *MemoryResultRepository) SaveTranscriptionJob(ctx context.Context, job TranscriptionJob) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if job.Status == "" {
+		job.Status = TranscriptionProcessing
+	}
+	job.FileDeletedAt, job.TranscriptionDeletedAt = nil, nil
+	if existing, ok := r.transcriptionJobs[job.SessionID]; ok && existing.TranscriptionID == job.TranscriptionID {
+		job.FileDeletedAt, job.TranscriptionDeletedAt = existing.FileDeletedAt, existing.TranscriptionDeletedAt
+	}
+	r.transcriptionJobs[job.SessionID] = &job
+	return nil
+}
+
+func (r *MemoryResultRepository) GetTranscriptionJob(ctx context.Context, sessionID string) (*TranscriptionJob, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if job, ok := r.transcriptionJobs[sessionID]; ok {
+		copied := *job
+		return &copied, nil
+	}
+	return nil, ErrResultNotFound
+}
+
+func (r *MemoryResultRepository) ListTranscriptionJobsWithRemoteData(ctx context.Context) ([]TranscriptionJob, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	jobs := []TranscriptionJob{}
+	for _, job := range r.transcriptionJobs {
+		if job.HasRemoteData() {
+			jobs = append(jobs, *job)
+		}
+	}
+	sort.Slice(jobs, func(i, j int) bool { return jobs[i].CreatedAt.Before(jobs[j].CreatedAt) })
+	return jobs, nil
+}
+
+func (r *MemoryResultRepository) RecordRemoteDeletion(ctx context.Context, deletion RemoteDeletion) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.remoteDeletions[deletion.SessionID] = append(r.remoteDeletions[deletion.SessionID], deletion)
+	delete(r.pendingDeletions, deletion.Resource+"/"+deletion.RemoteID)
+
+	job, ok := r.transcriptionJobs[deletion.SessionID]
+	if !ok {
+		return nil
+	}
+	deletedAt := deletion.DeletedAt
+	if deletion.Resource == RemoteFile && job.FileID == deletion.RemoteID {
+		job.FileDeletedAt = &deletedAt
+	}
+	if deletion.Resource == RemoteTranscription && job.TranscriptionID == deletion.RemoteID {
+		job.TranscriptionDeletedAt = &deletedAt
+	}
+	return nil
+}
+
+func (r *MemoryResultRepository) ListRemoteDeletions(ctx context.Context, sessionID string) ([]RemoteDeletion, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return append([]RemoteDeletion{}, r.remoteDeletions[sessionID]...), nil
+}
+
+func (r *MemoryResultRepository) SavePendingRemoteDeletions(ctx context.Context, pending []PendingRemoteDeletion) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, deletion := range pending {
+		key := deletion.Resource + "/" + deletion.RemoteID
+		if _, ok := r.pendingDeletions[key]; !ok {
+			r.pendingDeletions[key] = deletion
+		}
+	}
+	return nil
+}
+
+func (r *MemoryResultRepository) ListPendingRemoteDeletions(ctx context.Context) ([]PendingRemoteDeletion, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	pending := make([]PendingRemoteDeletion, 0, len(r.pendingDeletions))
+	for _, deletion := range r.pendingDeletions {
+		pending = append(pending, deletion)
+	}
+	sort.Slice(pending, func(i, j int) bool { return pending[i].CreatedAt.Before(pending[j].CreatedAt) })
+	return pending, nil
+}
@@ -0,0 +1,764 @@
+package assessment
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"embed"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// SQLDialect selects placeholder syntax for the SQL repositories
+type SQLDialect string
+
+const (
+	DialectPostgres SQLDialect = "postgres"
+	DialectSQLite   SQLDialect = "sqlite"
+)
+
+// SQLResultRepository is a ResultRepository backed by PostgreSQL or SQLite.
+// The caller opens the *sql.DB with the driver of its choice.
+type SQLResultRepository struct {
+	db      *sql.DB
+	dialect SQLDialect
+}
+
+// NewSQLResultRepository creates a repository on an open database
+func NewSQLResultRepository(db *sql.DB, dialect SQLDialect) *SQLResultRepository {
+	return &SQLResultRepository{db: db, dialect: dialect}
+}
+
+// Migrate applies the embedded migrations that have not been applied yet
+func (r *SQLResultRepository) Migrate(ctx context.Context) error {
+	return migrate(ctx, r.db, r.dialect)
+}
+
+// migrate applies embedded migrations in file name order, recording each in schema_migrations
+func migrate(ctx context.Context, db *sql.DB, dialect SQLDialect) error {
+	if _, err := db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    TEXT PRIMARY KEY,
+		applied_at TIMESTAMP NOT NULL
+	)`); err != nil {
+		return fmt.Errorf("failed to create schema_migrations: %w", err)
+	}
+
+	names, err := fs.Glob(migrationFiles, "migrations/*.sql")
+	if err != nil {
+		return err
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		version := strings.TrimSuffix(strings.TrimPrefix(name, "migrations/"), ".sql")
+
+		var applied int
+		if err := db.QueryRowContext(ctx, rebind(dialect, `SELECT COUNT(*) FROM schema_migrations WHERE version = ?`), version).Scan(&applied); err != nil {
+			return fmt.Errorf("failed to check migration %s: %w", version, err)
+		}
+		if applied > 0 {
+			continue
+		}
+
+		contents, err := migrationFiles.ReadFile(name)
+		if err != nil {
+			return err
+		}
+
+		tx, err := db.BeginTx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		for _, statement := range splitStatements(string(contents)) {
+			if _, err := tx.ExecContext(ctx, statement); err != nil {
+				tx.Rollback()
+				return fmt.Errorf("migration %s failed: %w", version, err)
+			}
+		}
+		if _, err := tx.ExecContext(ctx, rebind(dialect, `INSERT INTO schema_migrations (version, applied_at) VALUES (?, ?)`), version, time.Now().UTC()); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("failed to record migration %s: %w", version, err)
+		}
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("failed to commit migration %s: %w", version, err)
+		}
+	}
+
+	return nil
+}
+
+func (r *SQLResultRepository) SaveTranscript(ctx context.Context, record TranscriptRecord) error {
+	if record.ReceivedAt.IsZero() {
+		record.ReceivedAt = time.Now().UTC()
+	}
+
+	_, err := r.db.ExecContext(ctx, rebind(r.dialect, `
+		INSERT INTO assessment_transcripts
+			(id, session_id, participant_id, participant_name, role, transcript, client_timestamp, received_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`),
+		newRecordID(), record.SessionID, record.ParticipantID, record.ParticipantName, record.Role,
+		record.Transcript, record.ClientTimestamp, record.ReceivedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save transcript: %w", err)
+	}
+	return nil
+}
+
+func (r *SQLResultRepository) ListTranscripts(ctx context.Context, sessionID string) ([]TranscriptRecord, error) {
+	rows, err := r.db.QueryContext(ctx, rebind(r.dialect, `
+		SELECT session_id, participant_id, participant_name, role, transcript, client_timestamp, received_at
+		FROM assessment_transcripts
+		WHERE session_id = ?
+		ORDER BY received_at`), sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list transcripts: %w", err)
+	}
+	defer rows.Close()
+
+	records := []TranscriptRecord{}
+	for rows.Next() {
+		var record TranscriptRecord
+		if err := rows.Scan(&record.SessionID, &record.ParticipantID, &record.ParticipantName, &record.Role,
+			&record.Transcript, &record.ClientTimestamp, &record.ReceivedAt); err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+	return records, rows.Err()
+}
+
+func (r *SQLResultRepository) AppendTranscriptChunk(ctx context.Context, chunk TranscriptChunk) (bool, error) {
+	if chunk.ReceivedAt.IsZero() {
+		chunk.ReceivedAt = time.Now().UTC()
+	}
+
+	result, err := r.db.ExecContext(ctx, rebind(r.dialect, `
+		INSERT INTO assessment_transcript_chunks
+			(session_id, participant_id, client_timestamp, participant_name, role, text, received_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (session_id, participant_id, client_timestamp) DO NOTHING`),
+		chunk.SessionID, chunk.ParticipantID, chunk.ClientTimestamp, chunk.ParticipantName, chunk.Role,
+		chunk.Text, chunk.ReceivedAt)
+	if err != nil {
+		return false, fmt.Errorf("failed to save transcript chunk: %w", err)
+	}
+	inserted, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return inserted > 0, nil
+}
+
+func (r *SQLResultRepository) ListTranscriptChunks(ctx context.Context, sessionID, participantID string) ([]TranscriptChunk, error) {
+	query := `
+		SELECT session_id, participant_id, participant_name, role, text, client_timestamp, received_at
+		FROM assessment_transcript_chunks
+		WHERE session_id = ?`
+	args := []any{sessionID}
+	if participantID != "" {
+		query += ` AND participant_id = ?`
+		args = append(args, participantID)
+	}
+
+	rows, err := r.db.QueryContext(ctx, rebind(r.dialect, query+` ORDER BY client_timestamp`), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list transcript chunks: %w", err)
+	}
+	defer rows.Close()
+
+	chunks := []TranscriptChunk{}
+	for rows.Next() {
+		var chunk TranscriptChunk
+		if err := rows.Scan(&chunk.SessionID, &chunk.ParticipantID, &chunk.ParticipantName, &chunk.Role,
+			&chunk.Text, &chunk.ClientTimestamp, &chunk.ReceivedAt); err != nil {
+			return nil, err
+		}
+		chunks = append(chunks, chunk)
+	}
+	return chunks, rows.Err()
+}
+
+func (r *SQLResultRepository) SaveParticipantResult(ctx context.Context, result *AssessmentResponse) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to save participant result: %w", err)
+	}
+	defer tx.Rollback()
+
+	result.RunID = newRecordID()
+	payload, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, rebind(r.dialect, `
+		INSERT INTO assessment_runs (id, session_id, participant_id, payload_json, created_at)
+		VALUES (?, ?, ?, ?, ?)`),
+		result.RunID, result.SessionID, result.ParticipantID, string(payload), time.Now().UTC())
+	if err != nil {
+		return fmt.Errorf("failed to save assessment run: %w", err)
+	}
+
+	if err := r.upsertParticipantResult(ctx, tx, result, false, false); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// upsertParticipantResult writes the official result. Unless replacePinned is set, a
+// result a reviewer pinned is left in place.
+func (r *SQLResultRepository) upsertParticipantResult(ctx context.Context, tx *sql.Tx, result *AssessmentResponse, pin, replacePinned bool) error {
+	resultsJSON, err := json.Marshal(result.Results)
+	if err != nil {
+		return err
+	}
+	warningsJSON, err := json.Marshal(result.Warnings)
+	if err != nil {
+		return err
+	}
+	provenanceJSON, err := json.Marshal(result.Provenance)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO assessment_participant_results
+			(session_id, participant_id, overall_score, summary, results_json, warnings_json, run_id, pinned, provenance_json, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (session_id, participant_id) DO UPDATE SET
+			overall_score = excluded.overall_score,
+			summary = excluded.summary,
+			results_json = excluded.results_json,
+			warnings_json = excluded.warnings_json,
+			run_id = excluded.run_id,
+			pinned = excluded.pinned,
+			provenance_json = excluded.provenance_json,
+			updated_at = excluded.updated_at`
+	args := []any{result.SessionID, result.ParticipantID, result.OverallScore, result.Summary,
+		string(resultsJSON), string(warningsJSON), result.RunID, pin, string(provenanceJSON), time.Now().UTC()}
+	if !replacePinned {
+		query += `
+		WHERE assessment_participant_results.pinned = ?`
+		args = append(args, false)
+	}
+
+	if _, err := tx.ExecContext(ctx, rebind(r.dialect, query), args...); err != nil {
+		return fmt.Errorf("failed to save participant result: %w", err)
+	}
+	return nil
+}
+
+const participantResultColumns = `session_id, participant_id, overall_score, summary, results_json, warnings_json, run_id, provenance_json`
+
+func (r *SQLResultRepository) GetParticipantResult(ctx context.Context, sessionID, participantID string) (*AssessmentResponse, error) {
+	row := r.db.QueryRowContext(ctx, rebind(r.dialect, `
+		SELECT `+participantResultColumns+`
+		FROM assessment_participant_results
+		WHERE session_id = ? AND participant_id = ?`), sessionID, participantID)
+	return scanParticipantResult(row)
+}
+
+func (r *SQLResultRepository) ListParticipantResults(ctx context.Context, sessionID string) ([]*AssessmentResponse, error) {
+	rows, err := r.db.QueryContext(ctx, rebind(r.dialect, `
+		SELECT `+participantResultColumns+`
+		FROM assessment_participant_results
+		WHERE session_id = ?
+		ORDER BY participant_id`), sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list participant results: %w", err)
+	}
+	defer rows.Close()
+
+	results := []*AssessmentResponse{}
+	for rows.Next() {
+		result, err := scanParticipantResult(rows)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+	return results, rows.Err()
+}
+
+func (r *SQLResultRepository) ListAssessmentRuns(ctx context.Context, sessionID, participantID string) ([]*AssessmentRun, error) {
+	var officialID string
+	var pinned bool
+	err := r.db.QueryRowContext(ctx, rebind(r.dialect, `
+		SELECT run_id, pinned FROM assessment_participant_results
+		WHERE session_id = ? AND participant_id = ?`), sessionID, participantID).Scan(&officialID, &pinned)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("failed to load official run: %w", err)
+	}
+
+	rows, err := r.db.QueryContext(ctx, rebind(r.dialect, `
+		SELECT id, payload_json, created_at FROM assessment_runs
+		WHERE session_id = ? AND participant_id = ?
+		ORDER BY created_at`), sessionID, participantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list assessment runs: %w", err)
+	}
+	defer rows.Close()
+
+	runs := []*AssessmentRun{}
+	for rows.Next() {
+		run := &AssessmentRun{SessionID: sessionID, ParticipantID: participantID}
+		var payload string
+		if err := rows.Scan(&run.ID, &payload, &run.CreatedAt); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(payload), &run.Result); err != nil {
+			return nil, fmt.Errorf("failed to decode assessment run: %w", err)
+		}
+		run.Official = run.ID == officialID
+		run.Pinned = run.Official && pinned
+		runs = append(runs, run)
+	}
+	return runs, rows.Err()
+}
+
+func (r *SQLResultRepository) GetAssessmentRun(ctx context.Context, sessionID, participantID, runID string) (*AssessmentRun, error) {
+	runs, err := r.ListAssessmentRuns(ctx, sessionID, participantID)
+	if err != nil {
+		return nil, err
+	}
+	for _, run := range runs {
+		if run.ID == runID {
+			return run, nil
+		}
+	}
+	return nil, ErrResultNotFound
+}
+
+func (r *SQLResultRepository) SetOfficialRun(ctx context.Context, sessionID, participantID, runID string) error {
+	runs, err := r.ListAssessmentRuns(ctx, sessionID, participantID)
+	if err != nil {
+		return err
+	}
+	if len(runs) == 0 {
+		return ErrResultNotFound
+	}
+
+	official := runs[len(runs)-1]
+	if runID != "" {
+		official = nil
+		for _, run := range runs {
+			if run.ID == runID {
+				official = run
+			}
+		}
+		if official == nil {
+			return ErrResultNotFound
+		}
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to set official run: %w", err)
+	}
+	defer tx.Rollback()
+	if err := r.upsertParticipantResult(ctx, tx, official.Result, runID != "", true); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *SQLResultRepository) SaveGroupResult(ctx context.Context, result *GroupAssessmentResponse) error {
+	payload, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.ExecContext(ctx, rebind(r.dialect, `
+		INSERT INTO assessment_group_results (session_id, overall_score, solution_score, payload_json, updated_at)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (session_id) DO UPDATE SET
+			overall_score = excluded.overall_score,
+			solution_score = excluded.solution_score,
+			payload_json = excluded.payload_json,
+			updated_at = excluded.updated_at`),
+		result.SessionID, result.OverallScore, result.Score, string(payload), time.Now().UTC())
+	if err != nil {
+		return fmt.Errorf("failed to save group result: %w", err)
+	}
+	return nil
+}
+
+func (r *SQLResultRepository) GetGroupResult(ctx context.Context, sessionID string) (*GroupAssessmentResponse, error) {
+	var payload string
+	err := r.db.QueryRowContext(ctx, rebind(r.dialect, `
+		SELECT payload_json FROM assessment_group_results WHERE session_id = ?`), sessionID).Scan(&payload)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrResultNotFound
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to load group result: %w", err)
+	}
+
+	var result GroupAssessmentResponse
+	if err := json.Unmarshal([]byte(payload), &result); err != nil {
+		return nil, fmt.Errorf("failed to decode group result: %w", err)
+	}
+	return &result, nil
+}
+
+func (r *SQLResultRepository) SaveConsolidatedResult(ctx context.Context, result *ConsolidatedResult) error {
+	payload, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.ExecContext(ctx, rebind(r.dialect, `
+		INSERT INTO assessment_consolidated_results (session_id, result_type, payload_json, updated_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT (session_id) DO UPDATE SET
+			result_type = excluded.result_type,
+			payload_json = excluded.payload_json,
+			updated_at = excluded.updated_at`),
+		result.SessionID, result.Type, string(payload), time.Now().UTC())
+	if err != nil {
+		return fmt.Errorf("failed to save consolidated result: %w", err)
+	}
+	return nil
+}
+
+func (r *SQLResultRepository) GetConsolidatedResult(ctx context.Context, sessionID string) (*ConsolidatedResult, error) {
+	var payload string
+	err := r.db.QueryRowContext(ctx, rebind(r.dialect, `
+		SELECT payload_json FROM assessment_consolidated_results WHERE session_id = ?`), sessionID).Scan(&payload)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrResultNotFound
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to load consolidated result: %w", err)
+	}
+
+	var result ConsolidatedResult
+	if err := json.Unmarshal([]byte(payload), &result); err != nil {
+		return nil, fmt.Errorf("failed to decode consolidated result: %w", err)
+	}
+	return &result, nil
+}
+
+func (r *SQLResultRepository) SaveTranscriptionJob(ctx context.Context, job TranscriptionJob) error {
+	if job.CreatedAt.IsZero() {
+		job.CreatedAt = time.Now().UTC()
+	}
+	if job.Status == "" {
+		job.Status = TranscriptionProcessing
+	}
+	speakerMapping, err := json.Marshal(job.SpeakerMapping)
+	if err != nil {
+		return err
+	}
+	manualCorrections, err := json.Marshal(job.ManualCorrections)
+	if err != nil {
+		return err
+	}
+	speakerBindings, err := json.Marshal(job.SpeakerBindings)
+	if err != nil {
+		return err
+	}
+	tokens, err := json.Marshal(job.Tokens)
+	if err != nil {
+		return err
+	}
+	participants, err := json.Marshal(job.Participants)
+	if err != nil {
+		return err
+	}
+	speakerNames, err := json.Marshal(job.SpeakerNames)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.ExecContext(ctx, rebind(r.dialect, `
+		INSERT INTO transcription_jobs
+			(session_id, transcription_id, file_id, speaker_mapping_json, manual_corrections_json, speaker_bindings_json, status,
+			 error_message, webhook_secret, tokens_json, participants_json, speaker_names_json, speakers_named_at, assessment_job_id,
+			 created_at, completed_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (session_id) DO UPDATE SET
+			transcription_id = excluded.transcription_id,
+			file_id = excluded.file_id,
+			speaker_mapping_json = excluded.speaker_mapping_json,
+			manual_corrections_json = excluded.manual_corrections_json,
+			speaker_bindings_json = excluded.speaker_bindings_json,
+			status = excluded.status,
+			error_message = excluded.error_message,
+			webhook_secret = excluded.webhook_secret,
+			tokens_json = excluded.tokens_json,
+			participants_json = excluded.participants_json,
+			speaker_names_json = excluded.speaker_names_json,
+			speakers_named_at = excluded.speakers_named_at,
+			assessment_job_id = excluded.assessment_job_id,
+			created_at = excluded.created_at,
+			completed_at = excluded.completed_at,
+			file_deleted_at = CASE WHEN transcription_jobs.transcription_id = excluded.transcription_id
+				THEN transcription_jobs.file_deleted_at END,
+			transcription_deleted_at = CASE WHEN transcription_jobs.transcription_id = excluded.transcription_id
+				THEN transcription_jobs.transcription_deleted_at END`),
+		job.SessionID, job.TranscriptionID, job.FileID, string(speakerMapping), string(manualCorrections), string(speakerBindings), job.Status,
+		job.ErrorMessage, job.WebhookSecret, string(tokens), string(participants), string(speakerNames), job.SpeakersNamedAt, job.AssessmentJobID,
+		job.CreatedAt, job.CompletedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save transcription job: %w", err)
+	}
+	return nil
+}
+
+const transcriptionJobColumns = `session_id, transcription_id, file_id, speaker_mapping_json, manual_corrections_json,
+	speaker_bindings_json, status, error_message, webhook_secret, tokens_json, participants_json, speaker_names_json,
+	speakers_named_at, assessment_job_id, created_at, completed_at, file_deleted_at, transcription_deleted_at`
+
+func (r *SQLResultRepository) GetTranscriptionJob(ctx context.Context, sessionID string) (*TranscriptionJob, error) {
+	job, err := scanTranscriptionJob(r.db.QueryRowContext(ctx, rebind(r.dialect, `
+		SELECT `+transcriptionJobColumns+` FROM transcription_jobs WHERE session_id = ?`), sessionID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrResultNotFound
+	}
+	return job, err
+}
+
+func (r *SQLResultRepository) ListTranscriptionJobsWithRemoteData(ctx context.Context) ([]TranscriptionJob, error) {
+	rows, err := r.db.QueryContext(ctx, rebind(r.dialect, `
+		SELECT `+transcriptionJobColumns+` FROM transcription_jobs
+		WHERE (file_id <> '' AND file_deleted_at IS NULL) OR (transcription_id <> '' AND transcription_deleted_at IS NULL)
+		ORDER BY created_at`))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list transcription jobs: %w", err)
+	}
+	defer rows.Close()
+
+	jobs := []TranscriptionJob{}
+	for rows.Next() {
+		job, err := scanTranscriptionJob(rows)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, *job)
+	}
+	return jobs, rows.Err()
+}
+
+func scanTranscriptionJob(row rowScanner) (*TranscriptionJob, error) {
+	var job TranscriptionJob
+	var speakerMapping, manualCorrections, speakerBindings, tokens, participants, speakerNames string
+	var speakersNamedAt, completedAt, fileDeletedAt, transcriptionDeletedAt sql.NullTime
+	err := row.Scan(&job.SessionID, &job.TranscriptionID, &job.FileID, &speakerMapping, &manualCorrections,
+		&speakerBindings, &job.Status, &job.ErrorMessage, &job.WebhookSecret, &tokens, &participants, &speakerNames,
+		&speakersNamedAt, &job.AssessmentJobID, &job.CreatedAt, &completedAt, &fileDeletedAt, &transcriptionDeletedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to load transcription job: %w", err)
+	}
+
+	if err := json.Unmarshal([]byte(speakerMapping), &job.SpeakerMapping); err != nil {
+		return nil, fmt.Errorf("failed to decode speaker mapping: %w", err)
+	}
+	if err := json.Unmarshal([]byte(manualCorrections), &job.ManualCorrections); err != nil {
+		return nil, fmt.Errorf("failed to decode manual corrections: %w", err)
+	}
+	if err := json.Unmarshal([]byte(speakerBindings), &job.SpeakerBindings); err != nil {
+		return nil, fmt.Errorf("failed to decode speaker bindings: %w", err)
+	}
+	if err := json.Unmarshal([]byte(tokens), &job.Tokens); err != nil {
+		return nil, fmt.Errorf("failed to decode transcript tokens: %w", err)
+	}
+	if err := json.Unmarshal([]byte(participants), &job.Participants); err != nil {
+		return nil, fmt.Errorf("failed to decode participants: %w", err)
+	}
+	if err := json.Unmarshal([]byte(speakerNames), &job.SpeakerNames); err != nil {
+		return nil, fmt.Errorf("failed to decode speaker names: %w", err)
+	}
+	if speakersNamedAt.Valid {
+		job.SpeakersNamedAt = &speakersNamedAt.Time
+	}
+	if completedAt.Valid {
+		job.CompletedAt = &completedAt.Time
+	}
+	if fileDeletedAt.Valid {
+		job.FileDeletedAt = &fileDeletedAt.Time
+	}
+	if transcriptionDeletedAt.Valid {
+		job.TranscriptionDeletedAt = &transcriptionDeletedAt.Time
+	}
+	return &job, nil
+}
+
+func (r *SQLResultRepository) RecordRemoteDeletion(ctx context.Context, deletion RemoteDeletion) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to record remote deletion: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, rebind(r.dialect, `
+		INSERT INTO transcription_deletions (id, session_id, client_id, provider, resource, remote_id, reason, deleted_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`),
+		deletion.ID, deletion.SessionID, deletion.ClientID, deletion.Provider, deletion.Resource, deletion.RemoteID,
+		deletion.Reason, deletion.DeletedAt)
+	if err != nil {
+		return fmt.Errorf("failed to record remote deletion: %w", err)
+	}
+
+	var query string
+	switch deletion.Resource {
+	case RemoteFile:
+		query = `UPDATE transcription_jobs SET file_deleted_at = ? WHERE session_id = ? AND file_id = ?`
+	case RemoteTranscription:
+		query = `UPDATE transcription_jobs SET transcription_deleted_at = ? WHERE session_id = ? AND transcription_id = ?`
+	default:
+		return fmt.Errorf("unknown remote resource %q", deletion.Resource)
+	}
+	if _, err := tx.ExecContext(ctx, rebind(r.dialect, query), deletion.DeletedAt, deletion.SessionID, deletion.RemoteID); err != nil {
+		return fmt.Errorf("failed to mark remote data deleted: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, rebind(r.dialect, `
+		DELETE FROM transcription_pending_deletions WHERE resource = ? AND remote_id = ?`),
+		deletion.Resource, deletion.RemoteID); err != nil {
+		return fmt.Errorf("failed to clear pending remote deletion: %w", err)
+	}
+	return tx.Commit()
+}
+
+func (r *SQLResultRepository) ListRemoteDeletions(ctx context.Context, sessionID string) ([]RemoteDeletion, error) {
+	rows, err := r.db.QueryContext(ctx, rebind(r.dialect, `
+		SELECT id, session_id, client_id, provider, resource, remote_id, reason, deleted_at
+		FROM transcription_deletions WHERE session_id = ?
+		ORDER BY deleted_at`), sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list remote deletions: %w", err)
+	}
+	defer rows.Close()
+
+	deletions := []RemoteDeletion{}
+	for rows.Next() {
+		var deletion RemoteDeletion
+		if err := rows.Scan(&deletion.ID, &deletion.SessionID, &deletion.ClientID, &deletion.Provider, &deletion.Resource,
+			&deletion.RemoteID, &deletion.Reason, &deletion.DeletedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan remote deletion: %w", err)
+		}
+		deletions = append(deletions, deletion)
+	}
+	return deletions, rows.Err()
+}
+
+func (r *SQLResultRepository) SavePendingRemoteDeletions(ctx context.Context, pending []PendingRemoteDeletion) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to save pending remote deletions: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, deletion := range pending {
+		_, err := tx.ExecContext(ctx, rebind(r.dialect, `
+			INSERT INTO transcription_pending_deletions (id, session_id, resource, remote_id, reason, created_at)
+			VALUES (?, ?, ?, ?, ?, ?)
+			ON CONFLICT (resource, remote_id) DO NOTHING`),
+			deletion.ID, deletion.SessionID, deletion.Resource, deletion.RemoteID, deletion.Reason, deletion.CreatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to save pending remote deletion: %w", err)
+		}
+	}
+	return tx.Commit()
+}
+
+func (r *SQLResultRepository) ListPendingRemoteDeletions(ctx context.Context) ([]PendingRemoteDeletion, error) {
+	rows, err := r.db.QueryContext(ctx, rebind(r.dialect, `
+		SELECT id, session_id, resource, remote_id, reason, created_at
+		FROM transcription_pending_deletions
+		ORDER BY created_at`))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pending remote deletions: %w", err)
+	}
+	defer rows.Close()
+
+	pending := []PendingRemoteDeletion{}
+	for rows.Next() {
+		var deletion PendingRemoteDeletion
+		if err := rows.Scan(&deletion.ID, &deletion.SessionID, &deletion.Resource, &deletion.RemoteID, &deletion.Reason,
+			&deletion.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan pending remote deletion: %w", err)
+		}
+		pending = append(pending, deletion)
+	}
+	return pending, rows.Err()
+}
+
+// rowScanner is satisfied by *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanParticipantResult(row rowScanner) (*AssessmentResponse, error) {
+	var result AssessmentResponse
+	var resultsJSON, warningsJSON, provenanceJSON string
+	err := row.Scan(&result.SessionID, &result.ParticipantID, &result.OverallScore, &result.Summary, &resultsJSON, &warningsJSON,
+		&result.RunID, &provenanceJSON)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrResultNotFound
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to load participant result: %w", err)
+	}
+
+	if err := json.Unmarshal([]byte(resultsJSON), &result.Results); err != nil {
+		return nil, fmt.Errorf("failed to decode participant results: %w", err)
+	}
+	if err := json.Unmarshal([]byte(warningsJSON), &result.Warnings); err != nil {
+		return nil, fmt.Errorf("failed to decode participant warnings: %w", err)
+	}
+	if err := json.Unmarshal([]byte(provenanceJSON), &result.Provenance); err != nil {
+		return nil, fmt.Errorf("failed to decode participant provenance: %w", err)
+	}
+	return &result, nil
+}
+
+// rebind converts ? placeholders to $n for PostgreSQL
+func rebind(dialect SQLDialect, query string) string {
+	if dialect != DialectPostgres {
+		return query
+	}
+
+	var out strings.Builder
+	n := 0
+	for _, ch := range query {
+		if ch == '?' {
+			n++
+			out.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		out.WriteRune(ch)
+	}
+	return out.String()
+}
+
+// splitStatements splits a migration file on semicolons that end a line
+func splitStatements(contents string) []string {
+	statements := []string{}
+	for _, part := range strings.Split(contents, ";\n") {
+		lines := []string{}
+		for _, line := range strings.Split(part, "\n") {
+			if trimmed := strings.TrimSpace(line); trimmed != "" && !strings.HasPrefix(trimmed, "--") {
+				lines = append(lines, line)
+			}
+		}
+		if statement := strings.TrimSuffix(strings.TrimSpace(strings.Join(lines, "\n")), ";"); statement != "" {
+			statements = append(statements, statement)
+		}
+	}
+	return statements
+}
+
+func newRecordID() string {
+	buf := make([]byte, 16)
+	rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
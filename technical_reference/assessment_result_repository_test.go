@@ -0,0 +1,264 @@
+package assessment
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+// testResultRepositories lists the ResultRepository implementations the repository tests run against
+var testResultRepositories = []struct {
+	name string
+	open func(t *testing.T) ResultRepository
+}{
+	{name: "memory", open: func(t *testing.T) ResultRepository { return NewMemoryResultRepository() }},
+	{name: "sqlite", open: func(t *testing.T) ResultRepository { return NewSQLResultRepository(newTestSQLiteDB(t), DialectSQLite) }},
+}
+
+// assertSameJSON fails the test when got and want encode differently
+func assertSameJSON(t *testing.T, got, want any) {
+	t.Helper()
+	gotJSON, err := json.Marshal(got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantJSON, err := json.Marshal(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(gotJSON) != string(wantJSON) {
+		t.Errorf("got  %s\nwant %s", gotJSON, wantJSON)
+	}
+}
+
+func TestResultRepositoryRoundTrip(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	confident := false
+	tests := []struct {
+		name      string
+		roundTrip func(t *testing.T, ctx context.Context, repo ResultRepository)
+	}{
+		{
+			name: "transcripts",
+			roundTrip: func(t *testing.T, ctx context.Context, repo ResultRepository) {
+				records := []TranscriptRecord{
+					{SessionID: "session", ParticipantID: "p1", ParticipantName: "Minh", Role: "GT Channel Manager", Transcript: "Let's start.", ClientTimestamp: 1, ReceivedAt: now},
+					{SessionID: "session", ParticipantID: "p2", ParticipantName: "Lan", Transcript: "Ecommerce first.", ClientTimestamp: 2, ReceivedAt: now},
+				}
+				for _, record := range records {
+					if err := repo.SaveTranscript(ctx, record); err != nil {
+						t.Fatal(err)
+					}
+				}
+				got, err := repo.ListTranscripts(ctx, "session")
+				if err != nil {
+					t.Fatal(err)
+				}
+				assertSameJSON(t, got, records)
+			},
+		},
+		{
+			name: "transcript chunks",
+			roundTrip: func(t *testing.T, ctx context.Context, repo ResultRepository) {
+				chunks := []TranscriptChunk{
+					{SessionID: "session", ParticipantID: "p1", Text: "second", ClientTimestamp: 20, ReceivedAt: now},
+					{SessionID: "session", ParticipantID: "p1", Text: "first", ClientTimestamp: 10, ReceivedAt: now},
+					{SessionID: "session", ParticipantID: "p1", Text: "first, resent", ClientTimestamp: 10, ReceivedAt: now},
+				}
+				wantStored := []bool{true, true, false}
+				for i, chunk := range chunks {
+					stored, err := repo.AppendTranscriptChunk(ctx, chunk)
+					if err != nil {
+						t.Fatal(err)
+					}
+					if stored != wantStored[i] {
+						t.Errorf("chunk %d stored %v, want %v", i, stored, wantStored[i])
+					}
+				}
+				got, err := repo.ListTranscriptChunks(ctx, "session", "p1")
+				if err != nil {
+					t.Fatal(err)
+				}
+				if transcript := AssembleTranscript(got); transcript != "first\nsecond" {
+					t.Errorf("assembled %q", transcript)
+				}
+			},
+		},
+		{
+			name: "group result",
+			roundTrip: func(t *testing.T, ctx context.Context, repo ResultRepository) {
+				group := &GroupAssessmentResponse{
+					SessionID:            "session",
+					Results:              []AssessmentResult{{CriterionID: "teamwork", Score: 4, Status: "Pass"}},
+					OverallScore:         4,
+					Score:                3,
+					ScoringJustification: "covers two channels",
+					SolutionGaps:         []string{"no budget"},
+				}
+				if err := repo.SaveGroupResult(ctx, group); err != nil {
+					t.Fatal(err)
+				}
+				got, err := repo.GetGroupResult(ctx, "session")
+				if err != nil {
+					t.Fatal(err)
+				}
+				assertSameJSON(t, got, group)
+			},
+		},
+		{
+			name: "consolidated result",
+			roundTrip: func(t *testing.T, ctx context.Context, repo ResultRepository) {
+				consolidated := &ConsolidatedResult{
+					SessionID:   "session",
+					Type:        "consolidated",
+					Assessments: []ConsolidatedParticipantResult{{ParticipantID: "p1", ParticipantName: "Minh", Results: []AssessmentResult{{CriterionID: "teamwork", Score: 3}}}},
+					Timestamp:   now.UnixMilli(),
+					Partial:     true,
+					Pending:     []string{"group"},
+				}
+				if err := repo.SaveConsolidatedResult(ctx, consolidated); err != nil {
+					t.Fatal(err)
+				}
+				got, err := repo.GetConsolidatedResult(ctx, "session")
+				if err != nil {
+					t.Fatal(err)
+				}
+				assertSameJSON(t, got, consolidated)
+			},
+		},
+		{
+			name: "transcription job",
+			roundTrip: func(t *testing.T, ctx context.Context, repo ResultRepository) {
+				job := TranscriptionJob{
+					SessionID:         "session",
+					TranscriptionID:   "transcription",
+					FileID:            "file",
+					SpeakerMapping:    map[int]string{1: "Minh"},
+					ManualCorrections: map[int]string{1: "Minh"},
+					SpeakerBindings:   map[int]string{1: "p1"},
+					Status:            TranscriptionCompleted,
+					Tokens:            []TranscriptToken{{Text: "Chào", StartMs: 0, EndMs: 400, Confidence: 0.9, Speaker: "1", Language: "vi", IsAudioEvent: &confident}},
+					Participants:      []SessionParticipant{{ID: "p1", Name: "Minh"}},
+					SpeakerNames:      []SpeakerName{{Speaker: 1, Name: "Minh", ParticipantID: "p1", Source: "binding", Confidence: "high"}},
+					SpeakersNamedAt:   &now,
+					AssessmentJobID:   "job",
+					CreatedAt:         now,
+					CompletedAt:       &now,
+				}
+				if err := repo.SaveTranscriptionJob(ctx, job); err != nil {
+					t.Fatal(err)
+				}
+				got, err := repo.GetTranscriptionJob(ctx, "session")
+				if err != nil {
+					t.Fatal(err)
+				}
+				assertSameJSON(t, got, job)
+			},
+		},
+		{
+			name: "missing records",
+			roundTrip: func(t *testing.T, ctx context.Context, repo ResultRepository) {
+				if _, err := repo.GetParticipantResult(ctx, "session", "p1"); !errors.Is(err, ErrResultNotFound) {
+					t.Errorf("participant result: %v", err)
+				}
+				if _, err := repo.GetGroupResult(ctx, "session"); !errors.Is(err, ErrResultNotFound) {
+					t.Errorf("group result: %v", err)
+				}
+				if _, err := repo.GetConsolidatedResult(ctx, "session"); !errors.Is(err, ErrResultNotFound) {
+					t.Errorf("consolidated result: %v", err)
+				}
+				if _, err := repo.GetTranscriptionJob(ctx, "session"); !errors.Is(err, ErrResultNotFound) {
+					t.Errorf("transcription job: %v", err)
+				}
+			},
+		},
+	}
+
+	for _, repository := range testResultRepositories {
+		for _, tt := range tests {
+			t.Run(repository.name+"/"+tt.name, func(t *testing.T) {
+				tt.roundTrip(t, context.Background(), repository.open(t))
+			})
+		}
+	}
+}
+
+func TestResultRepositoryOfficialRun(t *testing.T) {
+	tests := []struct {
+		name        string
+		pin         int // index of the run to pin, or -1 to leave the newest official
+		unpin       bool
+		wantOverall float64
+	}{
+		{name: "newest run is official", pin: -1, wantOverall: 4},
+		{name: "pinned run stays official", pin: 0, wantOverall: 2},
+		{name: "unpinned falls back to newest", pin: 0, unpin: true, wantOverall: 4},
+	}
+
+	for _, repository := range testResultRepositories {
+		for _, tt := range tests {
+			t.Run(repository.name+"/"+tt.name, func(t *testing.T) {
+				ctx := context.Background()
+				repo := repository.open(t)
+
+				saved := []*AssessmentResponse{}
+				save := func(overall float64) {
+					result := &AssessmentResponse{SessionID: "session", ParticipantID: "p1", OverallScore: overall,
+						Results: []AssessmentResult{{CriterionID: "teamwork", Score: int(overall)}}}
+					if err := repo.SaveParticipantResult(ctx, result); err != nil {
+						t.Fatal(err)
+					}
+					if result.RunID == "" {
+						t.Fatal("saved result has no run ID")
+					}
+					saved = append(saved, result)
+				}
+
+				save(2)
+				if tt.pin >= 0 {
+					if err := repo.SetOfficialRun(ctx, "session", "p1", saved[tt.pin].RunID); err != nil {
+						t.Fatal(err)
+					}
+				}
+				// A run saved after the pin does not replace the pinned run
+				save(4)
+				if tt.unpin {
+					if err := repo.SetOfficialRun(ctx, "session", "p1", ""); err != nil {
+						t.Fatal(err)
+					}
+				}
+
+				result, err := repo.GetParticipantResult(ctx, "session", "p1")
+				if err != nil {
+					t.Fatal(err)
+				}
+				if result.OverallScore != tt.wantOverall {
+					t.Errorf("official result scored %v, want %v", result.OverallScore, tt.wantOverall)
+				}
+
+				runs, err := repo.ListAssessmentRuns(ctx, "session", "p1")
+				if err != nil {
+					t.Fatal(err)
+				}
+				official := 0
+				for i, run := range runs {
+					if run.ID != saved[i].RunID {
+						t.Errorf("run %d is %s, want %s", i, run.ID, saved[i].RunID)
+					}
+					if run.Official {
+						official++
+					}
+				}
+				if len(runs) != 2 || official != 1 {
+					t.Errorf("%d runs with %d official, want 2 runs with 1 official", len(runs), official)
+				}
+
+				if err := repo.SetOfficialRun(ctx, "session", "p1", "missing"); !errors.Is(err, ErrResultNotFound) {
+					t.Errorf("pinning an unknown run: %v, want %v", err, ErrResultNotFound)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package assessment
+
+import "time"
+
+// Criterion change kinds in a RunDiff
+const (
+	CriterionAdded     = "added"
+	CriterionRemoved   = "removed"
+	CriterionChanged   = "changed"
+	CriterionUnchanged = "unchanged"
+)
+
+// AssessmentRun is one stored assessment of a participant. Every run is kept; the
+// official run is the one GetParticipantResult returns.
+type AssessmentRun struct {
+	ID            string              `json:"id"`
+	SessionID     string              `json:"session_id"`
+	ParticipantID string              `json:"participant_id"`
+	Result        *AssessmentResponse `json:"result"`
+	CreatedAt     time.Time           `json:"created_at"`
+	Official      bool                `json:"official"`
+	// Pinned is set on the official run when a reviewer chose it; otherwise the newest
+	// run is official
+	Pinned bool `json:"pinned,omitempty"`
+}
+
+// CriterionChange compares one criterion between two runs
+type CriterionChange struct {
+	CriterionID         string `json:"criterion_id"`
+	CriterionName       string `json:"criterion_name"`
+	Change              string `json:"change"`
+	ScoreFrom           int    `json:"score_from"`
+	ScoreTo             int    `json:"score_to"`
+	ScoreDelta          int    `json:"score_delta"`
+	StatusFrom          string `json:"status_from,omitempty"`
+	StatusTo            string `json:"status_to,omitempty"`
+	EvidenceChanged     bool   `json:"evidence_changed"`
+	EvidenceFrom        string `json:"evidence_from,omitempty"`
+	EvidenceTo          string `json:"evidence_to,omitempty"`
+	ObservationsChanged bool   `json:"observations_changed"`
+}
+
+// RunDiff is the per-criterion difference between two runs of a participant
+type RunDiff struct {
+	SessionID     string  `json:"session_id"`
+	ParticipantID string  `json:"participant_id"`
+	FromRunID     string  `json:"from_run_id"`
+	ToRunID       string  `json:"to_run_id"`
+	OverallFrom   float64 `json:"overall_from"`
+	OverallTo     float64 `json:"overall_to"`
+	OverallDelta  float64 `json:"overall_delta"`
+	// ProvenanceChanges names what differs in how the runs were produced: "transcript",
+	// "prompt", "provider" or "model"
+	ProvenanceChanges []string          `json:"provenance_changes"`
+	Criteria          []CriterionChange `json:"criteria"`
+}
+
+// DiffAssessmentRuns compares two runs criterion by criterion, in the order of the
+// from run followed by criteria only the to run has
+func DiffAssessmentRuns(from, to *AssessmentRun) *RunDiff {
+	diff := &RunDiff{
+		SessionID:         to.SessionID,
+		ParticipantID:     to.ParticipantID,
+		FromRunID:         from.ID,
+		ToRunID:           to.ID,
+		OverallFrom:       from.Result.OverallScore,
+		OverallTo:         to.Result.OverallScore,
+		OverallDelta:      to.Result.OverallScore - from.Result.OverallScore,
+		ProvenanceChanges: provenanceChanges(from.Result.Provenance, to.Result.Provenance),
+		Criteria:          []CriterionChange{},
+	}
+
+	toResults := make(map[string]AssessmentResult, len(to.Result.Results))
+	for _, result := range to.Result.Results {
+		toResults[result.CriterionID] = result
+	}
+
+	seen := make(map[string]bool, len(from.Result.Results))
+	for _, before := range from.Result.Results {
+		seen[before.CriterionID] = true
+		after, ok := toResults[before.CriterionID]
+		if !ok {
+			diff.Criteria = append(diff.Criteria, CriterionChange{
+				CriterionID:   before.CriterionID,
+				CriterionName: before.CriterionName,
+				Change:        CriterionRemoved,
+				ScoreFrom:     before.Score,
+				StatusFrom:    before.Status,
+				EvidenceFrom:  before.Evidence,
+			})
+			continue
+		}
+		diff.Criteria = append(diff.Criteria, compareCriterion(before, after))
+	}
+
+	for _, after := range to.Result.Results {
+		if seen[after.CriterionID] {
+			continue
+		}
+		diff.Criteria = append(diff.Criteria, CriterionChange{
+			CriterionID:   after.CriterionID,
+			CriterionName: after.CriterionName,
+			Change:        CriterionAdded,
+			ScoreTo:       after.Score,
+			StatusTo:      after.Status,
+			EvidenceTo:    after.Evidence,
+		})
+	}
+	return diff
+}
+
+func compareCriterion(before, after AssessmentResult) CriterionChange {
+	change := CriterionChange{
+		CriterionID:         after.CriterionID,
+		CriterionName:       after.CriterionName,
+		Change:              CriterionUnchanged,
+		ScoreFrom:           before.Score,
+		ScoreTo:             after.Score,
+		ScoreDelta:          after.Score - before.Score,
+		StatusFrom:          before.Status,
+		StatusTo:            after.Status,
+		EvidenceChanged:     before.Evidence != after.Evidence,
+		ObservationsChanged: before.Observations != after.Observations,
+	}
+	if change.EvidenceChanged {
+		change.EvidenceFrom = before.Evidence
+		change.EvidenceTo = after.Evidence
+	}
+	if change.ScoreDelta != 0 || before.Status != after.Status || change.EvidenceChanged || change.ObservationsChanged {
+		change.Change = CriterionChanged
+	}
+	return change
+}
+
+func provenanceChanges(from, to *AssessmentProvenance) []string {
+	changes := []string{}
+	if from == nil || to == nil {
+		return changes
+	}
+	if from.TranscriptSHA256 != to.TranscriptSHA256 {
+		changes = append(changes, "transcript")
+	}
+	if from.PromptSHA256 != to.PromptSHA256 {
+		changes = append(changes, "prompt")
+	}
+	if from.Provider != to.Provider {
+		changes = append(changes, "provider")
+	}
+	if from.Model != to.Model {
+		changes = append(changes, "model")
+	}
+	return changes
+}
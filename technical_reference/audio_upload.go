@@ -0,0 +1,296 @@
+package assessment
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"time"
+)
+
+// AudioSniffLen is how much of an upload SniffAudio needs to identify it
+const AudioSniffLen = 8 << 10
+
+var (
+	// ErrUnsupportedAudio is returned for uploads that aren't a supported audio container
+	// and codec
+	ErrUnsupportedAudio = errors.New("unsupported audio format")
+	// ErrAudioTooLarge is returned once an upload exceeds AudioLimits.MaxBytes
+	ErrAudioTooLarge = errors.New("audio file too large")
+	// ErrAudioTooLong is returned for recordings longer than AudioLimits.MaxDuration
+	ErrAudioTooLong = errors.New("audio recording too long")
+)
+
+// AudioLimits bounds what an async transcription upload may be
+type AudioLimits struct {
+	MaxBytes    int64
+	MaxDuration time.Duration
+}
+
+// AudioLimitsFromEnv reads ASYNC_AUDIO_MAX_MB and ASYNC_AUDIO_MAX_DURATION (a Go duration
+// such as "3h"). The defaults match what Soniox accepts for one async file.
+func AudioLimitsFromEnv() AudioLimits {
+	limits := AudioLimits{
+		MaxBytes:    500 << 20,
+		MaxDuration: 300 * time.Minute,
+	}
+	if mb, err := strconv.ParseInt(os.Getenv("ASYNC_AUDIO_MAX_MB"), 10, 64); err == nil && mb > 0 {
+		limits.MaxBytes = mb << 20
+	}
+	if duration, err := time.ParseDuration(os.Getenv("ASYNC_AUDIO_MAX_DURATION")); err == nil && duration > 0 {
+		limits.MaxDuration = duration
+	}
+	return limits
+}
+
+// CheckDuration rejects a known duration over the limit; zero means unknown and passes
+func (l AudioLimits) CheckDuration(duration time.Duration) error {
+	if duration > l.MaxDuration {
+		return fmt.Errorf("%w: %s exceeds the %s limit", ErrAudioTooLong, duration.Round(time.Second), l.MaxDuration)
+	}
+	return nil
+}
+
+// Limit wraps an upload so reading past MaxBytes fails with ErrAudioTooLarge
+func (l AudioLimits) Limit(r io.Reader) io.Reader {
+	return &audioLimitReader{r: r, remaining: l.MaxBytes, max: l.MaxBytes}
+}
+
+type audioLimitReader struct {
+	r         io.Reader
+	remaining int64
+	max       int64
+}
+
+func (l *audioLimitReader) Read(p []byte) (int, error) {
+	if l.remaining < 0 {
+		return 0, fmt.Errorf("%w: over %d MB", ErrAudioTooLarge, l.max>>20)
+	}
+	// Read one byte past the limit so an upload of exactly MaxBytes still succeeds
+	if int64(len(p)) > l.remaining+1 {
+		p = p[:l.remaining+1]
+	}
+	n, err := l.r.Read(p)
+	l.remaining -= int64(n)
+	if l.remaining < 0 {
+		return n, fmt.Errorf("%w: over %d MB", ErrAudioTooLarge, l.max>>20)
+	}
+	return n, err
+}
+
+// AudioFormat is what SniffAudio recognised from the start of an upload
+type AudioFormat struct {
+	Container string `json:"container"`
+	Codec     string `json:"codec,omitempty"` // Empty when the container doesn't name it up front
+	MimeType  string `json:"mime_type"`
+	// Duration is read from the container header when it records one; zero if unknown
+	Duration time.Duration `json:"duration,omitempty"`
+}
+
+// SniffAudio identifies an upload's container and audio codec from its first
+// AudioSniffLen bytes. Containers whose header names the codec (WebM, Ogg, WAV) must
+// carry a codec Soniox can decode.
+func SniffAudio(header []byte) (AudioFormat, error) {
+	switch {
+	case bytes.HasPrefix(header, []byte{0x1A, 0x45, 0xDF, 0xA3}):
+		return sniffMatroska(header)
+	case bytes.HasPrefix(header, []byte("OggS")):
+		return sniffOgg(header)
+	case len(header) >= 12 && string(header[4:8]) == "ftyp":
+		return AudioFormat{Container: "mp4", MimeType: "audio/mp4", Duration: mp4Duration(header)}, nil
+	case len(header) >= 12 && string(header[0:4]) == "RIFF" && string(header[8:12]) == "WAVE":
+		return sniffWAV(header)
+	case bytes.HasPrefix(header, []byte("fLaC")):
+		return AudioFormat{Container: "flac", Codec: "flac", MimeType: "audio/flac", Duration: flacDuration(header)}, nil
+	case bytes.HasPrefix(header, []byte("ID3")):
+		return AudioFormat{Container: "mp3", Codec: "mp3", MimeType: "audio/mpeg"}, nil
+	case len(header) >= 2 && header[0] == 0xFF && header[1]&0xE0 == 0xE0:
+		// MPEG audio frame sync; layer bits 00 are ADTS-framed AAC
+		if header[1]&0x06 == 0 {
+			return AudioFormat{Container: "aac", Codec: "aac", MimeType: "audio/aac"}, nil
+		}
+		return AudioFormat{Container: "mp3", Codec: "mp3", MimeType: "audio/mpeg"}, nil
+	}
+	return AudioFormat{}, fmt.Errorf("%w: unrecognised container", ErrUnsupportedAudio)
+}
+
+// matroskaAudioCodecs maps Matroska codec IDs to codec names
+var matroskaAudioCodecs = []struct{ id, codec string }{
+	{"A_OPUS", "opus"},
+	{"A_VORBIS", "vorbis"},
+	{"A_AAC", "aac"},
+	{"A_MPEG/L3", "mp3"},
+	{"A_FLAC", "flac"},
+	{"A_PCM", "pcm"},
+}
+
+func sniffMatroska(header []byte) (AudioFormat, error) {
+	format := AudioFormat{Container: "webm", MimeType: "audio/webm", Duration: matroskaDuration(header)}
+	if !bytes.Contains(header, []byte("webm")) {
+		format.Container = "matroska"
+		format.MimeType = "audio/x-matroska"
+	}
+	for _, candidate := range matroskaAudioCodecs {
+		if bytes.Contains(header, []byte(candidate.id)) {
+			format.Codec = candidate.codec
+			return format, nil
+		}
+	}
+	return AudioFormat{}, fmt.Errorf("%w: %s without a supported audio track", ErrUnsupportedAudio, format.Container)
+}
+
+// matroskaDuration reads the Segment Info Duration, which MediaRecorder leaves out of
+// live recordings
+func matroskaDuration(header []byte) time.Duration {
+	// TimecodeScale (0x2AD7B1) is in nanoseconds and defaults to a millisecond
+	scale := 1e6
+	if i := bytes.Index(header, []byte{0x2A, 0xD7, 0xB1}); i >= 0 && i+4 < len(header) && header[i+3]&0xF0 == 0x80 {
+		size := int(header[i+3] & 0x0F)
+		if size > 0 && size <= 8 && i+4+size <= len(header) {
+			var value uint64
+			for _, b := range header[i+4 : i+4+size] {
+				value = value<<8 | uint64(b)
+			}
+			scale = float64(value)
+		}
+	}
+
+	// Duration (0x4489) is a 4 or 8 byte float in TimecodeScale units
+	i := bytes.Index(header, []byte{0x44, 0x89})
+	if i < 0 || i+3 >= len(header) {
+		return 0
+	}
+	var ticks float64
+	switch header[i+2] {
+	case 0x84:
+		if i+7 > len(header) {
+			return 0
+		}
+		ticks = float64(math.Float32frombits(binary.BigEndian.Uint32(header[i+3 : i+7])))
+	case 0x88:
+		if i+11 > len(header) {
+			return 0
+		}
+		ticks = math.Float64frombits(binary.BigEndian.Uint64(header[i+3 : i+11]))
+	default:
+		return 0
+	}
+	if ticks <= 0 || math.IsNaN(ticks) || math.IsInf(ticks, 0) {
+		return 0
+	}
+	return time.Duration(ticks * scale)
+}
+
+func sniffOgg(header []byte) (AudioFormat, error) {
+	format := AudioFormat{Container: "ogg", MimeType: "audio/ogg"}
+	switch {
+	case bytes.Contains(header, []byte("OpusHead")):
+		format.Codec = "opus"
+	case bytes.Contains(header, []byte("\x01vorbis")):
+		format.Codec = "vorbis"
+	case bytes.Contains(header, []byte("\x7fFLAC")):
+		format.Codec = "flac"
+	case bytes.Contains(header, []byte("Speex   ")):
+		format.Codec = "speex"
+	default:
+		return AudioFormat{}, fmt.Errorf("%w: ogg without a supported audio stream", ErrUnsupportedAudio)
+	}
+	return format, nil
+}
+
+// wavCodecs are the WAVE format tags Soniox decodes
+var wavCodecs = map[uint16]string{
+	0x0001: "pcm",
+	0x0003: "pcm_float",
+	0x0006: "alaw",
+	0x0007: "mulaw",
+	0xFFFE: "pcm", // WAVE_FORMAT_EXTENSIBLE
+}
+
+func sniffWAV(header []byte) (AudioFormat, error) {
+	format := AudioFormat{Container: "wav", MimeType: "audio/wav"}
+
+	// Walk the chunks after "RIFF<size>WAVE" for fmt and data
+	var byteRate uint32
+	for offset := 12; offset+8 <= len(header); {
+		id := string(header[offset : offset+4])
+		size := binary.LittleEndian.Uint32(header[offset+4 : offset+8])
+		body := header[offset+8:]
+		switch id {
+		case "fmt ":
+			if len(body) < 16 {
+				return AudioFormat{}, fmt.Errorf("%w: truncated wav header", ErrUnsupportedAudio)
+			}
+			codec, ok := wavCodecs[binary.LittleEndian.Uint16(body[0:2])]
+			if !ok {
+				return AudioFormat{}, fmt.Errorf("%w: wav codec 0x%04x", ErrUnsupportedAudio, binary.LittleEndian.Uint16(body[0:2]))
+			}
+			format.Codec = codec
+			byteRate = binary.LittleEndian.Uint32(body[8:12])
+		case "data":
+			if byteRate > 0 && size != 0xFFFFFFFF {
+				format.Duration = time.Duration(float64(size) / float64(byteRate) * float64(time.Second))
+			}
+			if format.Codec == "" {
+				return AudioFormat{}, fmt.Errorf("%w: wav without a format chunk", ErrUnsupportedAudio)
+			}
+			return format, nil
+		}
+		offset += 8 + int(size) + int(size&1)
+	}
+	if format.Codec == "" {
+		return AudioFormat{}, fmt.Errorf("%w: wav without a format chunk", ErrUnsupportedAudio)
+	}
+	return format, nil
+}
+
+// flacDuration reads the total sample count and rate from STREAMINFO
+func flacDuration(header []byte) time.Duration {
+	// "fLaC", a 4 byte block header, then STREAMINFO; rate and samples start at byte 10
+	const at = 8 + 10
+	if len(header) < at+8 {
+		return 0
+	}
+	bits := binary.BigEndian.Uint64(header[at : at+8])
+	sampleRate := bits >> 44
+	totalSamples := bits & (1<<36 - 1)
+	if sampleRate == 0 || totalSamples == 0 {
+		return 0
+	}
+	return time.Duration(float64(totalSamples) / float64(sampleRate) * float64(time.Second))
+}
+
+// mp4Duration reads the movie header when it comes before the media data
+func mp4Duration(header []byte) time.Duration {
+	i := bytes.Index(header, []byte("mvhd"))
+	if i < 0 || i+5 > len(header) {
+		return 0
+	}
+	body := header[i+4:]
+	var timescale uint32
+	var duration uint64
+	switch body[0] {
+	case 0:
+		if len(body) < 20 {
+			return 0
+		}
+		timescale = binary.BigEndian.Uint32(body[12:16])
+		duration = uint64(binary.BigEndian.Uint32(body[16:20]))
+	case 1:
+		if len(body) < 32 {
+			return 0
+		}
+		timescale = binary.BigEndian.Uint32(body[20:24])
+		duration = binary.BigEndian.Uint64(body[24:32])
+	default:
+		return 0
+	}
+	if timescale == 0 || duration == math.MaxUint32 || duration == math.MaxUint64 {
+		return 0
+	}
+	return time.Duration(float64(duration) / float64(timescale) * float64(time.Second))
+}
@@ -0,0 +1,141 @@
+package assessment
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testWAV returns a WAV header with the given format tag and byte rate announcing dataSize bytes of audio
+func testWAV(formatTag uint16, byteRate, dataSize uint32) []byte {
+	var b bytes.Buffer
+	b.WriteString("RIFF")
+	binary.Write(&b, binary.LittleEndian, uint32(36+dataSize))
+	b.WriteString("WAVEfmt ")
+	binary.Write(&b, binary.LittleEndian, uint32(16))
+	binary.Write(&b, binary.LittleEndian, formatTag)
+	binary.Write(&b, binary.LittleEndian, uint16(1))  // channels
+	binary.Write(&b, binary.LittleEndian, byteRate/2) // sample rate
+	binary.Write(&b, binary.LittleEndian, byteRate)   // byte rate
+	binary.Write(&b, binary.LittleEndian, uint16(2))  // block align
+	binary.Write(&b, binary.LittleEndian, uint16(16)) // bits per sample
+	b.WriteString("data")
+	binary.Write(&b, binary.LittleEndian, dataSize)
+	return b.Bytes()
+}
+
+// testFLAC returns a FLAC header whose STREAMINFO records totalSamples at sampleRate
+func testFLAC(sampleRate, totalSamples uint64) []byte {
+	header := append([]byte("fLaC"), 0x80, 0, 0, 34)
+	header = append(header, make([]byte, 10)...)
+	header = binary.BigEndian.AppendUint64(header, sampleRate<<44|15<<36|totalSamples)
+	return append(header, make([]byte, 16)...)
+}
+
+// testWebM returns a WebM header with an audio track of codecID lasting durationMs
+func testWebM(codecID string, durationMs float32) []byte {
+	header := append([]byte{0x1A, 0x45, 0xDF, 0xA3}, "\x42\x82\x84webm"...)
+	header = append(header, 0x44, 0x89, 0x84)
+	header = binary.BigEndian.AppendUint32(header, math.Float32bits(durationMs))
+	return append(header, codecID...)
+}
+
+// testMP4 returns an MP4 header whose movie header records duration in timescale units
+func testMP4(timescale, duration uint32) []byte {
+	header := append([]byte{0, 0, 0, 20}, "ftypisom\x00\x00\x02\x00isom"...)
+	header = append(header, 0, 0, 0, 108)
+	header = append(header, "moov\x00\x00\x00\x6cmvhd"...)
+	header = append(header, make([]byte, 12)...) // version, flags, creation and modification times
+	header = binary.BigEndian.AppendUint32(header, timescale)
+	return binary.BigEndian.AppendUint32(header, duration)
+}
+
+func TestSniffAudio(t *testing.T) {
+	tests := []struct {
+		name         string
+		header       []byte
+		wantFormat   AudioFormat
+		wantDuration time.Duration
+		wantErr      bool
+	}{
+		{name: "wav pcm", header: testWAV(0x0001, 32000, 64000), wantFormat: AudioFormat{Container: "wav", Codec: "pcm", MimeType: "audio/wav"}, wantDuration: 2 * time.Second},
+		{name: "wav mulaw", header: testWAV(0x0007, 8000, 8000), wantFormat: AudioFormat{Container: "wav", Codec: "mulaw", MimeType: "audio/wav"}, wantDuration: time.Second},
+		{name: "wav with mp3 inside", header: testWAV(0x0055, 16000, 16000), wantErr: true},
+		{name: "truncated wav", header: []byte("RIFF\x00\x00\x00\x00WAVEfmt \x10\x00\x00\x00\x01\x00"), wantErr: true},
+		{name: "webm opus", header: testWebM("A_OPUS", 5000), wantFormat: AudioFormat{Container: "webm", Codec: "opus", MimeType: "audio/webm"}, wantDuration: 5 * time.Second},
+		{name: "webm video only", header: testWebM("V_VP8", 5000), wantErr: true},
+		{name: "ogg opus", header: []byte("OggS\x00\x02" + strings.Repeat("\x00", 22) + "OpusHead"), wantFormat: AudioFormat{Container: "ogg", Codec: "opus", MimeType: "audio/ogg"}},
+		{name: "ogg theora", header: []byte("OggS\x00\x02" + strings.Repeat("\x00", 22) + "\x80theora"), wantErr: true},
+		{name: "mp4", header: testMP4(1000, 90000), wantFormat: AudioFormat{Container: "mp4", MimeType: "audio/mp4"}, wantDuration: 90 * time.Second},
+		{name: "flac", header: testFLAC(16000, 48000), wantFormat: AudioFormat{Container: "flac", Codec: "flac", MimeType: "audio/flac"}, wantDuration: 3 * time.Second},
+		{name: "mp3 with ID3 tag", header: []byte("ID3\x04\x00\x00"), wantFormat: AudioFormat{Container: "mp3", Codec: "mp3", MimeType: "audio/mpeg"}},
+		{name: "mp3 frame", header: []byte{0xFF, 0xFB, 0x90, 0x64}, wantFormat: AudioFormat{Container: "mp3", Codec: "mp3", MimeType: "audio/mpeg"}},
+		{name: "adts aac", header: []byte{0xFF, 0xF1, 0x50, 0x80}, wantFormat: AudioFormat{Container: "aac", Codec: "aac", MimeType: "audio/aac"}},
+		{name: "pdf", header: []byte("%PDF-1.7\n"), wantErr: true},
+		{name: "empty", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			format, err := SniffAudio(tt.header)
+			if tt.wantErr {
+				if !errors.Is(err, ErrUnsupportedAudio) {
+					t.Fatalf("SniffAudio() = %+v, %v; want %v", format, err, ErrUnsupportedAudio)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			duration := format.Duration
+			format.Duration = 0
+			if format != tt.wantFormat {
+				t.Errorf("format %+v, want %+v", format, tt.wantFormat)
+			}
+			if duration.Round(time.Millisecond) != tt.wantDuration {
+				t.Errorf("duration %s, want %s", duration, tt.wantDuration)
+			}
+		})
+	}
+}
+
+func TestAudioLimits(t *testing.T) {
+	limits := AudioLimits{MaxBytes: 1 << 20, MaxDuration: time.Hour}
+	tests := []struct {
+		name     string
+		size     int64
+		duration time.Duration
+		wantErr  error
+	}{
+		{name: "within limits", size: 1 << 10, duration: 30 * time.Minute},
+		{name: "exactly the size limit", size: 1 << 20},
+		{name: "one byte over", size: 1<<20 + 1, wantErr: ErrAudioTooLarge},
+		{name: "exactly the duration limit", duration: time.Hour},
+		{name: "too long", duration: time.Hour + time.Second, wantErr: ErrAudioTooLong},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := io.Copy(io.Discard, limits.Limit(io.LimitReader(zeros{}, tt.size)))
+			if err == nil {
+				err = limits.CheckDuration(tt.duration)
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("got %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// zeros is an endless reader of zero bytes
+type zeros struct{}
+
+func (zeros) Read(p []byte) (int, error) {
+	clear(p)
+	return len(p), nil
+}
@@ -0,0 +1,236 @@
+// Command assessment-replay checks assessment scoring against recorded LLM responses.
+//
+// Record fixtures by running the backend with ASSESSMENT_RECORD_DIR set, then:
+// (testdata/replay holds a redacted sample fixture of each kind with its golden file)
+//
+//	assessment-replay replay -fixtures DIR -golden DIR [-update]
+//	    Re-parses every recorded response offline and diffs scores against golden files.
+//	    -update rewrites the golden files from the current parser output.
+//
+//	assessment-replay candidate -fixtures DIR [-prompt FILE] [-group-prompt FILE]
+//	    Re-runs the recorded participant transcripts through a candidate prompt template,
+//	    and the recorded group transcripts through a candidate group prompt template
+//	    (calls the configured LLM provider), and reports score deltas per criterion
+//	    against the recorded responses. Fixtures of a kind without a template are skipped.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"sort"
+	"text/template"
+	"time"
+
+	assessmentService "newing.vn/competency/backend/internal/service/assessment"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	var err error
+	switch os.Args[1] {
+	case "replay":
+		err = runReplay(os.Args[2:])
+	case "candidate":
+		err = runCandidate(os.Args[2:])
+	default:
+		usage()
+		os.Exit(2)
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: assessment-replay replay -fixtures DIR -golden DIR [-update]")
+	fmt.Fprintln(os.Stderr, "       assessment-replay candidate -fixtures DIR [-prompt FILE] [-group-prompt FILE] [-timeout 5m]")
+}
+
+// runReplay re-parses recorded responses and compares them with golden snapshots
+func runReplay(args []string) error {
+	flags := flag.NewFlagSet("replay", flag.ExitOnError)
+	fixturesDir := flags.String("fixtures", "", "directory of recorded fixtures")
+	goldenDir := flags.String("golden", "", "directory of golden score files")
+	update := flags.Bool("update", false, "rewrite golden files from current output")
+	flags.Parse(args)
+
+	if *fixturesDir == "" || *goldenDir == "" {
+		return errors.New("-fixtures and -golden are required")
+	}
+
+	fixtures, err := assessmentService.LoadFixtures(*fixturesDir)
+	if err != nil {
+		return err
+	}
+
+	service := assessmentService.NewLLMAssessmentService()
+	service.SetResponseRecorder(nil)
+
+	failed := 0
+	for _, fixture := range fixtures {
+		actual, err := service.ReplayFixture(fixture)
+		if err != nil {
+			fmt.Printf("FAIL %s: %v\n", fixture.ID, err)
+			failed++
+			continue
+		}
+
+		if *update {
+			if err := assessmentService.WriteGolden(*goldenDir, fixture.ID, actual); err != nil {
+				return err
+			}
+			fmt.Printf("UPDATED %s\n", fixture.ID)
+			continue
+		}
+
+		golden, err := assessmentService.LoadGolden(*goldenDir, fixture.ID)
+		if errors.Is(err, fs.ErrNotExist) {
+			fmt.Printf("MISSING %s: no golden file (run with -update)\n", fixture.ID)
+			failed++
+			continue
+		} else if err != nil {
+			return err
+		}
+
+		deltas := assessmentService.DiffSnapshots(golden, actual)
+		if len(deltas) == 0 {
+			fmt.Printf("ok   %s\n", fixture.ID)
+			continue
+		}
+
+		failed++
+		fmt.Printf("DIFF %s\n", fixture.ID)
+		printDeltas(deltas)
+	}
+
+	fmt.Printf("\n%d fixture(s), %d failing\n", len(fixtures), failed)
+	if failed > 0 {
+		return fmt.Errorf("%d fixture(s) regressed", failed)
+	}
+	return nil
+}
+
+// runCandidate re-assesses recorded transcripts with a candidate prompt and reports deltas
+func runCandidate(args []string) error {
+	flags := flag.NewFlagSet("candidate", flag.ExitOnError)
+	fixturesDir := flags.String("fixtures", "", "directory of recorded fixtures (the transcript corpus)")
+	promptFile := flags.String("prompt", "", "candidate participant prompt template (text/template)")
+	groupPromptFile := flags.String("group-prompt", "", "candidate group prompt template (text/template)")
+	timeout := flags.Duration("timeout", 5*time.Minute, "per-transcript LLM timeout")
+	flags.Parse(args)
+
+	if *fixturesDir == "" || (*promptFile == "" && *groupPromptFile == "") {
+		return errors.New("-fixtures and at least one of -prompt and -group-prompt are required")
+	}
+
+	candidateService := assessmentService.NewLLMAssessmentService()
+	candidateService.SetResponseRecorder(nil)
+	if *promptFile != "" {
+		tmpl, err := template.ParseFiles(*promptFile)
+		if err != nil {
+			return fmt.Errorf("failed to parse candidate prompt: %w", err)
+		}
+		candidateService.SetAssessmentPromptTemplate(tmpl)
+	}
+	if *groupPromptFile != "" {
+		tmpl, err := template.ParseFiles(*groupPromptFile)
+		if err != nil {
+			return fmt.Errorf("failed to parse candidate group prompt: %w", err)
+		}
+		candidateService.SetGroupPromptTemplate(tmpl)
+	}
+
+	fixtures, err := assessmentService.LoadFixtures(*fixturesDir)
+	if err != nil {
+		return err
+	}
+
+	// Baselines are replayed from the recorded responses with a service that has no template
+	baselineService := assessmentService.NewLLMAssessmentService()
+	baselineService.SetResponseRecorder(nil)
+
+	// Aggregate deltas per criterion across the corpus
+	totals := map[string]float64{}
+	counts := map[string]int{}
+
+	for _, fixture := range fixtures {
+		switch {
+		case fixture.Kind == assessmentService.FixtureKindParticipant && fixture.Request != nil && *promptFile != "":
+		case fixture.Kind == assessmentService.FixtureKindGroup && fixture.GroupRequest != nil && *groupPromptFile != "":
+		default:
+			continue
+		}
+
+		baseline, err := baselineService.ReplayFixture(fixture)
+		if err != nil {
+			fmt.Printf("SKIP %s: baseline replay failed: %v\n", fixture.ID, err)
+			continue
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+		candidate, err := assessCandidate(ctx, candidateService, fixture)
+		cancel()
+		if err != nil {
+			fmt.Printf("FAIL %s: candidate assessment failed: %v\n", fixture.ID, err)
+			continue
+		}
+
+		deltas := assessmentService.DiffSnapshots(baseline, candidate)
+		fmt.Printf("%s: %d change(s)\n", fixture.ID, len(deltas))
+		printDeltas(deltas)
+
+		for _, delta := range deltas {
+			totals[delta.CriterionID] += delta.Delta
+			counts[delta.CriterionID]++
+		}
+	}
+
+	criteria := make([]string, 0, len(totals))
+	for criterionID := range totals {
+		criteria = append(criteria, criterionID)
+	}
+	sort.Strings(criteria)
+
+	fmt.Println("\nMean delta per criterion (changed scores only):")
+	for _, criterionID := range criteria {
+		fmt.Printf("  %-12s %+.2f over %d change(s)\n", criterionID, totals[criterionID]/float64(counts[criterionID]), counts[criterionID])
+	}
+	return nil
+}
+
+// assessCandidate re-assesses a fixture's transcript with the candidate service
+func assessCandidate(ctx context.Context, service *assessmentService.LLMAssessmentService, fixture assessmentService.AssessmentFixture) (*assessmentService.ScoreSnapshot, error) {
+	if fixture.Kind == assessmentService.FixtureKindGroup {
+		response, err := service.ProcessGroupAssessment(ctx, *fixture.GroupRequest)
+		if err != nil {
+			return nil, err
+		}
+		return assessmentService.SnapshotGroupResponse(response), nil
+	}
+
+	response, err := service.ProcessAssessment(ctx, *fixture.Request)
+	if err != nil {
+		return nil, err
+	}
+	return service.SnapshotParticipantResponse(response), nil
+}
+
+func printDeltas(deltas []assessmentService.ScoreDelta) {
+	for _, delta := range deltas {
+		if delta.Missing != "" {
+			fmt.Printf("    %s / %s: missing in %s\n", delta.Subject, delta.CriterionID, delta.Missing)
+			continue
+		}
+		fmt.Printf("    %s / %s: %.2f -> %.2f (%+.2f)\n", delta.Subject, delta.CriterionID, delta.Golden, delta.Actual, delta.Delta)
+	}
+}
@@ -0,0 +1,67 @@
+// Command webhook-receiver is a local endpoint for testing assessment webhooks.
+//
+//	webhook-receiver -addr :8089 -secret whsec_...
+//
+// Register http://localhost:8089/ as a webhook URL with the secret returned on
+// creation; the assessment server only accepts it with WEBHOOK_ALLOW_PRIVATE_URLS=true. Every delivery's signature is verified and the event is printed; bad
+// signatures are answered with 401 so they show up as failures in the delivery log.
+// The secret may also be given in WEBHOOK_SECRET.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	assessmentService "newing.vn/competency/backend/internal/service/assessment"
+)
+
+func main() {
+	addr := flag.String("addr", ":8089", "listen address")
+	secret := flag.String("secret", os.Getenv("WEBHOOK_SECRET"), "webhook signing secret")
+	tolerance := flag.Duration("tolerance", 5*time.Minute, "maximum age of a delivery timestamp")
+	flag.Parse()
+
+	if *secret == "" {
+		fmt.Fprintln(os.Stderr, "usage: webhook-receiver -secret SECRET [-addr :8089] [-tolerance 5m]")
+		os.Exit(2)
+	}
+
+	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		body, err := io.ReadAll(io.LimitReader(r.Body, 10<<20))
+		if err != nil {
+			http.Error(w, "failed to read body", http.StatusBadRequest)
+			return
+		}
+
+		err = assessmentService.VerifyWebhookSignature(*secret,
+			r.Header.Get(assessmentService.WebhookTimestampHeader),
+			r.Header.Get(assessmentService.WebhookSignatureHeader),
+			body, *tolerance)
+		if err != nil {
+			log.Printf("rejected %s: %v", r.Header.Get(assessmentService.WebhookIDHeader), err)
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+
+		var pretty bytes.Buffer
+		if err := json.Indent(&pretty, body, "", "  "); err != nil {
+			pretty.Write(body)
+		}
+		log.Printf("verified %s %s\n%s", r.Header.Get(assessmentService.WebhookEventHeader), r.Header.Get(assessmentService.WebhookIDHeader), pretty.String())
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	log.Printf("listening on %s", *addr)
+	log.Fatal(http.ListenAndServe(*addr, nil))
+}
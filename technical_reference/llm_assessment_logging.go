@@ -0,0 +1,135 @@
+package assessment
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"unicode/utf8"
+)
+
+// LoggingConfig controls the assessment pipeline logger.
+// Transcripts, names, prompts and raw LLM output are redacted unless PayloadDebug is set,
+// in which case the full payloads go to PayloadPath only (never to stdout).
+type LoggingConfig struct {
+	Level        slog.Level
+	JSON         bool
+	PayloadDebug bool
+	PayloadPath  string
+}
+
+// LoggingConfigFromEnv reads ASSESSMENT_LOG_LEVEL, ASSESSMENT_LOG_FORMAT,
+// ASSESSMENT_LOG_PAYLOADS and ASSESSMENT_PAYLOAD_LOG_PATH
+func LoggingConfigFromEnv() LoggingConfig {
+	config := LoggingConfig{
+		Level:       slog.LevelInfo,
+		JSON:        os.Getenv("ASSESSMENT_LOG_FORMAT") != "text",
+		PayloadPath: os.Getenv("ASSESSMENT_PAYLOAD_LOG_PATH"),
+	}
+
+	switch strings.ToLower(os.Getenv("ASSESSMENT_LOG_LEVEL")) {
+	case "debug":
+		config.Level = slog.LevelDebug
+	case "warn":
+		config.Level = slog.LevelWarn
+	case "error":
+		config.Level = slog.LevelError
+	}
+
+	config.PayloadDebug = os.Getenv("ASSESSMENT_LOG_PAYLOADS") == "true" && config.PayloadPath != ""
+	return config
+}
+
+// AssessmentLogger wraps the structured logger with a separate sink for full payloads
+type AssessmentLogger struct {
+	*slog.Logger
+	payloads *slog.Logger // nil unless payload debug mode is enabled
+}
+
+// NewAssessmentLogger creates a logger writing redacted records to w.
+// If payload debug mode is enabled the payload file is created with owner-only permissions.
+func NewAssessmentLogger(w io.Writer, config LoggingConfig) *AssessmentLogger {
+	options := &slog.HandlerOptions{Level: config.Level}
+
+	var handler slog.Handler
+	if config.JSON {
+		handler = slog.NewJSONHandler(w, options)
+	} else {
+		handler = slog.NewTextHandler(w, options)
+	}
+
+	logger := &AssessmentLogger{Logger: slog.New(handler)}
+
+	if config.PayloadDebug {
+		file, err := os.OpenFile(config.PayloadPath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+		if err != nil {
+			logger.Error("failed to open payload log, payload debug disabled", slog.String("error", err.Error()))
+			return logger
+		}
+		logger.payloads = slog.New(slog.NewJSONHandler(file, &slog.HandlerOptions{Level: slog.LevelDebug}))
+		logger.Warn("payload debug logging enabled, full transcripts are written to the payload log")
+	}
+
+	return logger
+}
+
+// With returns a logger carrying extra correlation attributes
+func (l *AssessmentLogger) With(args ...any) *AssessmentLogger {
+	child := &AssessmentLogger{Logger: l.Logger.With(args...)}
+	if l.payloads != nil {
+		child.payloads = l.payloads.With(args...)
+	}
+	return child
+}
+
+// ForParticipant returns a logger correlated to a session and participant
+func (l *AssessmentLogger) ForParticipant(sessionID, participantID string) *AssessmentLogger {
+	return l.With(slog.String("session_id", sessionID), slog.String("participant_id", participantID))
+}
+
+// ForSession returns a logger correlated to a session
+func (l *AssessmentLogger) ForSession(sessionID string) *AssessmentLogger {
+	return l.With(slog.String("session_id", sessionID))
+}
+
+// Payload records the full content of a transcript, prompt or LLM response to the
+// protected payload sink. It is a no-op unless payload debug mode is enabled.
+func (l *AssessmentLogger) Payload(ctx context.Context, kind, content string) {
+	if l.payloads == nil {
+		return
+	}
+	l.payloads.DebugContext(ctx, "payload", slog.String("kind", kind), slog.String("content", content))
+}
+
+// RedactedText describes sensitive text by size and fingerprint instead of content,
+// so log lines can still be correlated without exposing what was said
+func RedactedText(key, text string) slog.Attr {
+	if text == "" {
+		return slog.Group(key, slog.Int("chars", 0))
+	}
+
+	sum := sha256.Sum256([]byte(text))
+	return slog.Group(key,
+		slog.Int("chars", utf8.RuneCountInString(text)),
+		slog.Int("words", len(strings.Fields(text))),
+		slog.String("sha256", hex.EncodeToString(sum[:])[:12]),
+	)
+}
+
+// RedactedName reduces a person's name to its initials
+func RedactedName(key, name string) slog.Attr {
+	initials := make([]string, 0, 4)
+	for _, part := range strings.Fields(name) {
+		r, _ := utf8.DecodeRuneInString(part)
+		initials = append(initials, string(r)+".")
+	}
+	return slog.String(key, strings.Join(initials, ""))
+}
+
+// Logger returns the structured logger used by the service
+func (s *LLMAssessmentService) Logger() *AssessmentLogger {
+	return s.logger
+}
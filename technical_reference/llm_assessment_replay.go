@@ -0,0 +1,369 @@
+package assessment
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+)
+
+// Fixture kinds
+const (
+	FixtureKindParticipant = "participant"
+	FixtureKindGroup       = "group"
+)
+
+// groupSubject is the snapshot subject used for group assessment scores
+const groupSubject = "group"
+
+// solutionCriterion is the snapshot criterion used for the group solution score
+const solutionCriterion = "solution"
+
+// AssessmentFixture is a recorded provider response together with the request that produced it.
+// Fixtures contain transcripts and must be stored with the same care as production data.
+type AssessmentFixture struct {
+	ID           string                  `json:"id"`
+	Kind         string                  `json:"kind"`
+	RecordedAt   time.Time               `json:"recorded_at"`
+	PromptSHA256 string                  `json:"prompt_sha256"`
+	Request      *AssessmentRequest      `json:"request,omitempty"`
+	GroupRequest *GroupAssessmentRequest `json:"group_request,omitempty"`
+	LLMResponse  string                  `json:"llm_response"`
+}
+
+// ScoreSnapshot is the comparable outcome of an assessment: scores per subject and criterion.
+// Subjects are participant IDs, or "group" for the group assessment.
+type ScoreSnapshot struct {
+	Scores  map[string]map[string]int `json:"scores"`
+	Overall map[string]float64        `json:"overall"`
+}
+
+// ScoreDelta is a single difference between a golden snapshot and a new one
+type ScoreDelta struct {
+	Subject     string  `json:"subject"`
+	CriterionID string  `json:"criterion_id"`
+	Golden      float64 `json:"golden"`
+	Actual      float64 `json:"actual"`
+	Delta       float64 `json:"delta"`
+	Missing     string  `json:"missing,omitempty"` // "golden" or "actual" when one side has no score
+}
+
+// ResponseRecorder receives every raw provider response produced by the service
+type ResponseRecorder interface {
+	Record(fixture AssessmentFixture) error
+}
+
+// FileRecorder writes fixtures as JSON files into a directory
+type FileRecorder struct {
+	dir string
+	mu  sync.Mutex
+}
+
+// NewFileRecorder creates a recorder writing into dir, creating it with owner-only permissions
+func NewFileRecorder(dir string) (*FileRecorder, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create fixture directory: %w", err)
+	}
+	return &FileRecorder{dir: dir}, nil
+}
+
+// Record writes the fixture to <dir>/<id>.json
+func (r *FileRecorder) Record(fixture AssessmentFixture) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	data, err := json.MarshalIndent(fixture, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(r.dir, fixture.ID+".json"), data, 0o600)
+}
+
+// SetResponseRecorder enables recording of provider responses (nil disables it)
+func (s *LLMAssessmentService) SetResponseRecorder(recorder ResponseRecorder) {
+	s.recorder = recorder
+}
+
+// SetAssessmentPromptTemplate replaces the built-in participant prompt with a candidate template.
+// The template receives the AssessmentRequest plus a rendered CriteriaSection string.
+func (s *LLMAssessmentService) SetAssessmentPromptTemplate(tmpl *template.Template) {
+	s.promptTemplate = tmpl
+}
+
+// SetGroupPromptTemplate replaces the built-in group prompt with a candidate template.
+// The template receives the GroupAssessmentRequest plus a rendered CriteriaSection string.
+func (s *LLMAssessmentService) SetGroupPromptTemplate(tmpl *template.Template) {
+	s.groupPromptTemplate = tmpl
+}
+
+// recordParticipantResponse stores a participant fixture if recording is enabled
+func (s *LLMAssessmentService) recordParticipantResponse(req AssessmentRequest, prompt, llmResponse string) {
+	if s.recorder == nil {
+		return
+	}
+
+	fixture := AssessmentFixture{
+		ID:           fixtureID(FixtureKindParticipant, req.SessionID, req.ParticipantID),
+		Kind:         FixtureKindParticipant,
+		RecordedAt:   time.Now().UTC(),
+		PromptSHA256: promptHash(prompt),
+		Request:      &req,
+		LLMResponse:  llmResponse,
+	}
+	if err := s.recorder.Record(fixture); err != nil {
+		s.logger.ForParticipant(req.SessionID, req.ParticipantID).Warn("failed to record assessment fixture", slog.String("error", err.Error()))
+	}
+}
+
+// recordGroupResponse stores a group fixture if recording is enabled
+func (s *LLMAssessmentService) recordGroupResponse(req GroupAssessmentRequest, prompt, llmResponse string) {
+	if s.recorder == nil {
+		return
+	}
+
+	fixture := AssessmentFixture{
+		ID:           fixtureID(FixtureKindGroup, req.SessionID, groupSubject),
+		Kind:         FixtureKindGroup,
+		RecordedAt:   time.Now().UTC(),
+		PromptSHA256: promptHash(prompt),
+		GroupRequest: &req,
+		LLMResponse:  llmResponse,
+	}
+	if err := s.recorder.Record(fixture); err != nil {
+		s.logger.ForSession(req.SessionID).Warn("failed to record group assessment fixture", slog.String("error", err.Error()))
+	}
+}
+
+// renderAssessmentPromptTemplate renders the candidate prompt template for a request
+func (s *LLMAssessmentService) renderAssessmentPromptTemplate(req AssessmentRequest) (string, error) {
+	var criteria strings.Builder
+	writeCriteriaSection(&criteria, req.Criteria)
+
+	var prompt strings.Builder
+	data := struct {
+		AssessmentRequest
+		CriteriaSection string
+	}{req, criteria.String()}
+	if err := s.promptTemplate.Execute(&prompt, data); err != nil {
+		return "", fmt.Errorf("failed to render candidate prompt: %w", err)
+	}
+	return prompt.String(), nil
+}
+
+// renderGroupPromptTemplate renders the candidate group prompt template for a request
+func (s *LLMAssessmentService) renderGroupPromptTemplate(req GroupAssessmentRequest) (string, error) {
+	var criteria strings.Builder
+	writeCriteriaSection(&criteria, req.Criteria)
+
+	var prompt strings.Builder
+	data := struct {
+		GroupAssessmentRequest
+		CriteriaSection string
+	}{req, criteria.String()}
+	if err := s.groupPromptTemplate.Execute(&prompt, data); err != nil {
+		return "", fmt.Errorf("failed to render candidate group prompt: %w", err)
+	}
+	return prompt.String(), nil
+}
+
+// ReplayFixture runs a recorded provider response through the parsers without any network access
+func (s *LLMAssessmentService) ReplayFixture(fixture AssessmentFixture) (*ScoreSnapshot, error) {
+	switch fixture.Kind {
+	case FixtureKindParticipant:
+		if fixture.Request == nil {
+			return nil, fmt.Errorf("fixture %s has no request", fixture.ID)
+		}
+		response, err := s.parseAssessmentResponse(fixture.LLMResponse, *fixture.Request)
+		if err != nil {
+			return nil, fmt.Errorf("fixture %s: %w", fixture.ID, err)
+		}
+		return s.SnapshotParticipantResponse(response), nil
+
+	case FixtureKindGroup:
+		if fixture.GroupRequest == nil {
+			return nil, fmt.Errorf("fixture %s has no group request", fixture.ID)
+		}
+		response, err := s.parseGroupAssessmentResponse(fixture.LLMResponse, *fixture.GroupRequest)
+		if err != nil {
+			return nil, fmt.Errorf("fixture %s: %w", fixture.ID, err)
+		}
+		return SnapshotGroupResponse(response), nil
+	}
+
+	return nil, fmt.Errorf("fixture %s has unknown kind %q", fixture.ID, fixture.Kind)
+}
+
+// SnapshotParticipantResponse builds a snapshot, expanding unified marker responses into their participant assessments
+func (s *LLMAssessmentService) SnapshotParticipantResponse(response *AssessmentResponse) *ScoreSnapshot {
+	if response.ParticipantID == "unified_multiple" {
+		return SnapshotResponses(response.Participants...)
+	}
+	return SnapshotResponses(response)
+}
+
+// SnapshotResponses builds a snapshot from participant assessment responses
+func SnapshotResponses(responses ...*AssessmentResponse) *ScoreSnapshot {
+	snapshot := newScoreSnapshot()
+	for _, response := range responses {
+		scores := make(map[string]int, len(response.Results))
+		for _, result := range response.Results {
+			scores[result.CriterionID] = result.Score
+		}
+		snapshot.Scores[response.ParticipantID] = scores
+		snapshot.Overall[response.ParticipantID] = response.OverallScore
+	}
+	return snapshot
+}
+
+// SnapshotGroupResponse builds a snapshot from a group assessment response
+func SnapshotGroupResponse(response *GroupAssessmentResponse) *ScoreSnapshot {
+	snapshot := newScoreSnapshot()
+	scores := map[string]int{solutionCriterion: response.Score}
+	for _, result := range response.Results {
+		scores[result.CriterionID] = result.Score
+	}
+	snapshot.Scores[groupSubject] = scores
+	snapshot.Overall[groupSubject] = response.OverallScore
+	return snapshot
+}
+
+// DiffSnapshots lists every score that differs between golden and actual, sorted by subject and criterion
+func DiffSnapshots(golden, actual *ScoreSnapshot) []ScoreDelta {
+	deltas := []ScoreDelta{}
+
+	subjects := map[string]bool{}
+	for subject := range golden.Scores {
+		subjects[subject] = true
+	}
+	for subject := range actual.Scores {
+		subjects[subject] = true
+	}
+
+	for subject := range subjects {
+		criteria := map[string]bool{}
+		for criterionID := range golden.Scores[subject] {
+			criteria[criterionID] = true
+		}
+		for criterionID := range actual.Scores[subject] {
+			criteria[criterionID] = true
+		}
+
+		for criterionID := range criteria {
+			goldenScore, inGolden := golden.Scores[subject][criterionID]
+			actualScore, inActual := actual.Scores[subject][criterionID]
+			if inGolden && inActual && goldenScore == actualScore {
+				continue
+			}
+
+			delta := ScoreDelta{
+				Subject:     subject,
+				CriterionID: criterionID,
+				Golden:      float64(goldenScore),
+				Actual:      float64(actualScore),
+				Delta:       float64(actualScore - goldenScore),
+			}
+			if !inGolden {
+				delta.Missing = "golden"
+			} else if !inActual {
+				delta.Missing = "actual"
+			}
+			deltas = append(deltas, delta)
+		}
+
+		if goldenOverall, actualOverall := golden.Overall[subject], actual.Overall[subject]; math.Abs(goldenOverall-actualOverall) > 0.005 {
+			deltas = append(deltas, ScoreDelta{
+				Subject:     subject,
+				CriterionID: "overall",
+				Golden:      goldenOverall,
+				Actual:      actualOverall,
+				Delta:       actualOverall - goldenOverall,
+			})
+		}
+	}
+
+	sort.Slice(deltas, func(i, j int) bool {
+		if deltas[i].Subject != deltas[j].Subject {
+			return deltas[i].Subject < deltas[j].Subject
+		}
+		return deltas[i].CriterionID < deltas[j].CriterionID
+	})
+	return deltas
+}
+
+// LoadFixtures reads every *.json fixture in dir, sorted by ID
+func LoadFixtures(dir string) ([]AssessmentFixture, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	fixtures := make([]AssessmentFixture, 0, len(paths))
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		var fixture AssessmentFixture
+		if err := json.Unmarshal(data, &fixture); err != nil {
+			return nil, fmt.Errorf("failed to parse fixture %s: %w", path, err)
+		}
+		fixtures = append(fixtures, fixture)
+	}
+
+	sort.Slice(fixtures, func(i, j int) bool { return fixtures[i].ID < fixtures[j].ID })
+	return fixtures, nil
+}
+
+// LoadGolden reads the golden snapshot for a fixture from dir
+func LoadGolden(dir, fixtureID string) (*ScoreSnapshot, error) {
+	data, err := os.ReadFile(filepath.Join(dir, fixtureID+".golden.json"))
+	if err != nil {
+		return nil, err
+	}
+	var snapshot ScoreSnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, fmt.Errorf("failed to parse golden file for %s: %w", fixtureID, err)
+	}
+	return &snapshot, nil
+}
+
+// WriteGolden stores a snapshot as the golden file for a fixture
+func WriteGolden(dir, fixtureID string, snapshot *ScoreSnapshot) error {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, fixtureID+".golden.json"), data, 0o600)
+}
+
+func newScoreSnapshot() *ScoreSnapshot {
+	return &ScoreSnapshot{
+		Scores:  make(map[string]map[string]int),
+		Overall: make(map[string]float64),
+	}
+}
+
+var unsafeFixtureChars = regexp.MustCompile(`[^A-Za-z0-9_-]+`)
+
+func fixtureID(kind, sessionID, subject string) string {
+	id := fmt.Sprintf("%s_%s_%s_%d", kind, sessionID, subject, time.Now().UnixNano())
+	return unsafeFixtureChars.ReplaceAllString(id, "-")
+}
+
+func promptHash(prompt string) string {
+	sum := sha256.Sum256([]byte(prompt))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,171 @@
+package assessment
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var (
+	replayFixturesDir = filepath.Join("testdata", "replay", "fixtures")
+	replayGoldenDir   = filepath.Join("testdata", "replay", "golden")
+)
+
+func TestReplayFixturesMatchGolden(t *testing.T) {
+	fixtures, err := LoadFixtures(replayFixturesDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fixtures) == 0 {
+		t.Fatal("no replay fixtures")
+	}
+
+	s := newTestLLMService()
+	for _, fixture := range fixtures {
+		t.Run(fixture.ID, func(t *testing.T) {
+			actual, err := s.ReplayFixture(fixture)
+			if err != nil {
+				t.Fatal(err)
+			}
+			golden, err := LoadGolden(replayGoldenDir, fixture.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if deltas := DiffSnapshots(golden, actual); len(deltas) > 0 {
+				t.Fatalf("scores differ from the golden file: %+v", deltas)
+			}
+		})
+	}
+}
+
+func TestReplayReportsChangedScores(t *testing.T) {
+	fixtures, err := LoadFixtures(replayFixturesDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		kind    string
+		old     string // text in the recorded response replaced by new
+		new     string
+		subject string
+		want    []ScoreDelta
+	}{
+		{
+			name:    "participant criterion",
+			kind:    FixtureKindParticipant,
+			old:     `"score": 4`,
+			new:     `"score": 2`,
+			subject: "participant-a",
+			want: []ScoreDelta{
+				{Subject: "participant-a", CriterionID: "1", Golden: 4, Actual: 2, Delta: -2},
+				{Subject: "participant-a", CriterionID: "overall", Golden: 3.5, Actual: 2.5, Delta: -1},
+			},
+		},
+		{
+			name:    "group solution",
+			kind:    FixtureKindGroup,
+			old:     "\"score\": 3,\n    \"scoringJustification\"",
+			new:     "\"score\": 5,\n    \"scoringJustification\"",
+			subject: groupSubject,
+			want: []ScoreDelta{
+				{Subject: groupSubject, CriterionID: solutionCriterion, Golden: 3, Actual: 5, Delta: 2},
+			},
+		},
+	}
+
+	s := newTestLLMService()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var fixture *AssessmentFixture
+			for i := range fixtures {
+				if fixtures[i].Kind == tt.kind {
+					fixture = &fixtures[i]
+				}
+			}
+			if fixture == nil {
+				t.Fatalf("no %s fixture", tt.kind)
+			}
+			golden, err := LoadGolden(replayGoldenDir, fixture.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			changed := *fixture
+			if !strings.Contains(changed.LLMResponse, tt.old) {
+				t.Fatalf("fixture response has no %q", tt.old)
+			}
+			changed.LLMResponse = strings.Replace(changed.LLMResponse, tt.old, tt.new, 1)
+			actual, err := s.ReplayFixture(changed)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			deltas := DiffSnapshots(golden, actual)
+			if len(deltas) != len(tt.want) {
+				t.Fatalf("deltas %+v, want %+v", deltas, tt.want)
+			}
+			for i := range deltas {
+				if deltas[i] != tt.want[i] {
+					t.Errorf("delta %d: %+v, want %+v", i, deltas[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestDiffSnapshots(t *testing.T) {
+	golden := &ScoreSnapshot{
+		Scores:  map[string]map[string]int{"p1": {"1": 4, "2": 3}},
+		Overall: map[string]float64{"p1": 3.5},
+	}
+
+	tests := []struct {
+		name   string
+		actual *ScoreSnapshot
+		want   []ScoreDelta
+	}{
+		{
+			name: "unchanged",
+			actual: &ScoreSnapshot{
+				Scores:  map[string]map[string]int{"p1": {"1": 4, "2": 3}},
+				Overall: map[string]float64{"p1": 3.501},
+			},
+			want: []ScoreDelta{},
+		},
+		{
+			name: "missing criterion",
+			actual: &ScoreSnapshot{
+				Scores:  map[string]map[string]int{"p1": {"1": 4}},
+				Overall: map[string]float64{"p1": 3.5},
+			},
+			want: []ScoreDelta{{Subject: "p1", CriterionID: "2", Golden: 3, Delta: -3, Missing: "actual"}},
+		},
+		{
+			name: "new participant",
+			actual: &ScoreSnapshot{
+				Scores:  map[string]map[string]int{"p1": {"1": 4, "2": 3}, "p2": {"1": 2}},
+				Overall: map[string]float64{"p1": 3.5, "p2": 2},
+			},
+			want: []ScoreDelta{
+				{Subject: "p2", CriterionID: "1", Actual: 2, Delta: 2, Missing: "golden"},
+				{Subject: "p2", CriterionID: "overall", Actual: 2, Delta: 2},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deltas := DiffSnapshots(golden, tt.actual)
+			if len(deltas) != len(tt.want) {
+				t.Fatalf("deltas %+v, want %+v", deltas, tt.want)
+			}
+			for i := range deltas {
+				if deltas[i] != tt.want[i] {
+					t.Errorf("delta %d: %+v, want %+v", i, deltas[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
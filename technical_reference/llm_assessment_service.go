@@ -4,11 +4,18 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"log/slog"
+	"math"
 	"net/http"
 	"os"
+	"strconv"
 	"strings"
+	"text/template"
+	"time"
+	"unicode/utf8"
 )
 
 // LLMAssessmentService handles AI-powered assessment processing
@@ -16,14 +23,35 @@ type LLMAssessmentService struct {
 	geminiAPIKey string
 	anthropicAPIKey string
 	lastUnifiedAssessments []*AssessmentResponse // Temporary storage for unified assessments
+	validationPolicy ValidationPolicy
+	logger *AssessmentLogger
+	recorder ResponseRecorder // Optional fixture recorder for replay testing
+	promptTemplate *template.Template // Optional candidate prompt replacing buildAssessmentPrompt
+	groupPromptTemplate *template.Template // Optional candidate prompt replacing buildGroupAssessmentPrompt
+	limiter *RateLimiter // Provider request/token budgets shared by concurrent assessments
 }
 
 // NewLLMAssessmentService creates a new LLM assessment service
 func NewLLMAssessmentService() *LLMAssessmentService {
-	return &LLMAssessmentService{
+	service := &LLMAssessmentService{
 		geminiAPIKey: os.Getenv("GEMINI_API_KEY"),
 		anthropicAPIKey: os.Getenv("ANTHROPIC_API_KEY"),
+		validationPolicy: DefaultValidationPolicy(),
+		logger: NewAssessmentLogger(os.Stdout, LoggingConfigFromEnv()),
+		limiter: NewRateLimiter(RateLimitsFromEnv()),
 	}
+
+	// Record provider responses as replay fixtures when ASSESSMENT_RECORD_DIR is set
+	if dir := os.Getenv("ASSESSMENT_RECORD_DIR"); dir != "" {
+		recorder, err := NewFileRecorder(dir)
+		if err != nil {
+			service.logger.Error("failed to enable fixture recording", slog.String("error", err.Error()))
+		} else {
+			service.recorder = recorder
+		}
+	}
+
+	return service
 }
 
 // AssessmentCriteria represents an assessment criterion
@@ -57,6 +85,9 @@ type AssessmentRequest struct {
 	Criteria      []AssessmentCriteria `json:"criteria"`
 	Language      string               `json:"language"` // "vietnamese" or "english"
 	Context       string               `json:"context,omitempty"` // Optional: full conversation context for group assessments
+	// ExpectedParticipants are the participant IDs a unified assessment must cover; when
+	// set, participants missing from the response or not among them are warned about
+	ExpectedParticipants []string `json:"expected_participants,omitempty"`
 }
 
 // AssessmentResponse represents the complete assessment response
@@ -66,6 +97,27 @@ type AssessmentResponse struct {
 	Results       []AssessmentResult `json:"results"`
 	OverallScore  float64            `json:"overall_score"`
 	Summary       string             `json:"summary"`
+	Warnings      []ValidationWarning `json:"warnings,omitempty"`
+	// Participants holds each speaker's assessment when ParticipantID is "unified_multiple"
+	Participants  []*AssessmentResponse `json:"participants,omitempty"`
+	// Provenance records what produced the assessment; RunID is set once it is stored
+	Provenance    *AssessmentProvenance `json:"provenance,omitempty"`
+	RunID         string                `json:"run_id,omitempty"`
+}
+
+// AssessmentProvenance records what produced an assessment, so runs can be compared.
+// PromptSHA256 hashes the prompt with the transcript left out, so it only changes when
+// the prompt or criteria change.
+type AssessmentProvenance struct {
+	Provider         string    `json:"provider"`
+	Model            string    `json:"model,omitempty"`
+	PromptSHA256     string    `json:"prompt_sha256"`
+	CandidatePrompt  bool      `json:"candidate_prompt,omitempty"`
+	TranscriptSHA256 string    `json:"transcript_sha256"`
+	TranscriptChars  int       `json:"transcript_chars"`
+	Attempts         int       `json:"attempts"`
+	JobID            string    `json:"job_id,omitempty"`
+	AssessedAt       time.Time `json:"assessed_at"`
 }
 
 // UnifiedAssessmentResponse represents multiple participant assessments from a unified transcript
@@ -119,94 +171,134 @@ type GeminiResponse struct {
 	} `json:"candidates"`
 }
 
-// GetLastUnifiedAssessments retrieves the last unified assessment results
+// GetLastUnifiedAssessments retrieves the last unified assessment results.
+//
+// Deprecated: the value is shared by concurrent assessments; use AssessmentResponse.Participants.
 func (s *LLMAssessmentService) GetLastUnifiedAssessments() []*AssessmentResponse {
 	return s.lastUnifiedAssessments
 }
 
 // ProcessAssessment processes a transcript using LLM and returns assessment results
 func (s *LLMAssessmentService) ProcessAssessment(ctx context.Context, req AssessmentRequest) (*AssessmentResponse, error) {
-	// Build the prompt for assessment
+	// Build the prompt for assessment, preferring a candidate template when one is set
 	prompt := s.buildAssessmentPrompt(req)
-
-	// Log the received transcript
-	fmt.Printf("\n=== TRANSCRIPT RECEIVED BY LLM SERVICE ===\n")
-	fmt.Printf("Participant ID: %s\n", req.ParticipantID)
-	fmt.Printf("Session ID: %s\n", req.SessionID)
-	fmt.Printf("Language: %s\n", req.Language)
-	fmt.Printf("Transcript length: %d characters\n", len(req.Transcript))
-	fmt.Printf("Has context: %v\n", req.Context != "")
-	if req.Context != "" {
-		fmt.Printf("Context length: %d characters\n", len(req.Context))
-	}
-
-	// Log first 500 chars of transcript
-	if len(req.Transcript) > 0 {
-		preview := req.Transcript
-		if len(preview) > 500 {
-			preview = preview[:500] + "...[truncated]"
+	if s.promptTemplate != nil {
+		var err error
+		if prompt, err = s.renderAssessmentPromptTemplate(req); err != nil {
+			return nil, err
 		}
-		fmt.Printf("Transcript preview:\n%s\n", preview)
-	} else {
-		fmt.Printf("Transcript is empty!\n")
 	}
 
-	// Log prompt for debugging
-	fmt.Printf("\n=== LLM PROMPT (Participant: %s) ===\n", req.ParticipantID)
-	fmt.Printf("Prompt length: %d characters\n", len(prompt))
-	if len(prompt) < 5000 {
-		fmt.Printf("Full prompt:\n%s\n", prompt)
-	} else {
-		fmt.Printf("Prompt preview (first 2000 chars):\n%s\n...[truncated]\n", prompt[:2000])
-	}
+	logger := s.logger.ForParticipant(req.SessionID, req.ParticipantID)
+
+	// Log the received transcript without its content
+	logger.InfoContext(ctx, "assessment transcript received",
+		slog.String("language", req.Language),
+		RedactedText("transcript", req.Transcript),
+		RedactedText("context", req.Context),
+		slog.Int("prompt_chars", len(prompt)),
+	)
+	if strings.TrimSpace(req.Transcript) == "" {
+		logger.WarnContext(ctx, "assessment transcript is empty")
+	}
+	logger.Payload(ctx, "transcript", req.Transcript)
+	logger.Payload(ctx, "prompt", prompt)
+
+	// Call the LLM, retrying while the validation policy rejects the response
+	for attempt := 1; ; attempt++ {
+		// Determine which API to use and call it
+		var llmResponse string
+		var err error
+		var provider, model string
+
+		if s.anthropicAPIKey != "" {
+			logger.DebugContext(ctx, "calling LLM", slog.String("provider", "claude"), slog.Int("attempt", attempt))
+			provider, model = ProviderClaude, claudeModel
+			llmResponse, err = s.callClaudeAPI(ctx, prompt)
+			if err != nil {
+				logger.ErrorContext(ctx, "Claude API call failed", slog.String("error", err.Error()))
+				return nil, fmt.Errorf("failed to call Claude API: %w", err)
+			}
+		} else if s.geminiAPIKey != "" {
+			logger.DebugContext(ctx, "calling LLM", slog.String("provider", "gemini"), slog.Int("attempt", attempt))
+			provider = ProviderGemini
+			llmResponse, model, err = s.callGeminiModels(ctx, prompt)
+			if err != nil {
+				logger.ErrorContext(ctx, "Gemini API call failed", slog.String("error", err.Error()))
+				return nil, fmt.Errorf("failed to call Gemini API: %w", err)
+			}
+		} else {
+			logger.ErrorContext(ctx, "no LLM API key configured")
+			return nil, fmt.Errorf("no LLM API key configured")
+		}
 
-	// Determine which API to use and call it
-	var llmResponse string
-	var err error
+		// Log LLM response size; content only goes to the payload sink
+		logger.DebugContext(ctx, "LLM response received", slog.Int("attempt", attempt), slog.Int("response_chars", len(llmResponse)))
+		logger.Payload(ctx, "llm_response", llmResponse)
+		s.recordParticipantResponse(req, prompt, llmResponse)
 
-	if s.anthropicAPIKey != "" {
-		fmt.Println("Using Claude API for assessment")
-		llmResponse, err = s.callClaudeAPI(ctx, prompt)
-		if err != nil {
-			fmt.Printf("ERROR calling Claude API: %v\n", err)
-			return nil, fmt.Errorf("failed to call Claude API: %w", err)
+		// Parse the LLM response; an unreadable one is asked for again like a rejected one
+		response, err := s.parseAssessmentResponse(llmResponse, req)
+		if errors.Is(err, ErrUnparseableAssessment) && attempt <= s.validationPolicy.MaxRetries {
+			logger.WarnContext(ctx, "unparseable assessment response", slog.Int("attempt", attempt), slog.String("error", err.Error()))
+			continue
 		}
-	} else if s.geminiAPIKey != "" {
-		fmt.Println("Using Gemini API for assessment (fallback)")
-		llmResponse, err = s.callGeminiAPI(ctx, prompt)
 		if err != nil {
-			fmt.Printf("ERROR calling Gemini API: %v\n", err)
-			return nil, fmt.Errorf("failed to call Gemini API: %w", err)
+			logger.ErrorContext(ctx, "failed to parse assessment response", slog.String("error", err.Error()))
+			return nil, fmt.Errorf("failed to parse assessment response: %w", err)
 		}
-	} else {
-		fmt.Println("ERROR: No LLM API key configured (neither Claude nor Gemini)")
-		return nil, fmt.Errorf("no LLM API key configured")
-	}
 
-	// Log LLM response for debugging
-	fmt.Printf("\n=== LLM RESPONSE ===\n")
-	if len(llmResponse) < 3000 {
-		fmt.Printf("Full response:\n%s\n", llmResponse)
-	} else {
-		fmt.Printf("Response preview (first 1500 chars):\n%s\n...[truncated]\n", llmResponse[:1500])
-	}
+		if s.validationPolicy.Rejects(response.Warnings) {
+			logger.WarnContext(ctx, "assessment rejected by validation",
+				slog.Int("attempt", attempt),
+				slog.String("warnings", summarizeWarnings(response.Warnings)),
+			)
+			if attempt > s.validationPolicy.MaxRetries {
+				return nil, &ValidationError{Attempts: attempt, Warnings: response.Warnings}
+			}
+			continue
+		}
 
-	// Parse the LLM response
-	response, err := s.parseAssessmentResponse(llmResponse, req)
-	if err != nil {
-		fmt.Printf("ERROR parsing assessment response: %v\n", err)
-		fmt.Printf("Using fallback response due to parsing error\n")
-		return nil, fmt.Errorf("failed to parse assessment response: %w", err)
+		logger.InfoContext(ctx, "assessment parsed",
+			slog.Float64("overall_score", response.OverallScore),
+			slog.Int("warnings", len(response.Warnings)),
+		)
+
+		provenance := &AssessmentProvenance{
+			Provider:         provider,
+			Model:            model,
+			PromptSHA256:     promptHash(promptWithoutTranscript(prompt, req.Transcript)),
+			CandidatePrompt:  s.promptTemplate != nil,
+			TranscriptSHA256: promptHash(req.Transcript),
+			TranscriptChars:  utf8.RuneCountInString(req.Transcript),
+			Attempts:         attempt,
+			AssessedAt:       time.Now().UTC(),
+		}
+		if job, ok := JobFromContext(ctx); ok {
+			provenance.JobID = job.ID
+		}
+		response.Provenance = provenance
+		for _, participant := range response.Participants {
+			participant.Provenance = provenance
+		}
+		return response, nil
 	}
+}
 
-	fmt.Printf("Successfully parsed assessment for participant: %s\n", response.ParticipantID)
-	return response, nil
+// promptWithoutTranscript removes the transcript from a rendered prompt
+func promptWithoutTranscript(prompt, transcript string) string {
+	if transcript == "" {
+		return prompt
+	}
+	return strings.ReplaceAll(prompt, transcript, "")
 }
 
 // SpeakerIdentificationRequest represents a request for speaker identification
 type SpeakerIdentificationRequest struct {
 	Transcript string `json:"transcript"`
 	SpeakerID  int    `json:"speaker_id"`
+	// Candidates are the names of the session's participants, when known
+	Candidates []string `json:"candidates,omitempty"`
 }
 
 // SpeakerIdentificationResponse represents the response from speaker identification
@@ -215,6 +307,23 @@ type SpeakerIdentificationResponse struct {
 	Name        string `json:"name"`
 	Confidence  string `json:"confidence"` // "high", "medium", "low", "none"
 	Evidence    string `json:"evidence"`
+	// ParticipantID is the roster participant identified, in conversation identification
+	ParticipantID string `json:"participant_id,omitempty"`
+}
+
+// ConversationIdentificationRequest asks for every speaker of a diarized conversation to
+// be identified together
+type ConversationIdentificationRequest struct {
+	// Transcript is the whole conversation as "[Speaker N]: text" lines in speaking order
+	Transcript string               `json:"transcript"`
+	Speakers   []int                `json:"speakers"`
+	Roster     []SessionParticipant `json:"roster,omitempty"`
+}
+
+// ConversationIdentificationResponse holds one assignment per requested speaker, in the
+// order requested
+type ConversationIdentificationResponse struct {
+	Assignments []SpeakerIdentificationResponse `json:"assignments"`
 }
 
 // IdentifySpeaker uses LLM to identify speaker name from transcript
@@ -272,7 +381,7 @@ IMPORTANT CONSIDERATIONS:
 - If the speaker is asking questions or interviewing someone, they are likely NOT the person being described in the answers
 - If someone is being described in third person with titles and achievements, they are likely NOT the speaker
 - Consider the conversational flow and who is actually speaking vs who is being discussed
-
+%s
 Return a JSON response:
 {
   "speaker_id": %d,
@@ -287,11 +396,22 @@ Confidence levels:
 - low: Some clues but uncertain
 - none: Cannot determine from available information
 
-Return ONLY the JSON object.`, req.SpeakerID, req.Transcript, req.SpeakerID)
+Return ONLY the JSON object.`, req.SpeakerID, req.Transcript, candidatesSection(req.Candidates), req.SpeakerID)
 
 	return prompt
 }
 
+// candidatesSection lists the session's participants for the speaker identification prompt
+func candidatesSection(candidates []string) string {
+	if len(candidates) == 0 {
+		return ""
+	}
+	return fmt.Sprintf(`
+SESSION PARTICIPANTS:
+The speaker is most likely one of: %s. If you identify one of them, return the name exactly as listed here.
+`, strings.Join(candidates, ", "))
+}
+
 // parseSpeakerIdentificationResponse parses the LLM response for speaker identification
 func (s *LLMAssessmentService) parseSpeakerIdentificationResponse(llmResponse string, speakerID int) (*SpeakerIdentificationResponse, error) {
 	// Try to extract JSON from response
@@ -327,6 +447,136 @@ func (s *LLMAssessmentService) parseSpeakerIdentificationResponse(llmResponse st
 	return &response, nil
 }
 
+// IdentifySpeakers uses LLM to identify all speakers of a conversation jointly. Unlike
+// IdentifySpeaker it reads every speaker's lines, so a speaker can be named by how the
+// others address them, and it assigns each roster participant at most once.
+func (s *LLMAssessmentService) IdentifySpeakers(ctx context.Context, req ConversationIdentificationRequest) (*ConversationIdentificationResponse, error) {
+	if s.anthropicAPIKey == "" && s.geminiAPIKey == "" {
+		return nil, fmt.Errorf("no LLM API key configured")
+	}
+
+	prompt := s.buildConversationIdentificationPrompt(req)
+
+	var llmResponse string
+	var err error
+	if s.anthropicAPIKey != "" {
+		llmResponse, err = s.callClaudeAPI(ctx, prompt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to call Claude API: %w", err)
+		}
+	} else {
+		llmResponse, err = s.callGeminiAPI(ctx, prompt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to call Gemini API: %w", err)
+		}
+	}
+
+	return s.parseConversationIdentificationResponse(llmResponse, req), nil
+}
+
+// buildConversationIdentificationPrompt creates a prompt for identifying every speaker of
+// a conversation at once
+func (s *LLMAssessmentService) buildConversationIdentificationPrompt(req ConversationIdentificationRequest) string {
+	var roster strings.Builder
+	if len(req.Roster) == 0 {
+		roster.WriteString("No participant list is available; use names mentioned in the conversation.\n")
+	}
+	for _, participant := range req.Roster {
+		fmt.Fprintf(&roster, "- %s (id: %s", participant.Name, participant.ID)
+		if participant.Role != "" {
+			fmt.Fprintf(&roster, ", role: %s", participant.Role)
+		}
+		roster.WriteString(")\n")
+	}
+
+	speakers := make([]string, 0, len(req.Speakers))
+	for _, speaker := range req.Speakers {
+		speakers = append(speakers, strconv.Itoa(speaker))
+	}
+
+	return fmt.Sprintf(`You are analyzing a diarized conversation transcript to work out who each numbered speaker is. Identify all speakers together, using the whole conversation.
+
+EXPECTED PARTICIPANTS:
+%s
+TRANSCRIPT:
+%s
+
+SPEAKERS TO IDENTIFY: %s
+
+ANALYSIS APPROACH:
+1. Self-Introduction: Does a speaker introduce themselves?
+
+2. Being Addressed: A line addressing someone by name ("Thanks Minh", "anh Minh nói đúng") usually answers the previous speaker, so it names that speaker, not the one talking.
+
+3. Third-Person References: Someone talked about ("Minh takes GT") is usually not the one talking, and may not be the one addressed either.
+
+4. Vietnamese Address: Forms of address (anh, chị, em) come before the given name, which is usually the last word of a full name.
+
+5. Joint Solution: Each participant is at most one speaker and each speaker at most one participant. Prefer the assignment that is consistent with all the evidence over the strongest single clue.
+
+Return a JSON response with exactly one assignment per speaker to identify:
+{
+  "assignments": [
+    {
+      "speaker_id": <speaker number>,
+      "name": "<name exactly as listed in the participants, or as said in the conversation, or empty string>",
+      "participant_id": "<id from the participant list, or empty string>",
+      "confidence": "<high|medium|low|none>",
+      "evidence": "<quote the lines that support the assignment>"
+    }
+  ]
+}
+
+Confidence levels:
+- high: Clear self-introduction or being directly addressed by name
+- medium: Strong contextual evidence
+- low: Some clues but uncertain
+- none: Cannot determine from available information
+
+Return ONLY the JSON object.`, roster.String(), req.Transcript, strings.Join(speakers, ", "))
+}
+
+// parseConversationIdentificationResponse parses the LLM response for conversation
+// identification into one assignment per requested speaker. Speakers the response leaves
+// out, or that it can't be parsed for, are unidentified; participant IDs not on the
+// roster are dropped.
+func (s *LLMAssessmentService) parseConversationIdentificationResponse(llmResponse string, req ConversationIdentificationRequest) *ConversationIdentificationResponse {
+	var parsed ConversationIdentificationResponse
+	evidence := "Not identified in LLM response"
+	startIdx := strings.Index(llmResponse, "{")
+	endIdx := strings.LastIndex(llmResponse, "}") + 1
+	if startIdx == -1 || endIdx <= startIdx {
+		evidence = "Could not parse LLM response"
+	} else if err := json.Unmarshal([]byte(llmResponse[startIdx:endIdx]), &parsed); err != nil {
+		evidence = "Failed to parse JSON response"
+	}
+
+	onRoster := make(map[string]bool, len(req.Roster))
+	for _, participant := range req.Roster {
+		onRoster[participant.ID] = true
+	}
+	assignments := make(map[int]SpeakerIdentificationResponse)
+	for _, assignment := range parsed.Assignments {
+		if _, seen := assignments[assignment.SpeakerID]; seen {
+			continue
+		}
+		if !onRoster[assignment.ParticipantID] {
+			assignment.ParticipantID = ""
+		}
+		assignments[assignment.SpeakerID] = assignment
+	}
+
+	response := &ConversationIdentificationResponse{Assignments: make([]SpeakerIdentificationResponse, 0, len(req.Speakers))}
+	for _, speaker := range req.Speakers {
+		assignment, ok := assignments[speaker]
+		if !ok {
+			assignment = SpeakerIdentificationResponse{SpeakerID: speaker, Confidence: "none", Evidence: evidence}
+		}
+		response.Assignments = append(response.Assignments, assignment)
+	}
+	return response
+}
+
 // buildAssessmentPrompt creates a structured prompt for the LLM
 func (s *LLMAssessmentService) buildAssessmentPrompt(req AssessmentRequest) string {
 	var prompt strings.Builder
@@ -340,45 +590,7 @@ func (s *LLMAssessmentService) buildAssessmentPrompt(req AssessmentRequest) stri
 
 	// Assessment criteria with full details
 	prompt.WriteString("COMPREHENSIVE ASSESSMENT CRITERIA:\n\n")
-	for i, criterion := range req.Criteria {
-		prompt.WriteString(fmt.Sprintf("**%d. %s** (Weight: %.0f%%, Category: %s)\n", 
-			i+1, criterion.Name, criterion.Weight*100, criterion.Category))
-		prompt.WriteString(fmt.Sprintf("Description: %s\n", criterion.Description))
-		prompt.WriteString(fmt.Sprintf("Detailed Behaviors: %s\n\n", criterion.DetailedBehaviors))
-		
-		// Add level descriptors if available
-		if len(criterion.LevelDescriptors) > 0 {
-			prompt.WriteString("Level Descriptors:\n")
-			for level := 1; level <= 5; level++ {
-				if desc, ok := criterion.LevelDescriptors[level]; ok {
-					prompt.WriteString(fmt.Sprintf("  Level %d: %s\n", level, desc))
-				}
-			}
-			prompt.WriteString("\n")
-		}
-		
-		// Add case-specific examples if available
-		if len(criterion.CaseSpecificExamples) > 0 {
-			prompt.WriteString("Case-Specific Examples:\n")
-			for level := 1; level <= 5; level++ {
-				if example, ok := criterion.CaseSpecificExamples[level]; ok {
-					prompt.WriteString(fmt.Sprintf("  Level %d: %s\n", level, example))
-				}
-			}
-			prompt.WriteString("\n")
-		}
-		
-		// Add key observables if available
-		if len(criterion.KeyObservables) > 0 {
-			prompt.WriteString("Key Observables:\n")
-			for _, observable := range criterion.KeyObservables {
-				prompt.WriteString(fmt.Sprintf("  - %s\n", observable))
-			}
-			prompt.WriteString("\n")
-		}
-		
-		prompt.WriteString("\n")
-	}
+	writeCriteriaSection(&prompt, req.Criteria)
 
 	// Handle unified transcript case (all speakers in one)
 	if req.ParticipantID == "unified" {
@@ -606,12 +818,76 @@ Return ONLY the JSON array with complete participant assessment.
 	return prompt.String()
 }
 
+// writeCriteriaSection writes each criterion with its weight, level descriptors, examples and observables
+func writeCriteriaSection(prompt *strings.Builder, criteria []AssessmentCriteria) {
+	for i, criterion := range criteria {
+		prompt.WriteString(fmt.Sprintf("**%d. %s** (Weight: %.0f%%, Category: %s)\n", 
+			i+1, criterion.Name, criterion.Weight*100, criterion.Category))
+		prompt.WriteString(fmt.Sprintf("Description: %s\n", criterion.Description))
+		prompt.WriteString(fmt.Sprintf("Detailed Behaviors: %s\n\n", criterion.DetailedBehaviors))
+		
+		// Add level descriptors if available
+		if len(criterion.LevelDescriptors) > 0 {
+			prompt.WriteString("Level Descriptors:\n")
+			for level := 1; level <= 5; level++ {
+				if desc, ok := criterion.LevelDescriptors[level]; ok {
+					prompt.WriteString(fmt.Sprintf("  Level %d: %s\n", level, desc))
+				}
+			}
+			prompt.WriteString("\n")
+		}
+		
+		// Add case-specific examples if available
+		if len(criterion.CaseSpecificExamples) > 0 {
+			prompt.WriteString("Case-Specific Examples:\n")
+			for level := 1; level <= 5; level++ {
+				if example, ok := criterion.CaseSpecificExamples[level]; ok {
+					prompt.WriteString(fmt.Sprintf("  Level %d: %s\n", level, example))
+				}
+			}
+			prompt.WriteString("\n")
+		}
+		
+		// Add key observables if available
+		if len(criterion.KeyObservables) > 0 {
+			prompt.WriteString("Key Observables:\n")
+			for _, observable := range criterion.KeyObservables {
+				prompt.WriteString(fmt.Sprintf("  - %s\n", observable))
+			}
+			prompt.WriteString("\n")
+		}
+		
+		prompt.WriteString("\n")
+	}
+}
+
+// SetRateLimiter shares a provider rate limiter between services; nil disables limiting
+func (s *LLMAssessmentService) SetRateLimiter(limiter *RateLimiter) {
+	s.limiter = limiter
+}
+
+// pauseOnRateLimit pauses a provider when it answers 429, honouring Retry-After
+func (s *LLMAssessmentService) pauseOnRateLimit(provider string, resp *http.Response) {
+	if resp.StatusCode != http.StatusTooManyRequests {
+		return
+	}
+	pause := 30 * time.Second
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds > 0 {
+		pause = time.Duration(seconds) * time.Second
+	}
+	s.logger.Warn("LLM provider rate limited", slog.String("provider", provider), slog.Duration("pause", pause))
+	s.limiter.Pause(provider, pause)
+}
+
+// claudeModel is the Claude model used for every request
+const claudeModel = "claude-sonnet-4-20250514"
+
 // callClaudeAPI makes the API call to Anthropic's Claude
 func (s *LLMAssessmentService) callClaudeAPI(ctx context.Context, prompt string) (string, error) {
 	url := "https://api.anthropic.com/v1/messages"
 
 	reqBody := ClaudeRequest{
-		Model: "claude-sonnet-4-20250514",
+		Model: claudeModel,
 		Messages: []ClaudeMessage{
 			{
 				Role:    "user",
@@ -635,6 +911,12 @@ func (s *LLMAssessmentService) callClaudeAPI(ctx context.Context, prompt string)
 	req.Header.Set("x-api-key", s.anthropicAPIKey)
 	req.Header.Set("anthropic-version", "2023-06-01")
 
+	release, err := s.limiter.Acquire(ctx, ProviderClaude, EstimateTokens(prompt))
+	if err != nil {
+		return "", err
+	}
+	defer release()
+
 	client := &http.Client{}
 	resp, err := client.Do(req)
 	if err != nil {
@@ -643,6 +925,7 @@ func (s *LLMAssessmentService) callClaudeAPI(ctx context.Context, prompt string)
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
+		s.pauseOnRateLimit(ProviderClaude, resp)
 		bodyBytes, _ := io.ReadAll(resp.Body)
 		return "", fmt.Errorf("claude API returned status %d: %s", resp.StatusCode, string(bodyBytes))
 	}
@@ -669,6 +952,12 @@ func (s *LLMAssessmentService) callClaudeAPI(ctx context.Context, prompt string)
 
 // callGeminiAPI makes a request to the Gemini API
 func (s *LLMAssessmentService) callGeminiAPI(ctx context.Context, prompt string) (string, error) {
+	response, _, err := s.callGeminiModels(ctx, prompt)
+	return response, err
+}
+
+// callGeminiModels makes a request to the Gemini API and reports which model answered
+func (s *LLMAssessmentService) callGeminiModels(ctx context.Context, prompt string) (string, string, error) {
 	// Try multiple model endpoints in order of preference (newer models first)
 	models := []string{
 		"gemini-2.0-flash-exp",
@@ -683,20 +972,20 @@ func (s *LLMAssessmentService) callGeminiAPI(ctx context.Context, prompt string)
 		url := fmt.Sprintf("https://generativelanguage.googleapis.com/v1beta/models/%s:generateContent", model)
 		response, err := s.callGeminiAPIWithURL(ctx, url, prompt)
 		if err == nil {
-			fmt.Printf("Successfully used model: %s\n", model)
-			return response, nil
+			s.logger.Debug("Gemini model succeeded", slog.String("model", model))
+			return response, model, nil
 		}
 
-		fmt.Printf("Model %s failed: %v\n", model, err)
+		s.logger.Warn("Gemini model failed", slog.String("model", model), slog.String("error", err.Error()))
 		lastError = err
 
 		// If it's a 404, try next model. If it's another error, return immediately
 		if !strings.Contains(err.Error(), "404") && !strings.Contains(err.Error(), "NOT_FOUND") {
-			return "", err
+			return "", "", err
 		}
 	}
 
-	return "", fmt.Errorf("all Gemini models failed, last error: %v", lastError)
+	return "", "", fmt.Errorf("all Gemini models failed, last error: %v", lastError)
 }
 
 // callGeminiAPIWithURL makes the actual HTTP request
@@ -725,6 +1014,12 @@ func (s *LLMAssessmentService) callGeminiAPIWithURL(ctx context.Context, url, pr
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("x-goog-api-key", s.geminiAPIKey)
 
+	release, err := s.limiter.Acquire(ctx, ProviderGemini, EstimateTokens(prompt))
+	if err != nil {
+		return "", err
+	}
+	defer release()
+
 	client := &http.Client{}
 	resp, err := client.Do(req)
 	if err != nil {
@@ -733,6 +1028,7 @@ func (s *LLMAssessmentService) callGeminiAPIWithURL(ctx context.Context, url, pr
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
+		s.pauseOnRateLimit(ProviderGemini, resp)
 		bodyBytes, _ := io.ReadAll(resp.Body)
 		return "", fmt.Errorf("gemini API returned status %d: %s", resp.StatusCode, string(bodyBytes))
 	}
@@ -767,7 +1063,7 @@ type ParticipantAssessment struct {
 
 // CompetencyScore represents the score for a single competency
 type CompetencyScore struct {
-	Score                int      `json:"score"`
+	Score                float64  `json:"score"` // Decoded as float64 so a fractional level is rounded, not a parse failure
 	Evidence             []string `json:"evidence"`
 	Feedback             string   `json:"feedback"`
 	LevelJustification   string   `json:"levelJustification"`
@@ -780,7 +1076,7 @@ func (s *LLMAssessmentService) parseAssessmentResponse(llmResponse string, req A
 	endIdx := strings.LastIndex(llmResponse, "]") + 1
 	
 	if startIdx == -1 || endIdx <= startIdx {
-		return nil, fmt.Errorf("no valid JSON array found in LLM response")
+		return nil, fmt.Errorf("%w: no JSON array found", ErrUnparseableAssessment)
 	}
 
 	jsonStr := llmResponse[startIdx:endIdx]
@@ -788,69 +1084,22 @@ func (s *LLMAssessmentService) parseAssessmentResponse(llmResponse string, req A
 	// Parse the JSON array response
 	var participants []ParticipantAssessment
 	if err := json.Unmarshal([]byte(jsonStr), &participants); err != nil {
-		// If parsing fails, create a fallback response
-		return s.createFallbackResponse(req), nil
+		return nil, fmt.Errorf("%w: %v", ErrUnparseableAssessment, err)
 	}
 
+	// Validate scores, evidence and participant coverage before converting
+	warnings := s.validateParticipantAssessments(participants, req)
+
 	// Handle unified transcript case - return multiple participants
 	if req.ParticipantID == "unified" {
-		fmt.Printf("Parsing unified assessment response with %d participants\n", len(participants))
+		s.logger.ForSession(req.SessionID).Debug("parsing unified assessment response", slog.Int("participants", len(participants)))
 		
 		// For unified transcript, we need to handle all participants
 		allResponses := make([]*AssessmentResponse, 0)
 		
 		for _, participant := range participants {
 			// Convert to assessment results format for this participant
-			results := []AssessmentResult{}
-			overallScore := 0.0
-			totalWeight := 0.0
-
-			for _, criterion := range req.Criteria {
-				// Get the score for this criterion (scores are keyed by criterion ID)
-				compScore, exists := participant.Scores[criterion.ID]
-				if !exists {
-					// If no score for this criterion, use default
-					results = append(results, AssessmentResult{
-						CriterionID:   criterion.ID,
-						CriterionName: criterion.Name,
-						Score:         0,
-						Status:        "N/A",
-						Observations:  "No assessment data available",
-					})
-					continue
-				}
-
-				status := "Pass"
-				if compScore.Score < 3 {
-					status = "Fail"
-				} else if compScore.Score == 0 {
-					status = "N/A"
-				}
-
-				// Combine evidence into observations
-				observations := compScore.Feedback
-				if len(compScore.Evidence) > 0 {
-					observations = fmt.Sprintf("%s\n\nEvidence: %s", compScore.Feedback, strings.Join(compScore.Evidence, "; "))
-				}
-
-				results = append(results, AssessmentResult{
-					CriterionID:   criterion.ID,
-					CriterionName: criterion.Name,
-					Score:         compScore.Score,
-					Status:        status,
-					Observations:  observations,
-					Evidence:      compScore.LevelJustification,
-				})
-
-				// Calculate weighted score
-				overallScore += float64(compScore.Score) * criterion.Weight
-				totalWeight += criterion.Weight
-			}
-
-			// Normalize overall score if weights don't sum to 1
-			if totalWeight > 0 {
-				overallScore = overallScore / totalWeight
-			}
+			results, overallScore := scoreCriteria(participant.Scores, req.Criteria)
 
 			// Create summary from overall assessment and key strengths/development areas
 			summary := participant.OverallAssessment
@@ -868,6 +1117,7 @@ func (s *LLMAssessmentService) parseAssessmentResponse(llmResponse string, req A
 				Results:       results,
 				OverallScore:  overallScore,
 				Summary:       summary,
+				Warnings:      warningsForParticipant(warnings, participant.ParticipantID),
 			}
 			
 			allResponses = append(allResponses, response)
@@ -887,9 +1137,11 @@ func (s *LLMAssessmentService) parseAssessmentResponse(llmResponse string, req A
 				Results:       []AssessmentResult{}, // Empty results as this is just a marker
 				OverallScore:  0,
 				Summary:       fmt.Sprintf("Unified assessment with %d participants", len(allResponses)),
+				Warnings:      warnings,
+				Participants:  allResponses,
 			}, nil
 		}
-		return s.createFallbackResponse(req), nil
+		return nil, fmt.Errorf("%w: no participants in unified response", ErrUnparseableAssessment)
 	}
 	
 	// Find the participant in the response (for individual assessment)
@@ -902,18 +1154,45 @@ func (s *LLMAssessmentService) parseAssessmentResponse(llmResponse string, req A
 	}
 
 	if targetParticipant == nil {
-		// Participant not found in response, use fallback
-		return s.createFallbackResponse(req), nil
+		// Asked again rather than scored, so a missing participant never gets made-up scores
+		return nil, fmt.Errorf("%w: participant %s missing from response", ErrUnparseableAssessment, req.ParticipantID)
 	}
 
 	// Convert to assessment results format
+	results, overallScore := scoreCriteria(targetParticipant.Scores, req.Criteria)
+
+	// Create summary from overall assessment and key strengths/development areas
+	summary := targetParticipant.OverallAssessment
+	if len(targetParticipant.KeyStrengths) > 0 {
+		summary += fmt.Sprintf("\n\nKey Strengths: %s", strings.Join(targetParticipant.KeyStrengths, "; "))
+	}
+	if len(targetParticipant.DevelopmentPriorities) > 0 {
+		summary += fmt.Sprintf("\n\nDevelopment Areas: %s", strings.Join(targetParticipant.DevelopmentPriorities, "; "))
+	}
+
+	// Create the final response
+	response := &AssessmentResponse{
+		ParticipantID: req.ParticipantID,
+		SessionID:     req.SessionID,
+		Results:       results,
+		OverallScore:  overallScore,
+		Summary:       summary,
+		Warnings:      warnings,
+	}
+
+	return response, nil
+}
+
+// scoreCriteria converts per-criterion LLM scores into results and a weighted overall
+// score. N/A (0) scores are left out of the overall score, weight included.
+func scoreCriteria(scores map[string]CompetencyScore, criteria []AssessmentCriteria) ([]AssessmentResult, float64) {
 	results := []AssessmentResult{}
 	overallScore := 0.0
 	totalWeight := 0.0
 
-	for _, criterion := range req.Criteria {
+	for _, criterion := range criteria {
 		// Get the score for this criterion (scores are keyed by criterion ID)
-		compScore, exists := targetParticipant.Scores[criterion.ID]
+		compScore, exists := scores[criterion.ID]
 		if !exists {
 			// If no score for this criterion, use default
 			results = append(results, AssessmentResult{
@@ -926,11 +1205,12 @@ func (s *LLMAssessmentService) parseAssessmentResponse(llmResponse string, req A
 			continue
 		}
 
+		score := int(math.Round(compScore.Score))
 		status := "Pass"
-		if compScore.Score < 3 {
-			status = "Fail"
-		} else if compScore.Score == 0 {
+		if score == 0 {
 			status = "N/A"
+		} else if score < 3 {
+			status = "Fail"
 		}
 
 		// Combine evidence into observations
@@ -942,97 +1222,25 @@ func (s *LLMAssessmentService) parseAssessmentResponse(llmResponse string, req A
 		results = append(results, AssessmentResult{
 			CriterionID:   criterion.ID,
 			CriterionName: criterion.Name,
-			Score:         compScore.Score,
+			Score:         score,
 			Status:        status,
 			Observations:  observations,
 			Evidence:      compScore.LevelJustification,
 		})
 
 		// Calculate weighted score
-		overallScore += float64(compScore.Score) * criterion.Weight
-		totalWeight += criterion.Weight
-	}
-
-	// Normalize overall score if weights don't sum to 1
-	if totalWeight > 0 {
-		overallScore = overallScore / totalWeight
-	}
-
-	// Create summary from overall assessment and key strengths/development areas
-	summary := targetParticipant.OverallAssessment
-	if len(targetParticipant.KeyStrengths) > 0 {
-		summary += fmt.Sprintf("\n\nKey Strengths: %s", strings.Join(targetParticipant.KeyStrengths, "; "))
-	}
-	if len(targetParticipant.DevelopmentPriorities) > 0 {
-		summary += fmt.Sprintf("\n\nDevelopment Areas: %s", strings.Join(targetParticipant.DevelopmentPriorities, "; "))
-	}
-
-	// Create the final response
-	response := &AssessmentResponse{
-		ParticipantID: req.ParticipantID,
-		SessionID:     req.SessionID,
-		Results:       results,
-		OverallScore:  overallScore,
-		Summary:       summary,
-	}
-
-	return response, nil
-}
-
-// createFallbackResponse creates a fallback response when LLM parsing fails
-func (s *LLMAssessmentService) createFallbackResponse(req AssessmentRequest) *AssessmentResponse {
-	results := make([]AssessmentResult, len(req.Criteria))
-	
-	// Check if transcript is too short for meaningful assessment
-	transcriptWords := len(strings.Fields(req.Transcript))
-	hasMinimalContent := transcriptWords < 50 // Less than 50 words threshold
-	
-	for i, criterion := range req.Criteria {
-		var score int
-		var status string
-		var observations string
-		
-		if hasMinimalContent {
-			// If transcript is less than 200 words, all competencies should be N/A
-			score = 0
-			status = "N/A"
-			observations = fmt.Sprintf("Insufficient transcript content (<%d words) to assess %s. Minimum 200 words required for meaningful assessment.", transcriptWords, criterion.Name)
-		} else {
-			// For longer transcripts, generate reasonable fallback scores
-			score = 3 // Default to "Fully Meets"
-			status = "Pass"
-			observations = fmt.Sprintf("Assessment completed for %s based on transcript analysis.", criterion.Name)
-		}
-		
-		results[i] = AssessmentResult{
-			CriterionID:   criterion.ID,
-			CriterionName: criterion.Name,
-			Score:         score,
-			Status:        status,
-			Observations:  observations,
+		if score > 0 {
+			overallScore += float64(score) * criterion.Weight
+			totalWeight += criterion.Weight
 		}
 	}
 
-	// Calculate weighted overall score
-	overallScore := 0.0
-	totalWeight := 0.0
-	for i, result := range results {
-		if result.Score > 0 {
-			overallScore += float64(result.Score) * req.Criteria[i].Weight
-			totalWeight += req.Criteria[i].Weight
-		}
-	}
+	// Normalize overall score if weights don't sum to 1
 	if totalWeight > 0 {
 		overallScore = overallScore / totalWeight
 	}
 
-	return &AssessmentResponse{
-		ParticipantID: req.ParticipantID,
-		SessionID:     req.SessionID,
-		Results:       results,
-		OverallScore:  overallScore,
-		Summary:       "Assessment completed successfully using AI analysis of participant transcript.",
-	}
+	return results, overallScore
 }
 
 // GroupAssessmentRequest represents a request for group assessment
@@ -1046,7 +1254,9 @@ type GroupAssessmentRequest struct {
 // GroupAssessmentResponse represents the complete group assessment response
 type GroupAssessmentResponse struct {
 	SessionID                   string                 `json:"session_id"`
-	Score                       int                    `json:"score"`
+	Results                     []AssessmentResult     `json:"results"`       // Per-criterion scores against req.Criteria
+	OverallScore                float64                `json:"overall_score"` // Weighted over the group criteria
+	Score                       int                    `json:"score"`         // Solution quality score (0-5)
 	ScoringJustification        string                 `json:"scoring_justification"`
 	ChallengesIdentified        []string               `json:"challenges_identified"`
 	StrategicElementsCovered    map[string]string      `json:"strategic_elements_covered"`
@@ -1057,166 +1267,158 @@ type GroupAssessmentResponse struct {
 	ConsumerInsightUtilization  string                 `json:"consumer_insight_utilization"`
 	AlignmentWithCEOGuidance    string                 `json:"alignment_with_ceo_guidance"`
 	OverallComments             string                 `json:"overall_comments"`
+	Warnings                    []ValidationWarning    `json:"warnings,omitempty"`
+}
+
+// GroupSolutionAssessment is the JSON structure the LLM returns for a group assessment
+type GroupSolutionAssessment struct {
+	CriteriaScores             map[string]CompetencyScore `json:"criteriaScores"`
+	Score                      float64                    `json:"score"`
+	ScoringJustification       string                     `json:"scoringJustification"`
+	ChallengesIdentified       []string                   `json:"challengesIdentified"`
+	StrategicElementsCovered   map[string]string          `json:"strategicElementsCovered"`
+	SolutionStrengths          []string                   `json:"solutionStrengths"`
+	SolutionGaps               []string                   `json:"solutionGaps"`
+	FeasibilityAssessment      string                     `json:"feasibilityAssessment"`
+	IntegrationQuality         string                     `json:"integrationQuality"`
+	ConsumerInsightUtilization string                     `json:"consumerInsightUtilization"`
+	AlignmentWithCEOGuidance   string                     `json:"alignmentWithCEOGuidance"`
+	OverallComments            string                     `json:"overallComments"`
 }
 
 // ProcessGroupAssessment processes a transcript for group assessment
 func (s *LLMAssessmentService) ProcessGroupAssessment(ctx context.Context, req GroupAssessmentRequest) (*GroupAssessmentResponse, error) {
-	// Build the prompt for group assessment
+	// Build the prompt for group assessment, preferring a candidate template when one is set
 	prompt := s.buildGroupAssessmentPrompt(req)
+	if s.groupPromptTemplate != nil {
+		var err error
+		if prompt, err = s.renderGroupPromptTemplate(req); err != nil {
+			return nil, err
+		}
+	}
 
-	// Log the received transcript
-	fmt.Printf("\n=== GROUP ASSESSMENT TRANSCRIPT RECEIVED ===\n")
-	fmt.Printf("Session ID: %s\n", req.SessionID)
-	fmt.Printf("Language: %s\n", req.Language)
-	fmt.Printf("Transcript length: %d characters\n", len(req.Transcript))
+	logger := s.logger.ForSession(req.SessionID)
+
+	// Log the received transcript without its content
+	logger.InfoContext(ctx, "group assessment transcript received",
+		slog.String("language", req.Language),
+		RedactedText("transcript", req.Transcript),
+		slog.Int("criteria", len(req.Criteria)),
+	)
+	logger.Payload(ctx, "group_prompt", prompt)
+
+	// Call the LLM, retrying while the validation policy rejects the response
+	for attempt := 1; ; attempt++ {
+		// Determine which API to use and call it
+		var llmResponse string
+		var err error
+
+		if s.anthropicAPIKey != "" {
+			logger.DebugContext(ctx, "calling LLM", slog.String("provider", "claude"), slog.Int("attempt", attempt))
+			llmResponse, err = s.callClaudeAPI(ctx, prompt)
+		} else if s.geminiAPIKey != "" {
+			logger.DebugContext(ctx, "calling LLM", slog.String("provider", "gemini"), slog.Int("attempt", attempt))
+			llmResponse, err = s.callGeminiAPI(ctx, prompt)
+		} else {
+			logger.ErrorContext(ctx, "no LLM API key configured")
+			return nil, fmt.Errorf("no LLM API key configured")
+		}
 
-	// Determine which API to use and call it
-	var llmResponse string
-	var err error
+		if err != nil {
+			logger.ErrorContext(ctx, "group assessment LLM call failed", slog.String("error", err.Error()))
+			return nil, fmt.Errorf("LLM API call failed: %w", err)
+		}
 
-	if s.anthropicAPIKey != "" {
-		fmt.Printf("Using Claude API for group assessment\n")
-		llmResponse, err = s.callClaudeAPI(ctx, prompt)
-	} else if s.geminiAPIKey != "" {
-		fmt.Printf("Using Gemini API for group assessment\n")
-		llmResponse, err = s.callGeminiAPI(ctx, prompt)
-	} else {
-		return nil, fmt.Errorf("no LLM API key configured")
-	}
+		// Parse the LLM response as JSON
+		logger.DebugContext(ctx, "LLM response received", slog.Int("attempt", attempt), slog.Int("response_chars", len(llmResponse)))
+		logger.Payload(ctx, "group_llm_response", llmResponse)
+		s.recordGroupResponse(req, prompt, llmResponse)
 
-	if err != nil {
-		return nil, fmt.Errorf("LLM API call failed: %w", err)
-	}
+		// Parse the LLM response; an unreadable one is asked for again like a rejected one
+		response, err := s.parseGroupAssessmentResponse(llmResponse, req)
+		if errors.Is(err, ErrUnparseableAssessment) && attempt <= s.validationPolicy.MaxRetries {
+			logger.WarnContext(ctx, "unparseable group assessment response", slog.Int("attempt", attempt), slog.String("error", err.Error()))
+			continue
+		}
+		if err != nil {
+			logger.ErrorContext(ctx, "failed to parse group assessment response", slog.String("error", err.Error()))
+			return nil, fmt.Errorf("failed to parse group assessment response: %w", err)
+		}
 
-	// Parse the LLM response as JSON
-	fmt.Printf("\n=== LLM RESPONSE FOR GROUP ASSESSMENT ===\n")
-	fmt.Printf("Response length: %d characters\n", len(llmResponse))
+		if s.validationPolicy.Rejects(response.Warnings) {
+			logger.WarnContext(ctx, "group assessment rejected by validation",
+				slog.Int("attempt", attempt),
+				slog.String("warnings", summarizeWarnings(response.Warnings)),
+			)
+			if attempt > s.validationPolicy.MaxRetries {
+				return nil, &ValidationError{Attempts: attempt, Warnings: response.Warnings}
+			}
+			continue
+		}
+
+		logger.InfoContext(ctx, "group assessment parsed",
+			slog.Float64("overall_score", response.OverallScore),
+			slog.Int("solution_score", response.Score),
+			slog.Int("warnings", len(response.Warnings)),
+		)
+		return response, nil
+	}
+}
 
+// parseGroupAssessmentResponse parses the LLM response into a group assessment
+func (s *LLMAssessmentService) parseGroupAssessmentResponse(llmResponse string, req GroupAssessmentRequest) (*GroupAssessmentResponse, error) {
 	// Clean up the response to extract JSON
 	jsonStr := strings.TrimSpace(llmResponse)
 	// Find JSON object boundaries
 	startIdx := strings.Index(jsonStr, "{")
 	if startIdx == -1 {
-		return nil, fmt.Errorf("no JSON object found in response")
+		return nil, fmt.Errorf("%w: no JSON object found", ErrUnparseableAssessment)
 	}
 	// Find the matching closing brace
 	endIdx := strings.LastIndex(jsonStr, "}")
 	if endIdx == -1 || endIdx < startIdx {
-		return nil, fmt.Errorf("invalid JSON structure in response")
+		return nil, fmt.Errorf("%w: invalid JSON structure", ErrUnparseableAssessment)
 	}
 	jsonStr = jsonStr[startIdx : endIdx+1]
 
-	// Parse the new group solution assessment format
-	var parsed map[string]interface{}
-	if err := json.Unmarshal([]byte(jsonStr), &parsed); err != nil {
-		fmt.Printf("Error parsing JSON: %v\n", err)
-		return &GroupAssessmentResponse{
-			SessionID:            req.SessionID,
-			Score:                0,
-			ScoringJustification: "Error parsing LLM response",
-			OverallComments:      fmt.Sprintf("Failed to parse assessment: %v", err),
-		}, nil
-	}
-
-	// Extract groupSolutionAssessment object
-	var assessment map[string]interface{}
-	if assessmentData, ok := parsed["groupSolutionAssessment"].(map[string]interface{}); ok {
-		assessment = assessmentData
-	} else {
-		assessment = parsed // Fallback if LLM returns flat structure
-	}
-
-	// Extract score
-	score := 0
-	if scoreVal, ok := assessment["score"].(float64); ok {
-		score = int(scoreVal)
-	}
-
-	// Extract scoring justification
-	scoringJustification := ""
-	if val, ok := assessment["scoringJustification"].(string); ok {
-		scoringJustification = val
-	}
-
-	// Extract challenges identified
-	challengesIdentified := []string{}
-	if challenges, ok := assessment["challengesIdentified"].([]interface{}); ok {
-		for _, c := range challenges {
-			if str, ok := c.(string); ok {
-				challengesIdentified = append(challengesIdentified, str)
-			}
-		}
-	}
-
-	// Extract strategic elements covered
-	strategicElementsCovered := make(map[string]string)
-	if elements, ok := assessment["strategicElementsCovered"].(map[string]interface{}); ok {
-		for key, val := range elements {
-			if str, ok := val.(string); ok {
-				strategicElementsCovered[key] = str
-			}
-		}
+	// Parse the group solution assessment format, accepting a flat object as fallback
+	var envelope struct {
+		GroupSolutionAssessment *GroupSolutionAssessment `json:"groupSolutionAssessment"`
 	}
-
-	// Extract solution strengths
-	solutionStrengths := []string{}
-	if strengths, ok := assessment["solutionStrengths"].([]interface{}); ok {
-		for _, s := range strengths {
-			if str, ok := s.(string); ok {
-				solutionStrengths = append(solutionStrengths, str)
-			}
-		}
+	if err := json.Unmarshal([]byte(jsonStr), &envelope); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnparseableAssessment, err)
 	}
 
-	// Extract solution gaps
-	solutionGaps := []string{}
-	if gaps, ok := assessment["solutionGaps"].([]interface{}); ok {
-		for _, g := range gaps {
-			if str, ok := g.(string); ok {
-				solutionGaps = append(solutionGaps, str)
-			}
+	assessment := envelope.GroupSolutionAssessment
+	if assessment == nil {
+		assessment = &GroupSolutionAssessment{}
+		if err := json.Unmarshal([]byte(jsonStr), assessment); err != nil {
+			return nil, fmt.Errorf("%w: flat group assessment: %v", ErrUnparseableAssessment, err)
 		}
 	}
 
-	// Extract other fields
-	feasibilityAssessment := ""
-	if val, ok := assessment["feasibilityAssessment"].(string); ok {
-		feasibilityAssessment = val
-	}
-
-	integrationQuality := ""
-	if val, ok := assessment["integrationQuality"].(string); ok {
-		integrationQuality = val
-	}
-
-	consumerInsightUtilization := ""
-	if val, ok := assessment["consumerInsightUtilization"].(string); ok {
-		consumerInsightUtilization = val
-	}
-
-	alignmentWithCEOGuidance := ""
-	if val, ok := assessment["alignmentWithCEOGuidance"].(string); ok {
-		alignmentWithCEOGuidance = val
-	}
+	// Validate solution and criterion scores before weighting them
+	warnings := s.validateGroupAssessment(assessment, req)
 
-	overallComments := ""
-	if val, ok := assessment["overallComments"].(string); ok {
-		overallComments = val
-	}
+	// Score each group criterion and weight them into the overall group score
+	results, overallScore := scoreCriteria(assessment.CriteriaScores, req.Criteria)
 
 	return &GroupAssessmentResponse{
 		SessionID:                  req.SessionID,
-		Score:                      score,
-		ScoringJustification:       scoringJustification,
-		ChallengesIdentified:       challengesIdentified,
-		StrategicElementsCovered:   strategicElementsCovered,
-		SolutionStrengths:          solutionStrengths,
-		SolutionGaps:               solutionGaps,
-		FeasibilityAssessment:      feasibilityAssessment,
-		IntegrationQuality:         integrationQuality,
-		ConsumerInsightUtilization: consumerInsightUtilization,
-		AlignmentWithCEOGuidance:   alignmentWithCEOGuidance,
-		OverallComments:            overallComments,
+		Results:                    results,
+		OverallScore:               overallScore,
+		Score:                      int(math.Round(assessment.Score)),
+		ScoringJustification:       assessment.ScoringJustification,
+		ChallengesIdentified:       assessment.ChallengesIdentified,
+		StrategicElementsCovered:   assessment.StrategicElementsCovered,
+		SolutionStrengths:          assessment.SolutionStrengths,
+		SolutionGaps:               assessment.SolutionGaps,
+		FeasibilityAssessment:      assessment.FeasibilityAssessment,
+		IntegrationQuality:         assessment.IntegrationQuality,
+		ConsumerInsightUtilization: assessment.ConsumerInsightUtilization,
+		AlignmentWithCEOGuidance:   assessment.AlignmentWithCEOGuidance,
+		OverallComments:            assessment.OverallComments,
+		Warnings:                   warnings,
 	}, nil
 }
 
@@ -1281,10 +1483,17 @@ Evaluate the group's proposed solution based on:
 - No use of data or insights
 - No strategic recommendations
 
-## TRANSCRIPT TO ASSESS
-
 `)
 
+	// Group competency criteria, each scored separately in criteriaScores
+	if len(req.Criteria) > 0 {
+		prompt.WriteString("## GROUP COMPETENCY CRITERIA\n\n")
+		prompt.WriteString("In addition to the solution score, score the group against EACH criterion below (1-5, or 0 for N/A) using its Level Descriptors and Key Observables. ")
+		prompt.WriteString("Every non-zero score must cite specific evidence from the transcript.\n\n")
+		writeCriteriaSection(&prompt, req.Criteria)
+	}
+
+	prompt.WriteString("## TRANSCRIPT TO ASSESS\n\n")
 	prompt.WriteString(req.Transcript)
 	prompt.WriteString("\n\n")
 
@@ -1294,7 +1503,21 @@ Return assessment as JSON:
 
 {
   "groupSolutionAssessment": {
-    "score": 0-5,
+`)
+
+	if len(req.Criteria) > 0 {
+		prompt.WriteString("    \"criteriaScores\": {\n")
+		for i, criterion := range req.Criteria {
+			prompt.WriteString(fmt.Sprintf("      \"%s\": { \"score\": 0-5, \"evidence\": [\"quote or behavior for %s\"], \"feedback\": \"\", \"levelJustification\": \"\" }", criterion.ID, criterion.Name))
+			if i < len(req.Criteria)-1 {
+				prompt.WriteString(",")
+			}
+			prompt.WriteString("\n")
+		}
+		prompt.WriteString("    },\n")
+	}
+
+	prompt.WriteString(`    "score": 0-5,
     "scoringJustification": "Detailed explanation of why this score was assigned",
     "challengesIdentified": [
       "List each core challenge the team successfully identified",
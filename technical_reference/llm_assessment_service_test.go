@@ -0,0 +1,264 @@
+package assessment
+
+import (
+	"errors"
+	"io"
+	"testing"
+)
+
+// newTestLLMService returns a service with no provider keys and a discarded log, for
+// exercising parsing and validation on canned responses
+func newTestLLMService() *LLMAssessmentService {
+	return &LLMAssessmentService{
+		validationPolicy: ValidationPolicy{Mode: ValidationModeWarn, MaxRetries: 1, RequireEvidence: true},
+		logger:           NewAssessmentLogger(io.Discard, LoggingConfig{}),
+	}
+}
+
+var testCriteria = []AssessmentCriteria{
+	{ID: "communication", Name: "Communication", Weight: 0.5},
+	{ID: "teamwork", Name: "Teamwork", Weight: 0.5},
+}
+
+func TestParseAssessmentResponse(t *testing.T) {
+	tests := []struct {
+		name          string
+		participantID string
+		response      string
+		wantErr       error
+		wantScores    map[string]int
+		wantOverall   float64
+	}{
+		{
+			name:          "scored participant",
+			participantID: "p1",
+			response: `[{"participantId": "p1", "scores": {
+				"communication": {"score": 4, "evidence": ["framed the budget question"]},
+				"teamwork": {"score": 2, "evidence": ["talked over Lan"]}}}]`,
+			wantScores:  map[string]int{"communication": 4, "teamwork": 2},
+			wantOverall: 3,
+		},
+		{
+			name:          "N/A left out of the overall score",
+			participantID: "p1",
+			response: `[{"participantId": "p1", "scores": {
+				"communication": {"score": 4, "evidence": ["framed the budget question"]},
+				"teamwork": {"score": 0}}}]`,
+			wantScores:  map[string]int{"communication": 4, "teamwork": 0},
+			wantOverall: 4,
+		},
+		{
+			name:          "requested participant missing",
+			participantID: "p1",
+			response:      `[{"participantId": "p2", "scores": {"communication": {"score": 3, "evidence": ["x"]}}}]`,
+			wantErr:       ErrUnparseableAssessment,
+		},
+		{
+			name:          "no JSON array",
+			participantID: "p1",
+			response:      "I cannot assess this transcript.",
+			wantErr:       ErrUnparseableAssessment,
+		},
+		{
+			name:          "malformed JSON",
+			participantID: "p1",
+			response:      `[{"participantId": "p1", "scores": {]`,
+			wantErr:       ErrUnparseableAssessment,
+		},
+		{
+			name:          "unified response without participants",
+			participantID: "unified",
+			response:      `[]`,
+			wantErr:       ErrUnparseableAssessment,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestLLMService()
+			response, err := s.parseAssessmentResponse(tt.response, AssessmentRequest{
+				ParticipantID: tt.participantID,
+				SessionID:     "session",
+				Criteria:      testCriteria,
+			})
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("got response %+v, error %v; want %v", response, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			for _, result := range response.Results {
+				if want := tt.wantScores[result.CriterionID]; result.Score != want {
+					t.Errorf("%s: score %d, want %d", result.CriterionID, result.Score, want)
+				}
+			}
+			if response.OverallScore != tt.wantOverall {
+				t.Errorf("overall score %v, want %v", response.OverallScore, tt.wantOverall)
+			}
+		})
+	}
+}
+
+func TestParseGroupAssessmentResponse(t *testing.T) {
+	tests := []struct {
+		name         string
+		response     string
+		wantErr      error
+		wantSolution int
+		wantScores   map[string]int
+		wantWarnings []string
+	}{
+		{
+			name: "enveloped assessment",
+			response: `{"groupSolutionAssessment": {"score": 4, "scoringJustification": "covers every market",
+				"criteriaScores": {
+					"communication": {"score": 4, "evidence": ["shared the plan"]},
+					"teamwork": {"score": 3, "evidence": ["split the channels"]}}}}`,
+			wantSolution: 4,
+			wantScores:   map[string]int{"communication": 4, "teamwork": 3},
+		},
+		{
+			name: "flat assessment",
+			response: `{"score": 2, "scoringJustification": "one channel only",
+				"criteriaScores": {
+					"communication": {"score": 2, "evidence": ["little discussion"]},
+					"teamwork": {"score": 2, "evidence": ["one speaker"]}}}`,
+			wantSolution: 2,
+			wantScores:   map[string]int{"communication": 2, "teamwork": 2},
+		},
+		{
+			name: "fractional solution and criterion scores",
+			response: `{"groupSolutionAssessment": {"score": 3.5, "scoringJustification": "between levels",
+				"criteriaScores": {
+					"communication": {"score": 3.5, "evidence": ["shared the plan"]},
+					"teamwork": {"score": 2.4, "evidence": ["split the channels"]}}}}`,
+			wantSolution: 4,
+			wantScores:   map[string]int{"communication": 4, "teamwork": 2},
+			wantWarnings: []string{WarningFractionalScore, WarningFractionalScore, WarningFractionalScore},
+		},
+		{
+			name:     "no JSON object",
+			response: "The group did not discuss the case.",
+			wantErr:  ErrUnparseableAssessment,
+		},
+		{
+			name:     "malformed JSON",
+			response: `{"groupSolutionAssessment": {"score": "four"}}`,
+			wantErr:  ErrUnparseableAssessment,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestLLMService()
+			response, err := s.parseGroupAssessmentResponse(tt.response, GroupAssessmentRequest{
+				SessionID: "session",
+				Criteria:  testCriteria,
+			})
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("got response %+v, error %v; want %v", response, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if response.Score != tt.wantSolution {
+				t.Errorf("solution score %d, want %d", response.Score, tt.wantSolution)
+			}
+			for _, result := range response.Results {
+				if want := tt.wantScores[result.CriterionID]; result.Score != want {
+					t.Errorf("%s: score %d, want %d", result.CriterionID, result.Score, want)
+				}
+			}
+			codes := []string{}
+			for _, warning := range response.Warnings {
+				codes = append(codes, warning.Code)
+			}
+			if len(codes) != len(tt.wantWarnings) {
+				t.Fatalf("warnings %v, want %v", codes, tt.wantWarnings)
+			}
+			for i := range codes {
+				if codes[i] != tt.wantWarnings[i] {
+					t.Errorf("warnings %v, want %v", codes, tt.wantWarnings)
+				}
+			}
+		})
+	}
+}
+
+func TestParseConversationIdentificationResponse(t *testing.T) {
+	unidentified := func(speaker int, evidence string) SpeakerIdentificationResponse {
+		return SpeakerIdentificationResponse{SpeakerID: speaker, Confidence: "none", Evidence: evidence}
+	}
+	tests := []struct {
+		name     string
+		response string
+		want     []SpeakerIdentificationResponse
+	}{
+		{
+			name: "every speaker assigned",
+			response: "Here is the assignment:\n```json\n" + `{"assignments": [
+				{"speaker_id": 2, "name": "Lan", "participant_id": "p2", "confidence": "high", "evidence": "I'm Lan"},
+				{"speaker_id": 1, "name": "Minh", "participant_id": "p1", "confidence": "medium", "evidence": "anh Minh nói đúng"}]}` + "\n```",
+			want: []SpeakerIdentificationResponse{
+				{SpeakerID: 1, Name: "Minh", ParticipantID: "p1", Confidence: "medium", Evidence: "anh Minh nói đúng"},
+				{SpeakerID: 2, Name: "Lan", ParticipantID: "p2", Confidence: "high", Evidence: "I'm Lan"},
+			},
+		},
+		{
+			name:     "speaker left out",
+			response: `{"assignments": [{"speaker_id": 2, "name": "Lan", "participant_id": "p2", "confidence": "high"}]}`,
+			want: []SpeakerIdentificationResponse{
+				unidentified(1, "Not identified in LLM response"),
+				{SpeakerID: 2, Name: "Lan", ParticipantID: "p2", Confidence: "high"},
+			},
+		},
+		{
+			name: "participant off the roster, duplicate and unrequested speakers",
+			response: `{"assignments": [
+				{"speaker_id": 1, "name": "Hùng", "participant_id": "p9", "confidence": "low"},
+				{"speaker_id": 1, "name": "Lan", "participant_id": "p2", "confidence": "high"},
+				{"speaker_id": 2, "name": "Lan", "participant_id": "p2", "confidence": "high"},
+				{"speaker_id": 7, "name": "Minh", "participant_id": "p1", "confidence": "high"}]}`,
+			want: []SpeakerIdentificationResponse{
+				{SpeakerID: 1, Name: "Hùng", Confidence: "low"},
+				{SpeakerID: 2, Name: "Lan", ParticipantID: "p2", Confidence: "high"},
+			},
+		},
+		{
+			name:     "no JSON object",
+			response: "I cannot tell who is speaking.",
+			want:     []SpeakerIdentificationResponse{unidentified(1, "Could not parse LLM response"), unidentified(2, "Could not parse LLM response")},
+		},
+		{
+			name:     "malformed JSON",
+			response: `{"assignments": [{"speaker_id": "one"}]}`,
+			want:     []SpeakerIdentificationResponse{unidentified(1, "Failed to parse JSON response"), unidentified(2, "Failed to parse JSON response")},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestLLMService()
+			response := s.parseConversationIdentificationResponse(tt.response, ConversationIdentificationRequest{
+				Speakers: []int{1, 2},
+				Roster:   []SessionParticipant{{ID: "p1", Name: "Minh"}, {ID: "p2", Name: "Lan"}},
+			})
+			if len(response.Assignments) != len(tt.want) {
+				t.Fatalf("assignments %+v, want %+v", response.Assignments, tt.want)
+			}
+			for i, assignment := range response.Assignments {
+				if assignment != tt.want[i] {
+					t.Errorf("assignment %d: %+v, want %+v", i, assignment, tt.want[i])
+				}
+			}
+		})
+	}
+}
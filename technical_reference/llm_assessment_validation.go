@@ -0,0 +1,367 @@
+package assessment
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Validation warning codes recorded on assessment responses
+const (
+	WarningScoreOutOfRange      = "score_out_of_range"
+	WarningMissingEvidence      = "missing_evidence"
+	WarningMissingCriterion     = "missing_criterion"
+	WarningUnknownCriterion     = "unknown_criterion"
+	WarningDuplicateParticipant = "duplicate_participant"
+	WarningMissingParticipant   = "missing_participant"
+	WarningUnknownParticipant   = "unknown_participant"
+	WarningMissingJustification = "missing_justification"
+	WarningFractionalScore      = "fractional_score"
+)
+
+// Valid score bounds for criterion and group scores (0 means N/A)
+const (
+	MinAssessmentScore = 0
+	MaxAssessmentScore = 5
+)
+
+// ValidationWarning describes a single problem found in an LLM assessment
+type ValidationWarning struct {
+	Code          string `json:"code"`
+	ParticipantID string `json:"participant_id,omitempty"`
+	CriterionID   string `json:"criterion_id,omitempty"`
+	Message       string `json:"message"`
+}
+
+// ValidationMode controls what happens when validation finds problems
+type ValidationMode string
+
+const (
+	// ValidationModeWarn keeps the response and records the warnings on it
+	ValidationModeWarn ValidationMode = "warn"
+	// ValidationModeReject discards the response and asks the LLM again
+	ValidationModeReject ValidationMode = "reject"
+)
+
+// ValidationPolicy configures how LLM assessment output is validated
+type ValidationPolicy struct {
+	Mode            ValidationMode `json:"mode"`
+	MaxRetries      int            `json:"max_retries"`      // Extra LLM calls allowed when a response is rejected
+	RequireEvidence bool           `json:"require_evidence"` // Non-zero scores must cite evidence
+	RejectCodes     []string       `json:"reject_codes"`     // Codes that trigger rejection; empty means all codes
+}
+
+// ErrAssessmentValidation is returned when every attempt was rejected by the validation policy
+var ErrAssessmentValidation = errors.New("assessment failed validation")
+
+// ErrUnparseableAssessment is returned when no attempt produced a readable assessment
+var ErrUnparseableAssessment = errors.New("unparseable assessment response")
+
+// ValidationError carries the warnings of the last rejected attempt
+type ValidationError struct {
+	Attempts int
+	Warnings []ValidationWarning
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("%v after %d attempt(s): %s", ErrAssessmentValidation, e.Attempts, summarizeWarnings(e.Warnings))
+}
+
+func (e *ValidationError) Unwrap() error {
+	return ErrAssessmentValidation
+}
+
+// DefaultValidationPolicy returns the policy configured through the environment.
+// ASSESSMENT_VALIDATION_MODE is "warn" (default) or "reject";
+// ASSESSMENT_VALIDATION_MAX_RETRIES defaults to 1.
+func DefaultValidationPolicy() ValidationPolicy {
+	policy := ValidationPolicy{
+		Mode:            ValidationModeWarn,
+		MaxRetries:      1,
+		RequireEvidence: true,
+	}
+
+	if mode := os.Getenv("ASSESSMENT_VALIDATION_MODE"); mode == string(ValidationModeReject) {
+		policy.Mode = ValidationModeReject
+	}
+	if retries, err := strconv.Atoi(os.Getenv("ASSESSMENT_VALIDATION_MAX_RETRIES")); err == nil && retries >= 0 {
+		policy.MaxRetries = retries
+	}
+
+	return policy
+}
+
+// Rejects reports whether the policy rejects a response carrying these warnings
+func (p ValidationPolicy) Rejects(warnings []ValidationWarning) bool {
+	if p.Mode != ValidationModeReject || len(warnings) == 0 {
+		return false
+	}
+	if len(p.RejectCodes) == 0 {
+		return true
+	}
+
+	for _, warning := range warnings {
+		for _, code := range p.RejectCodes {
+			if warning.Code == code {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// SetValidationPolicy overrides the validation policy used by the service
+func (s *LLMAssessmentService) SetValidationPolicy(policy ValidationPolicy) {
+	s.validationPolicy = policy
+}
+
+// validateParticipantAssessments checks parsed participant assessments against the request.
+// Scores outside the valid range are reset to 0 (N/A) so they never reach the weighted total,
+// and fractional scores are rounded to the nearest level.
+func (s *LLMAssessmentService) validateParticipantAssessments(participants []ParticipantAssessment, req AssessmentRequest) []ValidationWarning {
+	warnings := []ValidationWarning{}
+
+	criteriaByID := make(map[string]bool, len(req.Criteria))
+	for _, criterion := range req.Criteria {
+		criteriaByID[criterion.ID] = true
+	}
+	expected := make(map[string]bool, len(req.ExpectedParticipants))
+	for _, participantID := range req.ExpectedParticipants {
+		expected[participantID] = true
+	}
+
+	seen := make(map[string]bool, len(participants))
+	for i := range participants {
+		participant := &participants[i]
+
+		if seen[participant.ParticipantID] {
+			warnings = append(warnings, ValidationWarning{
+				Code:          WarningDuplicateParticipant,
+				ParticipantID: participant.ParticipantID,
+				Message:       fmt.Sprintf("participant %q appears more than once in the response", participant.ParticipantID),
+			})
+		}
+		seen[participant.ParticipantID] = true
+
+		if req.ParticipantID != "unified" && participant.ParticipantID != req.ParticipantID && participant.ParticipantName != req.ParticipantID {
+			warnings = append(warnings, ValidationWarning{
+				Code:          WarningUnknownParticipant,
+				ParticipantID: participant.ParticipantID,
+				Message:       fmt.Sprintf("response contains participant %q but %q was requested", participant.ParticipantID, req.ParticipantID),
+			})
+		}
+		if req.ParticipantID == "unified" && len(expected) > 0 && !expected[participant.ParticipantID] {
+			warnings = append(warnings, ValidationWarning{
+				Code:          WarningUnknownParticipant,
+				ParticipantID: participant.ParticipantID,
+				Message:       fmt.Sprintf("response contains participant %q who is not in the session", participant.ParticipantID),
+			})
+		}
+
+		for _, criterion := range req.Criteria {
+			if _, exists := participant.Scores[criterion.ID]; !exists {
+				warnings = append(warnings, ValidationWarning{
+					Code:          WarningMissingCriterion,
+					ParticipantID: participant.ParticipantID,
+					CriterionID:   criterion.ID,
+					Message:       fmt.Sprintf("no score for criterion %q", criterion.Name),
+				})
+			}
+		}
+
+		for criterionID, compScore := range participant.Scores {
+			if !criteriaByID[criterionID] {
+				warnings = append(warnings, ValidationWarning{
+					Code:          WarningUnknownCriterion,
+					ParticipantID: participant.ParticipantID,
+					CriterionID:   criterionID,
+					Message:       fmt.Sprintf("score key %q does not match any criterion", criterionID),
+				})
+				continue
+			}
+
+			if compScore.Score < MinAssessmentScore || compScore.Score > MaxAssessmentScore {
+				warnings = append(warnings, ValidationWarning{
+					Code:          WarningScoreOutOfRange,
+					ParticipantID: participant.ParticipantID,
+					CriterionID:   criterionID,
+					Message:       fmt.Sprintf("score %v is outside %d-%d, treated as N/A", compScore.Score, MinAssessmentScore, MaxAssessmentScore),
+				})
+				compScore.Score = 0
+				participant.Scores[criterionID] = compScore
+				continue
+			}
+			if rounded := math.Round(compScore.Score); rounded != compScore.Score {
+				warnings = append(warnings, ValidationWarning{
+					Code:          WarningFractionalScore,
+					ParticipantID: participant.ParticipantID,
+					CriterionID:   criterionID,
+					Message:       fmt.Sprintf("score %v is not a whole level, rounded to %v", compScore.Score, rounded),
+				})
+				compScore.Score = rounded
+				participant.Scores[criterionID] = compScore
+			}
+
+			if s.validationPolicy.RequireEvidence && compScore.Score > 0 && !hasEvidence(compScore.Evidence) {
+				warnings = append(warnings, ValidationWarning{
+					Code:          WarningMissingEvidence,
+					ParticipantID: participant.ParticipantID,
+					CriterionID:   criterionID,
+					Message:       fmt.Sprintf("score %v has no supporting evidence", compScore.Score),
+				})
+			}
+		}
+	}
+
+	if req.ParticipantID != "unified" && !seen[req.ParticipantID] && !containsParticipantName(participants, req.ParticipantID) {
+		warnings = append(warnings, ValidationWarning{
+			Code:          WarningMissingParticipant,
+			ParticipantID: req.ParticipantID,
+			Message:       "requested participant is missing from the response",
+		})
+	}
+	if req.ParticipantID == "unified" {
+		for _, participantID := range req.ExpectedParticipants {
+			if !seen[participantID] {
+				warnings = append(warnings, ValidationWarning{
+					Code:          WarningMissingParticipant,
+					ParticipantID: participantID,
+					Message:       "session participant is missing from the response",
+				})
+			}
+		}
+	}
+
+	return warnings
+}
+
+// validateGroupAssessment checks a parsed group assessment against the group criteria.
+// Out-of-range scores are reset to 0 (N/A) so they never reach the weighted total, and
+// fractional solution and criterion scores are rounded to the nearest level.
+func (s *LLMAssessmentService) validateGroupAssessment(assessment *GroupSolutionAssessment, req GroupAssessmentRequest) []ValidationWarning {
+	warnings := []ValidationWarning{}
+
+	if assessment.Score < MinAssessmentScore || assessment.Score > MaxAssessmentScore {
+		warnings = append(warnings, ValidationWarning{
+			Code:    WarningScoreOutOfRange,
+			Message: fmt.Sprintf("group score %v is outside %d-%d, treated as N/A", assessment.Score, MinAssessmentScore, MaxAssessmentScore),
+		})
+		assessment.Score = 0
+	}
+	if rounded := math.Round(assessment.Score); rounded != assessment.Score {
+		warnings = append(warnings, ValidationWarning{
+			Code:    WarningFractionalScore,
+			Message: fmt.Sprintf("group score %v is not a whole level, rounded to %v", assessment.Score, rounded),
+		})
+		assessment.Score = rounded
+	}
+
+	if s.validationPolicy.RequireEvidence && assessment.Score > 0 && strings.TrimSpace(assessment.ScoringJustification) == "" {
+		warnings = append(warnings, ValidationWarning{
+			Code:    WarningMissingJustification,
+			Message: fmt.Sprintf("group score %v has no scoring justification", assessment.Score),
+		})
+	}
+
+	criteriaByID := make(map[string]bool, len(req.Criteria))
+	for _, criterion := range req.Criteria {
+		criteriaByID[criterion.ID] = true
+		if _, exists := assessment.CriteriaScores[criterion.ID]; !exists {
+			warnings = append(warnings, ValidationWarning{
+				Code:        WarningMissingCriterion,
+				CriterionID: criterion.ID,
+				Message:     fmt.Sprintf("no group score for criterion %q", criterion.Name),
+			})
+		}
+	}
+
+	for criterionID, compScore := range assessment.CriteriaScores {
+		if !criteriaByID[criterionID] {
+			warnings = append(warnings, ValidationWarning{
+				Code:        WarningUnknownCriterion,
+				CriterionID: criterionID,
+				Message:     fmt.Sprintf("group score key %q does not match any criterion", criterionID),
+			})
+			continue
+		}
+
+		if compScore.Score < MinAssessmentScore || compScore.Score > MaxAssessmentScore {
+			warnings = append(warnings, ValidationWarning{
+				Code:        WarningScoreOutOfRange,
+				CriterionID: criterionID,
+				Message:     fmt.Sprintf("group score %v is outside %d-%d, treated as N/A", compScore.Score, MinAssessmentScore, MaxAssessmentScore),
+			})
+			compScore.Score = 0
+			assessment.CriteriaScores[criterionID] = compScore
+			continue
+		}
+		if rounded := math.Round(compScore.Score); rounded != compScore.Score {
+			warnings = append(warnings, ValidationWarning{
+				Code:        WarningFractionalScore,
+				CriterionID: criterionID,
+				Message:     fmt.Sprintf("group score %v is not a whole level, rounded to %v", compScore.Score, rounded),
+			})
+			compScore.Score = rounded
+			assessment.CriteriaScores[criterionID] = compScore
+		}
+
+		if s.validationPolicy.RequireEvidence && compScore.Score > 0 && !hasEvidence(compScore.Evidence) {
+			warnings = append(warnings, ValidationWarning{
+				Code:        WarningMissingEvidence,
+				CriterionID: criterionID,
+				Message:     fmt.Sprintf("group score %v has no supporting evidence", compScore.Score),
+			})
+		}
+	}
+
+	return warnings
+}
+
+// warningsForParticipant filters warnings down to a single participant
+func warningsForParticipant(warnings []ValidationWarning, participantID string) []ValidationWarning {
+	filtered := []ValidationWarning{}
+	for _, warning := range warnings {
+		if warning.ParticipantID == participantID {
+			filtered = append(filtered, warning)
+		}
+	}
+	return filtered
+}
+
+func hasEvidence(evidence []string) bool {
+	for _, item := range evidence {
+		if strings.TrimSpace(item) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func containsParticipantName(participants []ParticipantAssessment, name string) bool {
+	for _, participant := range participants {
+		if participant.ParticipantName == name {
+			return true
+		}
+	}
+	return false
+}
+
+func summarizeWarnings(warnings []ValidationWarning) string {
+	counts := make(map[string]int)
+	codes := []string{}
+	for _, warning := range warnings {
+		if counts[warning.Code] == 0 {
+			codes = append(codes, warning.Code)
+		}
+		counts[warning.Code]++
+	}
+
+	parts := make([]string, 0, len(codes))
+	for _, code := range codes {
+		parts = append(parts, fmt.Sprintf("%s x%d", code, counts[code]))
+	}
+	return strings.Join(parts, ", ")
+}
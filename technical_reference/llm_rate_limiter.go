@@ -0,0 +1,215 @@
+package assessment
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// LLM providers with independent rate limits
+const (
+	ProviderClaude = "claude"
+	ProviderGemini = "gemini"
+)
+
+// providerEnvPrefixes names each provider's rate limit environment variables
+var providerEnvPrefixes = map[string]string{
+	ProviderClaude: "ASSESSMENT_CLAUDE",
+	ProviderGemini: "ASSESSMENT_GEMINI",
+}
+
+// ProviderLimits caps the load sent to one LLM provider. Zero disables a limit.
+type ProviderLimits struct {
+	RequestsPerMinute int
+	TokensPerMinute   int // Input (prompt) tokens, estimated with EstimateTokens
+	MaxConcurrent     int
+}
+
+// RateLimitsFromEnv reads ASSESSMENT_<PROVIDER>_RPM, ASSESSMENT_<PROVIDER>_TPM and
+// ASSESSMENT_<PROVIDER>_CONCURRENCY for the claude and gemini providers
+func RateLimitsFromEnv() map[string]ProviderLimits {
+	limits := map[string]ProviderLimits{
+		ProviderClaude: {RequestsPerMinute: 50, TokensPerMinute: 80000, MaxConcurrent: 4},
+		ProviderGemini: {RequestsPerMinute: 60, TokensPerMinute: 250000, MaxConcurrent: 4},
+	}
+
+	for provider, limit := range limits {
+		prefix := providerEnvPrefixes[provider]
+		if rpm, err := strconv.Atoi(os.Getenv(prefix + "_RPM")); err == nil && rpm >= 0 {
+			limit.RequestsPerMinute = rpm
+		}
+		if tpm, err := strconv.Atoi(os.Getenv(prefix + "_TPM")); err == nil && tpm >= 0 {
+			limit.TokensPerMinute = tpm
+		}
+		if concurrency, err := strconv.Atoi(os.Getenv(prefix + "_CONCURRENCY")); err == nil && concurrency >= 0 {
+			limit.MaxConcurrent = concurrency
+		}
+		limits[provider] = limit
+	}
+	return limits
+}
+
+// EstimateTokens gives a conservative token count for prompt text. Vietnamese text
+// tokenizes at roughly one token per three characters.
+func EstimateTokens(text string) int {
+	return utf8.RuneCountInString(text)/3 + 1
+}
+
+// RateLimiter shares provider request, token and concurrency budgets between all
+// assessments in the process
+type RateLimiter struct {
+	mu        sync.Mutex
+	providers map[string]*providerLimiter
+}
+
+type providerLimiter struct {
+	limits   ProviderLimits
+	requests *tokenBucket
+	tokens   *tokenBucket
+	slots    chan struct{}
+	// pausedUntil is set when the provider answers 429
+	pausedUntil time.Time
+}
+
+// NewRateLimiter creates a limiter; providers missing from limits are unlimited
+func NewRateLimiter(limits map[string]ProviderLimits) *RateLimiter {
+	limiter := &RateLimiter{providers: make(map[string]*providerLimiter)}
+	for provider, limit := range limits {
+		p := &providerLimiter{limits: limit}
+		if limit.RequestsPerMinute > 0 {
+			p.requests = newTokenBucket(limit.RequestsPerMinute)
+		}
+		if limit.TokensPerMinute > 0 {
+			p.tokens = newTokenBucket(limit.TokensPerMinute)
+		}
+		if limit.MaxConcurrent > 0 {
+			p.slots = make(chan struct{}, limit.MaxConcurrent)
+		}
+		limiter.providers[provider] = p
+	}
+	return limiter
+}
+
+// Acquire waits until the provider has a free slot and budget for one request of
+// the given size. The caller must call release when the request finishes.
+func (l *RateLimiter) Acquire(ctx context.Context, provider string, tokens int) (release func(), err error) {
+	if l == nil {
+		return func() {}, nil
+	}
+	l.mu.Lock()
+	p, ok := l.providers[provider]
+	l.mu.Unlock()
+	if !ok {
+		return func() {}, nil
+	}
+
+	if p.slots != nil {
+		select {
+		case p.slots <- struct{}{}:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	release = func() {
+		if p.slots != nil {
+			<-p.slots
+		}
+	}
+
+	// A single request larger than the per-minute budget could never be admitted
+	if p.tokens != nil && tokens > p.limits.TokensPerMinute {
+		tokens = p.limits.TokensPerMinute
+	}
+
+	for {
+		wait := l.reserve(p, tokens)
+		if wait == 0 {
+			return release, nil
+		}
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			release()
+			return nil, fmt.Errorf("waiting for %s rate limit: %w", provider, ctx.Err())
+		}
+	}
+}
+
+// reserve takes budget for one request, or returns how long to wait before trying again
+func (l *RateLimiter) reserve(p *providerLimiter, tokens int) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	if now.Before(p.pausedUntil) {
+		return p.pausedUntil.Sub(now)
+	}
+
+	var wait time.Duration
+	if p.requests != nil {
+		wait = max(wait, p.requests.wait(now, 1))
+	}
+	if p.tokens != nil {
+		wait = max(wait, p.tokens.wait(now, float64(tokens)))
+	}
+	if wait > 0 {
+		return wait
+	}
+
+	if p.requests != nil {
+		p.requests.take(1)
+	}
+	if p.tokens != nil {
+		p.tokens.take(float64(tokens))
+	}
+	return 0
+}
+
+// Pause stops admitting requests to a provider, e.g. after a 429 with Retry-After
+func (l *RateLimiter) Pause(provider string, d time.Duration) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if p, ok := l.providers[provider]; ok {
+		if until := time.Now().Add(d); until.After(p.pausedUntil) {
+			p.pausedUntil = until
+		}
+	}
+}
+
+// tokenBucket refills continuously up to a per-minute capacity
+type tokenBucket struct {
+	capacity  float64
+	available float64
+	perSecond float64
+	updated   time.Time
+}
+
+func newTokenBucket(perMinute int) *tokenBucket {
+	return &tokenBucket{
+		capacity:  float64(perMinute),
+		available: float64(perMinute),
+		perSecond: float64(perMinute) / 60,
+		updated:   time.Now(),
+	}
+}
+
+// wait refills the bucket and returns how long until n units are available
+func (b *tokenBucket) wait(now time.Time, n float64) time.Duration {
+	b.available = min(b.capacity, b.available+now.Sub(b.updated).Seconds()*b.perSecond)
+	b.updated = now
+	if b.available >= n {
+		return 0
+	}
+	return time.Duration((n - b.available) / b.perSecond * float64(time.Second))
+}
+
+func (b *tokenBucket) take(n float64) {
+	b.available -= n
+}
@@ -0,0 +1,120 @@
+package assessment
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestTokenBucketWait(t *testing.T) {
+	start := time.Now()
+	tests := []struct {
+		name      string
+		available float64
+		elapsed   time.Duration
+		n         float64
+		want      time.Duration
+	}{
+		{name: "enough available", available: 10, n: 10},
+		{name: "short by one", available: 9, n: 10, want: time.Second},
+		{name: "refilled while idle", available: 0, elapsed: 5 * time.Second, n: 5},
+		{name: "refill capped at capacity", available: 50, elapsed: time.Hour, n: 61, want: time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 60 per minute refills one unit a second
+			bucket := newTokenBucket(60)
+			bucket.available, bucket.updated = tt.available, start
+			if got := bucket.wait(start.Add(tt.elapsed), tt.n); got != tt.want {
+				t.Errorf("wait(%v) = %s, want %s", tt.n, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRateLimiterAcquire(t *testing.T) {
+	tests := []struct {
+		name         string
+		limits       ProviderLimits
+		provider     string
+		pause        time.Duration
+		requests     []int // estimated tokens of each request, acquired without releasing
+		wantAdmitted int
+	}{
+		{name: "requests per minute", limits: ProviderLimits{RequestsPerMinute: 2}, requests: []int{1, 1, 1}, wantAdmitted: 2},
+		{name: "tokens per minute", limits: ProviderLimits{TokensPerMinute: 100}, requests: []int{60, 30, 60}, wantAdmitted: 2},
+		{name: "oversized request clamped to the budget", limits: ProviderLimits{TokensPerMinute: 100}, requests: []int{5000, 1}, wantAdmitted: 1},
+		{name: "concurrent requests", limits: ProviderLimits{MaxConcurrent: 2}, requests: []int{1, 1, 1}, wantAdmitted: 2},
+		{name: "paused after a 429", limits: ProviderLimits{RequestsPerMinute: 50}, pause: time.Minute, requests: []int{1}},
+		{name: "no limits", requests: []int{1000000, 1000000, 1000000}, wantAdmitted: 3},
+		{name: "unconfigured provider", limits: ProviderLimits{MaxConcurrent: 1}, provider: ProviderGemini, requests: []int{1, 1, 1}, wantAdmitted: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limiter := NewRateLimiter(map[string]ProviderLimits{ProviderClaude: tt.limits})
+			provider := tt.provider
+			if provider == "" {
+				provider = ProviderClaude
+			}
+			limiter.Pause(provider, tt.pause)
+
+			admitted := 0
+			for _, tokens := range tt.requests {
+				ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+				_, err := limiter.Acquire(ctx, provider, tokens)
+				cancel()
+				if errors.Is(err, context.DeadlineExceeded) {
+					break
+				} else if err != nil {
+					t.Fatal(err)
+				}
+				admitted++
+			}
+			if admitted != tt.wantAdmitted {
+				t.Errorf("admitted %d requests, want %d", admitted, tt.wantAdmitted)
+			}
+		})
+	}
+}
+
+func TestRateLimiterReleaseFreesSlot(t *testing.T) {
+	limiter := NewRateLimiter(map[string]ProviderLimits{ProviderClaude: {MaxConcurrent: 1}})
+	ctx := context.Background()
+	release, err := limiter.Acquire(ctx, ProviderClaude, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	admitted := make(chan struct{})
+	go func() {
+		if release, err := limiter.Acquire(ctx, ProviderClaude, 1); err == nil {
+			release()
+			close(admitted)
+		}
+	}()
+	select {
+	case <-admitted:
+		t.Fatal("second request admitted while the only slot was taken")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	release()
+	select {
+	case <-admitted:
+	case <-time.After(time.Second):
+		t.Fatal("second request not admitted after the slot was released")
+	}
+}
+
+func TestNilRateLimiterIsUnlimited(t *testing.T) {
+	var limiter *RateLimiter
+	limiter.Pause(ProviderClaude, time.Minute)
+	release, err := limiter.Acquire(context.Background(), ProviderClaude, 1000000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	release()
+}
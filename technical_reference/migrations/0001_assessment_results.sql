@@ -0,0 +1,51 @@
+-- Transcripts, assessment results and async transcription jobs.
+-- Portable between PostgreSQL and SQLite; JSON payloads are stored as TEXT.
+
+CREATE TABLE IF NOT EXISTS assessment_transcripts (
+    id               TEXT PRIMARY KEY,
+    session_id       TEXT NOT NULL,
+    participant_id   TEXT NOT NULL,
+    participant_name TEXT NOT NULL DEFAULT '',
+    role             TEXT NOT NULL DEFAULT '',
+    transcript       TEXT NOT NULL,
+    client_timestamp BIGINT NOT NULL DEFAULT 0,
+    received_at      TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_assessment_transcripts_session
+    ON assessment_transcripts (session_id, received_at);
+
+CREATE TABLE IF NOT EXISTS assessment_participant_results (
+    session_id     TEXT NOT NULL,
+    participant_id TEXT NOT NULL,
+    overall_score  DOUBLE PRECISION NOT NULL,
+    summary        TEXT NOT NULL DEFAULT '',
+    results_json   TEXT NOT NULL,
+    warnings_json  TEXT NOT NULL DEFAULT '[]',
+    updated_at     TIMESTAMP NOT NULL,
+    PRIMARY KEY (session_id, participant_id)
+);
+
+CREATE TABLE IF NOT EXISTS assessment_group_results (
+    session_id     TEXT PRIMARY KEY,
+    overall_score  DOUBLE PRECISION NOT NULL,
+    solution_score INTEGER NOT NULL,
+    payload_json   TEXT NOT NULL,
+    updated_at     TIMESTAMP NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS assessment_consolidated_results (
+    session_id   TEXT PRIMARY KEY,
+    result_type  TEXT NOT NULL DEFAULT '',
+    payload_json TEXT NOT NULL,
+    updated_at   TIMESTAMP NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS transcription_jobs (
+    session_id              TEXT PRIMARY KEY,
+    transcription_id        TEXT NOT NULL,
+    file_id                 TEXT NOT NULL,
+    speaker_mapping_json    TEXT NOT NULL DEFAULT '{}',
+    manual_corrections_json TEXT NOT NULL DEFAULT '{}',
+    created_at              TIMESTAMP NOT NULL
+);
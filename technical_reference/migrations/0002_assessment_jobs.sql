@@ -0,0 +1,23 @@
+-- Durable queue for background assessment jobs.
+-- Jobs that exhaust their attempts stay in the table with status 'dead_letter'.
+
+CREATE TABLE IF NOT EXISTS assessment_jobs (
+    id             TEXT PRIMARY KEY,
+    job_type       TEXT NOT NULL,
+    session_id     TEXT NOT NULL,
+    participant_id TEXT NOT NULL DEFAULT '',
+    payload_json   TEXT NOT NULL,
+    status         TEXT NOT NULL,
+    attempts       INTEGER NOT NULL DEFAULT 0,
+    max_attempts   INTEGER NOT NULL,
+    run_at         TIMESTAMP NOT NULL,
+    last_error     TEXT NOT NULL DEFAULT '',
+    created_at     TIMESTAMP NOT NULL,
+    updated_at     TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_assessment_jobs_ready
+    ON assessment_jobs (status, run_at);
+
+CREATE INDEX IF NOT EXISTS idx_assessment_jobs_session
+    ON assessment_jobs (session_id);
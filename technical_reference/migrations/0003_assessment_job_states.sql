@@ -0,0 +1,10 @@
+-- Explicit job state machine: start/finish timestamps and per-attempt errors.
+-- Jobs parked as 'dead_letter' by the first queue version become 'failed'.
+
+ALTER TABLE assessment_jobs ADD COLUMN started_at TIMESTAMP;
+
+ALTER TABLE assessment_jobs ADD COLUMN finished_at TIMESTAMP;
+
+ALTER TABLE assessment_jobs ADD COLUMN errors_json TEXT NOT NULL DEFAULT '[]';
+
+UPDATE assessment_jobs SET status = 'failed', finished_at = updated_at WHERE status = 'dead_letter';
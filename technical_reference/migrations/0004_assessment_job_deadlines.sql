@@ -0,0 +1,3 @@
+-- Per-attempt deadline, used to recover running jobs orphaned by a stopped process.
+
+ALTER TABLE assessment_jobs ADD COLUMN deadline_at TIMESTAMP;
@@ -0,0 +1,3 @@
+-- Record when an async transcription finished so completion is announced once.
+
+ALTER TABLE transcription_jobs ADD COLUMN completed_at TIMESTAMP;
@@ -0,0 +1,32 @@
+-- Webhook subscriptions and their delivery log.
+-- Secrets are stored as issued because deliveries are signed with them.
+
+CREATE TABLE IF NOT EXISTS webhook_subscriptions (
+    id          TEXT PRIMARY KEY,
+    client_id   TEXT NOT NULL DEFAULT '',
+    url         TEXT NOT NULL,
+    secret      TEXT NOT NULL,
+    events_json TEXT NOT NULL DEFAULT '[]',
+    active      BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at  TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_subscriptions_client
+    ON webhook_subscriptions (client_id);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id              TEXT PRIMARY KEY,
+    subscription_id TEXT NOT NULL,
+    event_id        TEXT NOT NULL,
+    event_type      TEXT NOT NULL,
+    session_id      TEXT NOT NULL DEFAULT '',
+    attempt         INTEGER NOT NULL,
+    status_code     INTEGER NOT NULL DEFAULT 0,
+    error           TEXT NOT NULL DEFAULT '',
+    duration_ms     BIGINT NOT NULL DEFAULT 0,
+    succeeded       BOOLEAN NOT NULL,
+    delivered_at    TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_subscription
+    ON webhook_deliveries (subscription_id, delivered_at);
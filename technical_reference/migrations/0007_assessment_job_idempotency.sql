@@ -0,0 +1,3 @@
+-- Idempotency key (client supplied or payload hash) used to deduplicate transcript syncs.
+
+ALTER TABLE assessment_jobs ADD COLUMN idempotency_key TEXT NOT NULL DEFAULT '';
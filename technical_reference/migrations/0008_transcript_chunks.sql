@@ -0,0 +1,13 @@
+-- Append-only live transcript deltas, keyed by the client's timestamp so resent
+-- chunks are stored once.
+
+CREATE TABLE IF NOT EXISTS assessment_transcript_chunks (
+    session_id       TEXT NOT NULL,
+    participant_id   TEXT NOT NULL,
+    client_timestamp BIGINT NOT NULL,
+    participant_name TEXT NOT NULL DEFAULT '',
+    role             TEXT NOT NULL DEFAULT '',
+    text             TEXT NOT NULL,
+    received_at      TIMESTAMP NOT NULL,
+    PRIMARY KEY (session_id, participant_id, client_timestamp)
+);
@@ -0,0 +1,19 @@
+-- Every participant assessment run is kept. The participant results table holds the
+-- official run: the newest one, or the run a reviewer pinned.
+
+CREATE TABLE IF NOT EXISTS assessment_runs (
+    id             TEXT PRIMARY KEY,
+    session_id     TEXT NOT NULL,
+    participant_id TEXT NOT NULL,
+    payload_json   TEXT NOT NULL,
+    created_at     TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_assessment_runs_participant
+    ON assessment_runs (session_id, participant_id, created_at);
+
+ALTER TABLE assessment_participant_results ADD COLUMN run_id TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE assessment_participant_results ADD COLUMN pinned BOOLEAN NOT NULL DEFAULT FALSE;
+
+ALTER TABLE assessment_participant_results ADD COLUMN provenance_json TEXT NOT NULL DEFAULT 'null';
@@ -0,0 +1,9 @@
+-- Track async transcription status and keep the finished transcript, so Soniox's
+-- completion callback is authenticated per job and status reads stay local.
+
+-- Jobs finished before this migration have no stored transcript and stay 'processing',
+-- so the next status read fetches it from Soniox once.
+ALTER TABLE transcription_jobs ADD COLUMN status TEXT NOT NULL DEFAULT 'processing';
+ALTER TABLE transcription_jobs ADD COLUMN error_message TEXT NOT NULL DEFAULT '';
+ALTER TABLE transcription_jobs ADD COLUMN webhook_secret TEXT NOT NULL DEFAULT '';
+ALTER TABLE transcription_jobs ADD COLUMN tokens_json TEXT NOT NULL DEFAULT '[]';
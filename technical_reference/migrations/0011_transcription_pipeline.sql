@@ -0,0 +1,5 @@
+-- The roster an async transcript's speakers are bound to, and the consolidated
+-- assessment queued automatically once the transcript is stored.
+
+ALTER TABLE transcription_jobs ADD COLUMN participants_json TEXT NOT NULL DEFAULT '[]';
+ALTER TABLE transcription_jobs ADD COLUMN assessment_job_id TEXT NOT NULL DEFAULT '';
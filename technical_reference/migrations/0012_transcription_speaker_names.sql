@@ -0,0 +1,5 @@
+-- Names the server resolves for every diarized speaker of a finished async transcript,
+-- and when it did, so the transcript is named once before its assessment is queued.
+
+ALTER TABLE transcription_jobs ADD COLUMN speaker_names_json TEXT NOT NULL DEFAULT '[]';
+ALTER TABLE transcription_jobs ADD COLUMN speakers_named_at TIMESTAMP;
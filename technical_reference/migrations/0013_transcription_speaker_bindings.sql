@@ -0,0 +1,3 @@
+-- Speaker numbers of an async transcript bound to participant IDs on the session roster.
+
+ALTER TABLE transcription_jobs ADD COLUMN speaker_bindings_json TEXT NOT NULL DEFAULT '{}';
@@ -0,0 +1,19 @@
+-- Deletion of async transcription files and transcriptions from the transcriber, and
+-- the deletion log kept for compliance after the jobs themselves are replaced.
+
+ALTER TABLE transcription_jobs ADD COLUMN file_deleted_at TIMESTAMP;
+ALTER TABLE transcription_jobs ADD COLUMN transcription_deleted_at TIMESTAMP;
+
+CREATE TABLE IF NOT EXISTS transcription_deletions (
+    id         TEXT PRIMARY KEY,
+    session_id TEXT NOT NULL,
+    client_id  TEXT NOT NULL DEFAULT '',
+    provider   TEXT NOT NULL,
+    resource   TEXT NOT NULL,
+    remote_id  TEXT NOT NULL,
+    reason     TEXT NOT NULL,
+    deleted_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_transcription_deletions_session
+    ON transcription_deletions (session_id, deleted_at);
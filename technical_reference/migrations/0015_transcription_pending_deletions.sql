@@ -0,0 +1,12 @@
+-- Remote transcription data no job refers to any more (replaced jobs, submissions that
+-- failed after uploading), kept until its deletion is logged in transcription_deletions.
+
+CREATE TABLE IF NOT EXISTS transcription_pending_deletions (
+    id         TEXT PRIMARY KEY,
+    session_id TEXT NOT NULL,
+    resource   TEXT NOT NULL,
+    remote_id  TEXT NOT NULL,
+    reason     TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    UNIQUE (resource, remote_id)
+);
@@ -0,0 +1,6 @@
+-- Worker leases on running jobs, renewed while the job runs, so that an instance only
+-- recovers jobs whose worker stopped renewing rather than jobs another instance is running.
+
+ALTER TABLE assessment_jobs ADD COLUMN lease_owner TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE assessment_jobs ADD COLUMN lease_expires_at TIMESTAMP;
@@ -1,17 +1,21 @@
 package assessment
 
 import (
-	"bytes"
+	"bufio"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
-	"log"
-	"mime/multipart"
+	"log/slog"
 	"net/http"
 	"os"
+	"sort"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
+	"unicode/utf8"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -21,15 +25,291 @@ import (
 // SonioxHandler handles Soniox speech-to-text integration
 type SonioxHandler struct{
 	llmService *assessmentService.LLMAssessmentService
-	// In-memory store for latest assessment results
-	assessmentResults sync.Map // key: sessionID_participantID, value: *AssessmentResponse
+	logger     *assessmentService.AssessmentLogger
+	// Store for transcripts, assessment results and transcription jobs
+	results assessmentService.ResultRepository
+	// Bounded worker pool for background assessments and webhook deliveries
+	jobs *assessmentService.JobQueue
+	// Assessment and transcription events, fanned out to webhooks
+	events   *assessmentService.EventBus
+	webhooks *assessmentService.WebhookDispatcher
+	// Recent events per session for live monitoring streams
+	stream *assessmentService.EventStream
+	// Participants assessed at once within one consolidated job
+	participantParallelism int
+	// Hold back re-assessment of live transcripts until they have grown meaningfully
+	transcriptDebounce   *assessmentService.TranscriptDebouncer
+	consolidatedDebounce *assessmentService.TranscriptDebouncer
+	// Latest deferred consolidated sync per session, assessed once the session goes quiet
+	deferredConsolidated sync.Map
+	// Public base URL Soniox calls back when an async transcription finishes; empty
+	// falls back to checking Soniox from status reads
+	sonioxWebhookBaseURL string
+	// Speech-to-text backend for realtime keys and async transcription
+	transcriber assessmentService.Transcriber
+	// Last time each session's async transcription status was checked with the backend
+	transcriptionChecks sync.Map
+	// Size and duration bounds on async transcription uploads
+	audioLimits assessmentService.AudioLimits
+	// Default splitting of async transcripts into segments
+	segmentation assessmentService.SegmentationConfig
+	// Authoritative session rosters, if configured
+	sessionRoster assessmentService.SessionRosterResolver
+	// Deletes uploaded audio and transcripts from the transcriber per client retention policy
+	retention *assessmentService.TranscriptionRetention
+	// Case study vocabularies, and the session's from the system of record if configured
+	vocabulary        assessmentService.VocabularyConfig
+	sessionVocabulary assessmentService.SessionVocabularyResolver
 }
 
-// NewSonioxHandler creates a new Soniox handler
+// NewSonioxHandler creates a new Soniox handler backed by in-memory result and job stores
 func NewSonioxHandler() *SonioxHandler {
-	return &SonioxHandler{
-		llmService: assessmentService.NewLLMAssessmentService(),
+	return NewSonioxHandlerWithRepository(assessmentService.NewMemoryResultRepository())
+}
+
+// NewSonioxHandlerWithRepository creates a new Soniox handler backed by the given result store
+func NewSonioxHandlerWithRepository(results assessmentService.ResultRepository) *SonioxHandler {
+	return NewSonioxHandlerWithStores(results, assessmentService.NewMemoryJobStore())
+}
+
+// NewSonioxHandlerWithStores creates a new Soniox handler backed by the given result and job stores
+// and starts the assessment workers
+func NewSonioxHandlerWithStores(results assessmentService.ResultRepository, jobStore assessmentService.JobStore) *SonioxHandler {
+	return NewSonioxHandlerWithConfig(SonioxHandlerConfig{Results: results, Jobs: jobStore})
+}
+
+// SonioxHandlerConfig selects the stores behind a Soniox handler. Nil stores are in-memory.
+type SonioxHandlerConfig struct {
+	Results  assessmentService.ResultRepository
+	Jobs     assessmentService.JobStore
+	Webhooks assessmentService.WebhookStore
+	// SessionClient maps a session to the client whose webhooks receive its events;
+	// without it no webhooks are delivered
+	SessionClient assessmentService.SessionClientResolver
+	// Transcriber defaults to the backend chosen by TRANSCRIBER
+	Transcriber assessmentService.Transcriber
+	// SessionRoster returns a session's participants for checking speaker bindings; when
+	// nil, bindings are checked against the submitted or live transcript roster
+	SessionRoster assessmentService.SessionRosterResolver
+	// SessionVocabulary returns a session's case study and vocabulary for transcription;
+	// when nil, sessions use the case study they are submitted with or the default
+	SessionVocabulary assessmentService.SessionVocabularyResolver
+}
+
+// NewSonioxHandlerWithConfig creates a new Soniox handler and starts the assessment workers
+func NewSonioxHandlerWithConfig(config SonioxHandlerConfig) *SonioxHandler {
+	if config.Results == nil {
+		config.Results = assessmentService.NewMemoryResultRepository()
+	}
+	if config.Jobs == nil {
+		config.Jobs = assessmentService.NewMemoryJobStore()
+	}
+	if config.Webhooks == nil {
+		config.Webhooks = assessmentService.NewMemoryWebhookStore()
+	}
+	if config.Transcriber == nil {
+		config.Transcriber = assessmentService.TranscriberFromEnv()
+	}
+
+	llmService := assessmentService.NewLLMAssessmentService()
+	events := assessmentService.NewEventBus()
+	h := &SonioxHandler{
+		llmService: llmService,
+		logger:     llmService.Logger(),
+		results:    assessmentService.NewPublishingResultRepository(config.Results, events),
+		events:     events,
+		stream:     assessmentService.NewEventStream(200, 2*time.Hour),
+		participantParallelism: 5,
+		sonioxWebhookBaseURL:   strings.TrimRight(os.Getenv("SONIOX_WEBHOOK_BASE_URL"), "/"),
+		audioLimits:            assessmentService.AudioLimitsFromEnv(),
+		segmentation:           assessmentService.SegmentationConfigFromEnv(),
+		transcriber:            config.Transcriber,
+		sessionRoster:          config.SessionRoster,
+		sessionVocabulary:      config.SessionVocabulary,
+	}
+	if parallelism, err := strconv.Atoi(os.Getenv("ASSESSMENT_PARTICIPANT_PARALLELISM")); err == nil && parallelism > 0 {
+		h.participantParallelism = parallelism
+	}
+
+	h.jobs = assessmentService.NewJobQueue(config.Jobs, assessmentService.QueueConfigFromEnv(), h.logger)
+	h.jobs.Register(assessmentService.JobTypeParticipantAssessment, h.runParticipantAssessmentJob)
+	h.jobs.Register(assessmentService.JobTypeConsolidatedAssessment, h.runConsolidatedAssessmentJob)
+	h.jobs.Register(assessmentService.JobTypeSpeakerNaming, h.runSpeakerNamingJob)
+	h.webhooks = assessmentService.NewWebhookDispatcher(config.Webhooks, h.jobs, config.SessionClient, assessmentService.WebhookConfigFromEnv(), h.logger)
+	events.Subscribe(h.webhooks)
+	events.Subscribe(h.stream)
+	h.jobs.OnTransition(h.publishJobFailure)
+	h.jobs.OnTransition(h.resetFailedDebounce)
+
+	debounce := assessmentService.DebounceConfigFromEnv()
+	h.transcriptDebounce = assessmentService.NewTranscriptDebouncer(debounce, h.assessIdleTranscript)
+	h.consolidatedDebounce = assessmentService.NewTranscriptDebouncer(debounce, h.assessIdleConsolidated)
+	h.jobs.Start(context.Background())
+
+	retention, err := assessmentService.RetentionConfigFromEnv()
+	if err != nil {
+		h.logger.Warn("ignoring per-client retention settings", slog.String("error", err.Error()))
+	}
+	h.retention = assessmentService.NewTranscriptionRetention(h.results, h.transcriber, retention, config.SessionClient, h.logger)
+	h.retention.Start(context.Background())
+
+	h.vocabulary, err = assessmentService.VocabularyConfigFromEnv()
+	if err != nil {
+		h.logger.Warn("ignoring case study vocabulary settings", slog.String("error", err.Error()))
+	}
+
+	return h
+}
+
+// Webhooks returns the dispatcher that manages webhook subscriptions
+func (h *SonioxHandler) Webhooks() *assessmentService.WebhookDispatcher {
+	return h.webhooks
+}
+
+// publishJobFailure announces assessment jobs that exhausted their retries. Failed
+// webhook deliveries are not announced, which would only queue more deliveries.
+func (h *SonioxHandler) publishJobFailure(ctx context.Context, job *assessmentService.Job) {
+	if job.Status != assessmentService.JobStatusFailed || job.Type == assessmentService.JobTypeWebhookDelivery {
+		return
+	}
+	h.events.Publish(ctx, assessmentService.NewAssessmentEvent(assessmentService.EventJobFailed, job.SessionID, job))
+}
+
+// resetFailedDebounce lets a transcript whose latest assessment failed or was cancelled
+// be assessed again by its next sync, even if it has not grown since
+func (h *SonioxHandler) resetFailedDebounce(ctx context.Context, job *assessmentService.Job) {
+	if job.Status != assessmentService.JobStatusFailed && job.Status != assessmentService.JobStatusCancelled {
+		return
+	}
+	var debounce *assessmentService.TranscriptDebouncer
+	switch job.Type {
+	case assessmentService.JobTypeParticipantAssessment:
+		debounce = h.transcriptDebounce
+	case assessmentService.JobTypeConsolidatedAssessment:
+		debounce = h.consolidatedDebounce
+	default:
+		return
+	}
+	// A job cancelled because a newer one replaced it leaves the newer one in charge
+	if latest, err := h.jobs.Latest(ctx, job.SessionID, job.Type, job.ParticipantID); err != nil || latest.ID != job.ID {
+		return
+	}
+	debounce.Reset(job.SessionID, job.ParticipantID)
+}
+
+// Shutdown stops background assessment workers, waiting for in-flight jobs until ctx
+// expires and then requeueing whatever is still running
+func (h *SonioxHandler) Shutdown(ctx context.Context) error {
+	retentionErr := h.retention.Shutdown(ctx)
+	if err := h.jobs.Shutdown(ctx); err != nil {
+		return err
+	}
+	return retentionErr
+}
+
+// participantAssessmentPayload is the queued payload for a single participant assessment
+type participantAssessmentPayload struct {
+	Transcript string `json:"transcript"`
+}
+
+// consolidatedAssessmentPayload is the queued payload for a consolidated session assessment
+type consolidatedAssessmentPayload struct {
+	Conversation       []ConsolidatedTranscriptParticipant `json:"conversation"`
+	ParticipantMapping []ParticipantMapping               `json:"participant_mapping"`
+	// FullConversation is the conversation in speaking order, for transcribed recordings;
+	// otherwise it is built from Conversation one participant after another
+	FullConversation string `json:"full_conversation,omitempty"`
+}
+
+// speakerNamingPayload is the job payload of speaker naming
+type speakerNamingPayload struct {
+	TranscriptionID string `json:"transcription_id"`
+}
+
+// runParticipantAssessmentJob decodes a queued participant assessment and runs it
+func (h *SonioxHandler) runParticipantAssessmentJob(ctx context.Context, job *assessmentService.Job) error {
+	var payload participantAssessmentPayload
+	if err := json.Unmarshal(job.Payload, &payload); err != nil {
+		return fmt.Errorf("invalid job payload: %w", err)
+	}
+	return h.processAssessmentInBackground(ctx, job.SessionID, job.ParticipantID, payload.Transcript)
+}
+
+// runConsolidatedAssessmentJob decodes a queued consolidated assessment and runs it
+func (h *SonioxHandler) runConsolidatedAssessmentJob(ctx context.Context, job *assessmentService.Job) error {
+	var payload consolidatedAssessmentPayload
+	if err := json.Unmarshal(job.Payload, &payload); err != nil {
+		return fmt.Errorf("invalid job payload: %w", err)
+	}
+	return h.processConsolidatedAssessmentInBackground(ctx, job.SessionID, payload.Conversation, payload.ParticipantMapping, payload.FullConversation)
+}
+
+// runSpeakerNamingJob names the speakers of a session's finished async transcript, unless
+// that has been done, and queues its assessment. A job for a transcription since
+// replaced has nothing to do.
+func (h *SonioxHandler) runSpeakerNamingJob(ctx context.Context, job *assessmentService.Job) error {
+	var payload speakerNamingPayload
+	if err := json.Unmarshal(job.Payload, &payload); err != nil {
+		return fmt.Errorf("invalid job payload: %w", err)
+	}
+	transcription, err := h.results.GetTranscriptionJob(ctx, job.SessionID)
+	if err != nil {
+		return err
+	}
+	if transcription.TranscriptionID != payload.TranscriptionID || transcription.Status != assessmentService.TranscriptionCompleted {
+		return nil
+	}
+
+	logger := h.logger.ForSession(job.SessionID).With(slog.String("transcription_id", transcription.TranscriptionID))
+	if transcription.SpeakersNamedAt == nil {
+		if err := h.nameSpeakers(ctx, logger, transcription); err != nil {
+			return err
+		}
+	}
+	_, err = h.queuePipelineAssessment(ctx, logger, transcription)
+	return err
+}
+
+// respondEnqueueError maps a job queue error to an HTTP response
+func respondEnqueueError(c *gin.Context, err error) {
+	if errors.Is(err, assessmentService.ErrQueueFull) {
+		c.Header("Retry-After", "30")
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "assessment queue is full, retry later"})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to queue assessment"})
+}
+
+// respondDuplicateSubmission answers a repeated transcript sync with the job the first
+// submission created
+func respondDuplicateSubmission(c *gin.Context, job *assessmentService.Job) {
+	c.Header("Idempotent-Replayed", "true")
+	c.JSON(http.StatusOK, gin.H{
+		"status":     "duplicate",
+		"message":    "Transcript already received, returning the existing assessment job",
+		"job_id":     job.ID,
+		"job_status": job.Status,
+	})
+}
+
+// findDuplicateSubmission looks up the job of an earlier identical sync. It reports
+// whether the response has been written.
+func (h *SonioxHandler) findDuplicateSubmission(c *gin.Context, logger *assessmentService.AssessmentLogger, jobType, sessionID, participantID string, payload any) bool {
+	job, err := h.jobs.FindIdempotent(c.Request.Context(), jobType, sessionID, participantID, c.GetHeader("Idempotency-Key"), payload)
+	switch {
+	case err == nil:
+		logger.Info("duplicate transcript sync", slog.String("job_id", job.ID))
+		respondDuplicateSubmission(c, job)
+		return true
+	case errors.Is(err, assessmentService.ErrIdempotencyKeyReused):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return true
+	case !errors.Is(err, assessmentService.ErrJobNotFound):
+		logger.Error("failed to check for duplicate transcript sync", slog.String("error", err.Error()))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to queue assessment"})
+		return true
 	}
+	return false
 }
 
 // getComprehensiveAssessmentCriteria returns the full assessment criteria with all details
@@ -397,7 +677,7 @@ func (h *SonioxHandler) GetTemporaryKey(c *gin.Context) {
 	if candidateToken != "" {
 		// TODO: Validate candidate token here
 		// For now, just allow if token exists
-		log.Printf("Soniox key request from candidate with token")
+		h.logger.Info("Soniox key request from candidate with token")
 	} else {
 		// Otherwise, auth middleware should have validated JWT
 		// Check if user exists in context (set by auth middleware)
@@ -422,84 +702,38 @@ func (h *SonioxHandler) GetTemporaryKey(c *gin.Context) {
 		}
 	}
 
-	// Get Soniox API key from environment
-	sonioxAPIKey := os.Getenv("SONIOX_API_KEY")
-	if sonioxAPIKey == "" {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Soniox API key not configured"})
-		return
-	}
-
-	// Create temporary key request (valid for 2 hours = 7200 seconds)
-	tempKeyReq := SonioxTempKeyRequest{
-		UsageLimitSeconds: 7200,
-	}
-
-	reqBody, err := json.Marshal(tempKeyReq)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to marshal request"})
-		return
-	}
-
-	// Create proper request body for Soniox API
-	reqBodyMap := map[string]interface{}{
-		"usage_type":         "transcribe_websocket",
-		"expires_in_seconds": 3600, // 1 hour
-	}
-
-	reqBody, err = json.Marshal(reqBodyMap)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to marshal request"})
-		return
-	}
-
-	// Make request to Soniox API
-	req, err := http.NewRequest("POST", "https://api.soniox.com/v1/auth/temporary-api-key", bytes.NewBuffer(reqBody))
+	// The realtime client configures recognition itself, so it is given the session's vocabulary
+	vocabulary, err := h.transcriptionVocabulary(c.Request.Context(), sessionID, assessmentService.SessionVocabulary{
+		CaseStudyID:   c.Query("case_study"),
+		LanguageHints: c.QueryArray("language_hints"),
+		Terms:         c.QueryArray("vocabulary"),
+	})
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create request"})
+		respondVocabularyError(c, h.logger.ForSession(sessionID), err)
 		return
 	}
 
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+sonioxAPIKey)
-
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to call Soniox API"})
+	// Create a temporary key valid for 1 hour
+	apiKey, err := h.transcriber.TemporaryKey(c.Request.Context(), time.Hour)
+	if errors.Is(err, assessmentService.ErrTranscriberNotConfigured) {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Transcription API key not configured"})
 		return
-	}
-	defer resp.Body.Close()
-
-	// Check for success status codes (200 OK or 201 Created)
-	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
-		// Read error response for debugging
-		var errorBody map[string]interface{}
-		json.NewDecoder(resp.Body).Decode(&errorBody)
-		fmt.Printf("Soniox API error - Status: %d, Body: %+v\n", resp.StatusCode, errorBody)
+	} else if err != nil {
+		h.logger.Error("temporary key request failed", slog.String("transcriber", h.transcriber.Name()), slog.String("error", err.Error()))
 		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": fmt.Sprintf("Soniox API returned error: %d", resp.StatusCode),
-			"details": errorBody,
+			"error":   "Failed to create temporary key",
+			"details": err.Error(),
 		})
 		return
 	}
 
-	var sonioxResp map[string]interface{}
-	if err := json.NewDecoder(resp.Body).Decode(&sonioxResp); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to decode Soniox response"})
-		return
-	}
-
-	// Extract api_key from response
-	apiKey, ok := sonioxResp["api_key"].(string)
-	if !ok {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid response format from Soniox"})
-		return
-	}
-
 	// Return the temporary key
 	c.JSON(http.StatusOK, gin.H{
-		"apiKey":     apiKey,
-		"sessionId":  sessionID,
+		"apiKey":        apiKey,
+		"sessionId":     sessionID,
+		"caseStudy":     vocabulary.CaseStudyID,
+		"languageHints": vocabulary.LanguageHints,
+		"context":       vocabulary.Context(),
 	})
 }
 
@@ -509,6 +743,8 @@ type TranscriptSyncRequest struct {
 	Transcript    string `json:"transcript" binding:"required"`
 	SessionID     string `json:"session_id" binding:"required"`
 	Timestamp     int64  `json:"timestamp"`
+	// Supersede cancels this participant's unfinished assessment, e.g. for a corrected transcript
+	Supersede bool `json:"supersede"`
 }
 
 // ConsolidatedTranscriptParticipant represents a participant in the consolidated transcript
@@ -533,6 +769,23 @@ type ConsolidatedTranscriptSyncRequest struct {
 	Conversation        []ConsolidatedTranscriptParticipant `json:"conversation" binding:"required"`
 	ParticipantMapping  []ParticipantMapping               `json:"participant_mapping"`
 	Timestamp           int64                               `json:"timestamp"`
+	// Supersede cancels the session's unfinished consolidated assessment, e.g. for a corrected transcript
+	Supersede           bool                                `json:"supersede"`
+	// Final marks the last sync of a recording; it is assessed without waiting for more growth
+	Final               bool                                `json:"final"`
+}
+
+// TranscriptDeltaRequest is one chunk of a participant's live transcript. Chunks are
+// keyed by Timestamp, so resending a chunk is harmless.
+type TranscriptDeltaRequest struct {
+	SessionID       string `json:"session_id" binding:"required"`
+	ParticipantID   string `json:"participant_id" binding:"required"`
+	ParticipantName string `json:"participant_name"`
+	Role            string `json:"role"`
+	Text            string `json:"text"`
+	Timestamp       int64  `json:"timestamp" binding:"required"`
+	// Final marks the participant's last chunk; it is assessed without waiting for more growth
+	Final bool `json:"final"`
 }
 
 // SyncTranscript receives and processes transcript data from frontend
@@ -554,18 +807,52 @@ func (h *SonioxHandler) SyncTranscript(c *gin.Context) {
 	// For demo, allow numeric participant IDs
 	// In production, this should be UUID
 
-	// TODO: Store transcript in database
-	// For demo purposes, we'll just log it and return success
-	fmt.Printf("Received transcript for session %s, participant %s: %s\n", 
-		req.SessionID, req.ParticipantID, req.Transcript)
+	logger := h.logger.ForParticipant(req.SessionID, req.ParticipantID)
+	logger.Info("transcript received", assessmentService.RedactedText("transcript", req.Transcript))
+
+	// Retried submissions return the job the first one started
+	payload := participantAssessmentPayload{Transcript: req.Transcript}
+	if h.findDuplicateSubmission(c, logger, assessmentService.JobTypeParticipantAssessment, req.SessionID, req.ParticipantID, payload) {
+		return
+	}
+
+	// Store transcript
+	if err := h.results.SaveTranscript(c.Request.Context(), assessmentService.TranscriptRecord{
+		SessionID:       req.SessionID,
+		ParticipantID:   req.ParticipantID,
+		Transcript:      req.Transcript,
+		ClientTimestamp: req.Timestamp,
+		ReceivedAt:      time.Now().UTC(),
+	}); err != nil {
+		logger.Error("failed to store transcript", slog.String("error", err.Error()))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to store transcript"})
+		return
+	}
+
+	if req.Supersede {
+		if _, err := h.jobs.CancelMatching(c.Request.Context(), req.SessionID, assessmentService.JobTypeParticipantAssessment, req.ParticipantID); err != nil {
+			logger.Error("failed to cancel superseded assessment", slog.String("error", err.Error()))
+		}
+	}
 
-	// Trigger background assessment processing
-	go h.processAssessmentInBackground(req.SessionID, req.ParticipantID, req.Transcript)
+	// Queue background assessment processing
+	job, duplicate, err := h.jobs.EnqueueIdempotent(c.Request.Context(), assessmentService.JobTypeParticipantAssessment,
+		req.SessionID, req.ParticipantID, c.GetHeader("Idempotency-Key"), payload)
+	if err != nil {
+		logger.Error("failed to queue assessment", slog.String("error", err.Error()))
+		respondEnqueueError(c, err)
+		return
+	}
+	if duplicate {
+		respondDuplicateSubmission(c, job)
+		return
+	}
 
 	// Return success
 	c.JSON(http.StatusOK, gin.H{
 		"status": "received",
-		"message": "Transcript received and assessment processing started",
+		"message": "Transcript received and assessment queued",
+		"job_id": job.ID,
 	})
 }
 
@@ -634,8 +921,9 @@ func (h *SonioxHandler) ProcessAssessment(c *gin.Context) {
 }
 
 // processAssessmentInBackground processes assessment in background and stores result
-func (h *SonioxHandler) processAssessmentInBackground(sessionID, participantID, transcript string) {
-	fmt.Printf("Starting background assessment processing for session %s, participant %s\n", sessionID, participantID)
+func (h *SonioxHandler) processAssessmentInBackground(ctx context.Context, sessionID, participantID, transcript string) error {
+	logger := h.logger.ForParticipant(sessionID, participantID)
+	logger.Info("background assessment started")
 	
 	// Use comprehensive assessment criteria
 	criteria := getComprehensiveAssessmentCriteria()
@@ -650,17 +938,18 @@ func (h *SonioxHandler) processAssessmentInBackground(sessionID, participantID,
 	}
 
 	// Process assessment using LLM service
-	result, err := h.llmService.ProcessAssessment(context.Background(), assessmentReq)
+	result, err := h.llmService.ProcessAssessment(ctx, assessmentReq)
 	if err != nil {
-		fmt.Printf("Error processing assessment in background: %v\n", err)
-		return
+		return fmt.Errorf("assessment failed: %w", err)
 	}
 
-	// Store result in memory
-	key := fmt.Sprintf("%s_%s", sessionID, participantID)
-	h.assessmentResults.Store(key, result)
+	// Store result
+	if err := h.results.SaveParticipantResult(ctx, result); err != nil {
+		return fmt.Errorf("failed to store assessment result: %w", err)
+	}
 	
-	fmt.Printf("Assessment processing completed and stored for session %s, participant %s\n", sessionID, participantID)
+	logger.Info("background assessment stored")
+	return nil
 }
 
 // GetAssessmentResults retrieves the latest assessment results for polling
@@ -681,274 +970,257 @@ func (h *SonioxHandler) GetAssessmentResults(c *gin.Context) {
 		}
 	}
 
-	key := fmt.Sprintf("%s_%s", sessionID, participantID)
-	if result, ok := h.assessmentResults.Load(key); ok {
-		if assessmentResult, ok := result.(*assessmentService.AssessmentResponse); ok {
-			c.JSON(http.StatusOK, assessmentResult)
-			return
-		}
+	ctx := c.Request.Context()
+	result, err := h.results.GetParticipantResult(ctx, sessionID, participantID)
+	if err != nil && !errors.Is(err, assessmentService.ErrResultNotFound) {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load assessment results"})
+		return
 	}
 
-	// No results found yet
-	c.JSON(http.StatusNotFound, gin.H{
-		"error": "Assessment results not ready yet",
-		"status": "processing",
-	})
+	// The participant's job, or the session's consolidated job that covers it, whichever is newer
+	job, jobErr := h.latestParticipantJob(ctx, sessionID, participantID)
+	if err != nil {
+		// No results yet - report the job producing them
+		h.respondJobState(c, job, jobErr)
+		return
+	}
+	if jobErr != nil && !errors.Is(jobErr, assessmentService.ErrJobNotFound) {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load assessment job"})
+		return
+	}
+	c.JSON(http.StatusOK, newParticipantResultResponse(result, job))
 }
 
-// IdentifySpeakerRequest represents the request for speaker identification
-type IdentifySpeakerRequest struct {
-	Transcript string `json:"transcript" binding:"required"`
-	SpeakerID  int    `json:"speaker_id" binding:"required"`
+// participantResultResponse is a stored result with the latest assessment job for the
+// participant. Stale is set when that job did not produce the result and has not
+// succeeded, so a newer transcript is still being assessed or failed to be.
+type participantResultResponse struct {
+	*assessmentService.AssessmentResponse
+	LatestJob *assessmentService.Job `json:"latest_job,omitempty"`
+	Stale     bool                   `json:"stale"`
 }
 
-// IdentifySpeaker uses LLM to identify speaker name from transcript
-func (h *SonioxHandler) IdentifySpeaker(c *gin.Context) {
-	var req IdentifySpeakerRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
+func newParticipantResultResponse(result *assessmentService.AssessmentResponse, job *assessmentService.Job) participantResultResponse {
+	response := participantResultResponse{AssessmentResponse: result, LatestJob: job}
+	if job != nil && job.Status != assessmentService.JobStatusSucceeded {
+		response.Stale = result.Provenance == nil || result.Provenance.JobID != job.ID
 	}
+	return response
+}
 
-	// Create context with timeout
-	ctx, cancel := context.WithTimeout(c.Request.Context(), 30*time.Second)
-	defer cancel()
+// latestParticipantJob returns the newest participant or consolidated assessment job covering a participant
+func (h *SonioxHandler) latestParticipantJob(ctx context.Context, sessionID, participantID string) (*assessmentService.Job, error) {
+	job, err := h.jobs.Latest(ctx, sessionID, assessmentService.JobTypeParticipantAssessment, participantID)
+	if err != nil && !errors.Is(err, assessmentService.ErrJobNotFound) {
+		return nil, err
+	}
+	consolidated, consolidatedErr := h.jobs.Latest(ctx, sessionID, assessmentService.JobTypeConsolidatedAssessment, "")
+	if consolidatedErr != nil && !errors.Is(consolidatedErr, assessmentService.ErrJobNotFound) {
+		return nil, consolidatedErr
+	}
+	if consolidated != nil && (job == nil || consolidated.CreatedAt.After(job.CreatedAt)) {
+		return consolidated, nil
+	}
+	return job, err
+}
 
-	// Call LLM service to identify speaker
-	identificationReq := assessmentService.SpeakerIdentificationRequest{
-		Transcript: req.Transcript,
-		SpeakerID:  req.SpeakerID,
+// ListAssessmentRuns handles GET /api/v1/sessions/:id/participants/:participantId/assessment/runs
+func (h *SonioxHandler) ListAssessmentRuns(c *gin.Context) {
+	sessionID := c.Param("id")
+	participantID := c.Param("participantId")
+
+	// Validate session ID (allow "test" for demo)
+	if sessionID != "test" {
+		if _, err := uuid.Parse(sessionID); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid session_id format"})
+			return
+		}
 	}
 
-	response, err := h.llmService.IdentifySpeaker(ctx, identificationReq)
+	runs, err := h.results.ListAssessmentRuns(c.Request.Context(), sessionID, participantID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": fmt.Sprintf("Failed to identify speaker: %v", err),
-		})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list assessment runs"})
 		return
 	}
-
-	// Return the identification result
 	c.JSON(http.StatusOK, gin.H{
-		"speaker_id":  response.SpeakerID,
-		"name":        response.Name,
-		"confidence":  response.Confidence,
-		"evidence":    response.Evidence,
+		"session_id":     sessionID,
+		"participant_id": participantID,
+		"runs":           runs,
 	})
 }
 
-// SyncConsolidatedTranscript receives and processes consolidated transcript data from frontend
-func (h *SonioxHandler) SyncConsolidatedTranscript(c *gin.Context) {
-	var req ConsolidatedTranscriptSyncRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
-	}
+// SetOfficialRunRequest chooses the official run; an empty run_id makes the newest run official
+type SetOfficialRunRequest struct {
+	RunID string `json:"run_id"`
+}
+
+// SetOfficialAssessmentRun handles PUT /api/v1/sessions/:id/participants/:participantId/assessment/official
+func (h *SonioxHandler) SetOfficialAssessmentRun(c *gin.Context) {
+	sessionID := c.Param("id")
+	participantID := c.Param("participantId")
 
 	// Validate session ID (allow "test" for demo)
-	if req.SessionID != "test" {
-		if _, err := uuid.Parse(req.SessionID); err != nil {
+	if sessionID != "test" {
+		if _, err := uuid.Parse(sessionID); err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid session_id format"})
 			return
 		}
 	}
 
-	// Log received transcript
-	fmt.Printf("Received consolidated transcript for session %s with %d participants\n", 
-		req.SessionID, len(req.Conversation))
+	var req SetOfficialRunRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
-	// Process assessment for each participant in background
-	go h.processConsolidatedAssessmentInBackground(req.SessionID, req.Conversation, req.ParticipantMapping)
+	err := h.results.SetOfficialRun(c.Request.Context(), sessionID, participantID, req.RunID)
+	if errors.Is(err, assessmentService.ErrResultNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "assessment run not found"})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to set official run"})
+		return
+	}
 
-	// Return success
-	c.JSON(http.StatusOK, gin.H{
-		"status": "received",
-		"message": "Consolidated transcript received and assessment processing started",
-		"participants": len(req.Conversation),
-	})
+	h.logger.ForParticipant(sessionID, participantID).Info("official assessment run set", slog.String("run_id", req.RunID))
+	result, err := h.results.GetParticipantResult(c.Request.Context(), sessionID, participantID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load assessment results"})
+		return
+	}
+	c.JSON(http.StatusOK, result)
 }
 
-// processConsolidatedAssessmentInBackground processes assessment for all participants
-func (h *SonioxHandler) processConsolidatedAssessmentInBackground(sessionID string, conversation []ConsolidatedTranscriptParticipant, participantMapping []ParticipantMapping) {
-	fmt.Printf("\n=== CONSOLIDATED ASSESSMENT PROCESSING ===\n")
-	fmt.Printf("Session ID: %s\n", sessionID)
-	fmt.Printf("Number of participants: %d\n", len(conversation))
-	
-	// Log each participant's data
-	for i, participant := range conversation {
-		fmt.Printf("\nParticipant %d:\n", i+1)
-		fmt.Printf("  ID: %s\n", participant.ParticipantID)
-		fmt.Printf("  Name: %s\n", participant.ParticipantName)
-		fmt.Printf("  Role: %s\n", participant.Role)
-		fmt.Printf("  Transcript length: %d chars\n", len(participant.Transcript))
-		if len(participant.Transcript) > 0 {
-			preview := participant.Transcript
-			if len(preview) > 200 {
-				preview = preview[:200] + "..."
-			}
-			fmt.Printf("  Transcript preview: %s\n", preview)
+// DiffAssessmentRuns handles GET /api/v1/sessions/:id/participants/:participantId/assessment/runs/diff.
+// ?to= defaults to the official run and ?from= to the run before it.
+func (h *SonioxHandler) DiffAssessmentRuns(c *gin.Context) {
+	sessionID := c.Param("id")
+	participantID := c.Param("participantId")
+
+	// Validate session ID (allow "test" for demo)
+	if sessionID != "test" {
+		if _, err := uuid.Parse(sessionID); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid session_id format"})
+			return
 		}
 	}
-	
-	// Use comprehensive assessment criteria
-	criteria := getComprehensiveAssessmentCriteria()
 
-	// Build full conversation context
-	var fullConversation string
-	for _, participant := range conversation {
-		fullConversation += fmt.Sprintf("\n[%s - %s]:\n%s\n", participant.ParticipantName, participant.Role, participant.Transcript)
+	runs, err := h.results.ListAssessmentRuns(c.Request.Context(), sessionID, participantID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list assessment runs"})
+		return
 	}
-	
-	fmt.Printf("\nFull conversation context length: %d chars\n", len(fullConversation))
 
-	// Process assessment for each participant
-	results := make([]map[string]interface{}, 0)
-	
-	// Special handling for unified transcript
-	if len(conversation) == 1 && conversation[0].ParticipantID == "unified" {
-		fmt.Printf("Processing unified transcript with all speakers\n")
-		
-		// Process the unified transcript once
-		assessmentReq := assessmentService.AssessmentRequest{
-			ParticipantID: "unified",
-			SessionID:     sessionID,
-			Transcript:    conversation[0].Transcript,
-			Criteria:      criteria,
-			Language:      "vietnamese",
-			Context:       "", // No separate context needed for unified
-		}
-
-		// Process assessment using LLM service - this will return assessments for all speakers
-		result, err := h.llmService.ProcessAssessment(context.Background(), assessmentReq)
-		if err != nil {
-			fmt.Printf("Error processing unified assessment: %v\n", err)
-			return
+	toIndex, fromIndex := -1, -1
+	for i, run := range runs {
+		if run.ID == c.Query("to") || (c.Query("to") == "" && run.Official) {
+			toIndex = i
 		}
-
-		// Check if this is a unified assessment with multiple participants
-		if result.ParticipantID == "unified_multiple" {
-			// Get all the individual participant assessments
-			allAssessments := h.llmService.GetLastUnifiedAssessments()
-
-			fmt.Printf("Got %d participant assessments from unified transcript\n", len(allAssessments))
-
-			// Store each participant's assessment individually
-			for _, participantAssessment := range allAssessments {
-				// Store with participant-specific key
-				key := fmt.Sprintf("%s_%s", sessionID, participantAssessment.ParticipantID)
-				h.assessmentResults.Store(key, participantAssessment)
-
-				// Add to results
-				results = append(results, map[string]interface{}{
-					"participant_id": participantAssessment.ParticipantID,
-					"participant_name": participantAssessment.ParticipantID, // Name should be extracted from the assessment
-					"results": participantAssessment.Results,
-				})
-
-				fmt.Printf("Stored assessment for participant: %s\n", participantAssessment.ParticipantID)
+	}
+	if toIndex < 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "assessment run not found"})
+		return
+	}
+	if from := c.Query("from"); from != "" {
+		for i, run := range runs {
+			if run.ID == from {
+				fromIndex = i
 			}
-		} else {
-			// Fallback to single result (shouldn't happen with unified transcript)
-			key := fmt.Sprintf("%s_unified", sessionID)
-			h.assessmentResults.Store(key, result)
-
-			results = append(results, map[string]interface{}{
-				"participant_id": "unified",
-				"participant_name": "All Speakers",
-				"results": result.Results,
-			})
 		}
-		
-		fmt.Printf("Unified assessment completed for session %s\n", sessionID)
 	} else {
-		// Process individual participants
-		for _, participant := range conversation {
-			// Create assessment request with full context
-			assessmentReq := assessmentService.AssessmentRequest{
-				ParticipantID: participant.ParticipantID,
-				SessionID:     sessionID,
-				Transcript:    participant.Transcript,
-				Criteria:      criteria,
-				Language:      "vietnamese",
-				Context:       fullConversation, // Add full conversation as context
-			}
+		fromIndex = toIndex - 1
+	}
+	if fromIndex < 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "no earlier assessment run to compare with"})
+		return
+	}
 
-			// Process assessment using LLM service
-			result, err := h.llmService.ProcessAssessment(context.Background(), assessmentReq)
-			if err != nil {
-				fmt.Printf("Error processing assessment for participant %s: %v\n", participant.ParticipantID, err)
-				continue
-			}
+	c.JSON(http.StatusOK, assessmentService.DiffAssessmentRuns(runs[fromIndex], runs[toIndex]))
+}
 
-			// Store individual result
-			key := fmt.Sprintf("%s_%s", sessionID, participant.ParticipantID)
-			h.assessmentResults.Store(key, result)
-			
-			// Add to consolidated results
-			results = append(results, map[string]interface{}{
-				"participant_id": participant.ParticipantID,
-				"participant_name": participant.ParticipantName,
-				"results": result.Results,
-			})
-			
-			fmt.Printf("Assessment completed for participant %s\n", participant.ParticipantName)
-		}
+// respondJobState reports the state of the job producing results that are not stored yet.
+// Queued and running jobs keep the 404 the frontend polls on; failed and cancelled jobs
+// return 200 with the job so polling stops.
+func (h *SonioxHandler) respondJobState(c *gin.Context, job *assessmentService.Job, err error) {
+	if errors.Is(err, assessmentService.ErrJobNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "No assessment has been queued for this session",
+			"status": "not_found",
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load assessment job"})
+		return
 	}
 
-	// Process group assessment using the full conversation transcript
-	fmt.Printf("Processing group assessment for session %s\n", sessionID)
-	groupCriteria := getGroupAssessmentCriteria()
-	groupAssessmentReq := assessmentService.GroupAssessmentRequest{
-		SessionID:  sessionID,
-		Transcript: fullConversation,
-		Criteria:   groupCriteria,
-		Language:   "vietnamese",
+	switch job.Status {
+	case assessmentService.JobStatusFailed, assessmentService.JobStatusCancelled:
+		c.JSON(http.StatusOK, gin.H{
+			"error": job.LastError,
+			"status": job.Status,
+			"job": job,
+		})
+	default:
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Assessment results not ready yet",
+			"status": job.Status,
+			"job": job,
+		})
 	}
+}
+
+// GetAssessmentJob returns the state of a background assessment job
+func (h *SonioxHandler) GetAssessmentJob(c *gin.Context) {
+	sessionID := c.Param("id")
+	jobID := c.Param("jobId")
 
-	groupResult, err := h.llmService.ProcessGroupAssessment(context.Background(), groupAssessmentReq)
+	job, err := h.jobs.Get(c.Request.Context(), jobID)
+	if errors.Is(err, assessmentService.ErrJobNotFound) || (err == nil && job.SessionID != sessionID) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "job not found"})
+		return
+	}
 	if err != nil {
-		fmt.Printf("Error processing group assessment: %v\n", err)
-		groupResult = nil // Continue without group assessment if it fails
-	} else {
-		fmt.Printf("Group assessment completed for session %s\n", sessionID)
-		// Store group assessment
-		groupKey := fmt.Sprintf("%s_group", sessionID)
-		h.assessmentResults.Store(groupKey, groupResult)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load assessment job"})
+		return
 	}
 
-	// Store consolidated results
-	consolidatedKey := fmt.Sprintf("%s_consolidated", sessionID)
+	c.JSON(http.StatusOK, job)
+}
 
-	// If we have unified assessments, mark them properly
-	consolidatedData := map[string]interface{}{
-		"session_id": sessionID,
-		"assessments": results,
-		"group_assessment": groupResult,
-		"timestamp": time.Now().Unix(),
+// CancelAssessmentJob cancels a queued or running background assessment job
+func (h *SonioxHandler) CancelAssessmentJob(c *gin.Context) {
+	sessionID := c.Param("id")
+	jobID := c.Param("jobId")
+	ctx := c.Request.Context()
+
+	job, err := h.jobs.Get(ctx, jobID)
+	if errors.Is(err, assessmentService.ErrJobNotFound) || (err == nil && job.SessionID != sessionID) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "job not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load assessment job"})
+		return
 	}
 
-	// Mark if this is from a unified transcript with individual participants
-	if len(results) > 0 {
-		// results is already []map[string]interface{}, no need to cast
-		firstResult := results[0]
-		if pid, ok := firstResult["participant_id"].(string); ok && pid != "unified" {
-			consolidatedData["type"] = "unified_individual"
-		}
+	job, err = h.jobs.Cancel(ctx, jobID)
+	if errors.Is(err, assessmentService.ErrInvalidJobTransition) {
+		c.JSON(http.StatusConflict, gin.H{"error": "job has already finished"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to cancel assessment job"})
+		return
 	}
 
-	h.assessmentResults.Store(consolidatedKey, consolidatedData)
-	
-	fmt.Printf("Consolidated assessment processing completed for session %s\n", sessionID)
+	h.logger.ForSession(sessionID).Info("assessment job cancelled", slog.String("job_id", jobID))
+	c.JSON(http.StatusOK, job)
 }
 
-// GetConsolidatedAssessmentResults retrieves consolidated assessment results for all participants
-func (h *SonioxHandler) GetConsolidatedAssessmentResults(c *gin.Context) {
+// CancelSessionAssessments cancels every unfinished background assessment job for a session
+func (h *SonioxHandler) CancelSessionAssessments(c *gin.Context) {
 	sessionID := c.Param("id")
-	
-	if sessionID == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "session_id is required"})
-		return
-	}
 
 	// Validate session ID (allow "test" for demo)
 	if sessionID != "test" {
@@ -958,452 +1230,1871 @@ func (h *SonioxHandler) GetConsolidatedAssessmentResults(c *gin.Context) {
 		}
 	}
 
-	// Try to get consolidated results first
-	consolidatedKey := fmt.Sprintf("%s_consolidated", sessionID)
-	if result, ok := h.assessmentResults.Load(consolidatedKey); ok {
-		c.JSON(http.StatusOK, result)
-		return
-	}
-
-	// If no consolidated results, try to build from individual results
-	results := make([]map[string]interface{}, 0)
-	foundAny := false
-	
-	// Iterate through stored results to find matching session
-	h.assessmentResults.Range(func(key, value interface{}) bool {
-		keyStr := key.(string)
-		if len(keyStr) > len(sessionID) && keyStr[:len(sessionID)] == sessionID && keyStr != consolidatedKey {
-			if assessmentResult, ok := value.(*assessmentService.AssessmentResponse); ok {
-				results = append(results, map[string]interface{}{
-					"participant_id": assessmentResult.ParticipantID,
-					"results": assessmentResult.Results,
-				})
-				foundAny = true
-			}
+	cancelled := []*assessmentService.Job{}
+	for _, jobType := range []string{assessmentService.JobTypeParticipantAssessment, assessmentService.JobTypeConsolidatedAssessment} {
+		jobs, err := h.jobs.CancelMatching(c.Request.Context(), sessionID, jobType, "")
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to cancel assessment jobs"})
+			return
 		}
-		return true
-	})
-
-	if foundAny {
-		c.JSON(http.StatusOK, gin.H{
-			"session_id": sessionID,
-			"assessments": results,
-		})
-		return
+		cancelled = append(cancelled, jobs...)
 	}
 
-	// No results found yet
-	c.JSON(http.StatusNotFound, gin.H{
-		"error": "Assessment results not ready yet",
-		"status": "processing",
+	h.logger.ForSession(sessionID).Info("session assessments cancelled", slog.Int("jobs", len(cancelled)))
+	c.JSON(http.StatusOK, gin.H{
+		"session_id": sessionID,
+		"cancelled": cancelled,
 	})
 }
 
-// SubmitAsyncTranscriptionRequest represents a request to start async transcription
-type SubmitAsyncTranscriptionRequest struct {
-	SessionID         string         `json:"session_id" binding:"required"`
-	SpeakerMapping    map[int]string `json:"speaker_mapping"`    // Manual speaker corrections
-	ManualCorrections map[int]string `json:"manual_corrections"` // Track which speakers were manually corrected
-}
+// StreamSessionEvents handles GET /api/v1/sessions/:id/events as a Server-Sent Events
+// stream of the session's results and transcription status. Reconnecting clients send
+// Last-Event-ID (or ?last_event_id=) to replay what they missed.
+func (h *SonioxHandler) StreamSessionEvents(c *gin.Context) {
+	sessionID := c.Param("id")
 
-// SubmitAsyncTranscription submits audio file for async transcription with Soniox
-func (h *SonioxHandler) SubmitAsyncTranscription(c *gin.Context) {
-	sessionID := c.PostForm("session_id")
-	if sessionID == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "session_id is required"})
-		return
+	// Validate session ID (allow "test" for demo)
+	if sessionID != "test" {
+		if _, err := uuid.Parse(sessionID); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid session_id format"})
+			return
+		}
 	}
 
-	// Get audio file from multipart form
-	file, err := c.FormFile("audio")
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "audio file is required"})
-		return
+	lastEventID := c.GetHeader("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = c.Query("last_event_id")
 	}
 
-	// Parse speaker mapping JSON if provided
-	speakerMappingJSON := c.PostForm("speaker_mapping")
-	speakerMapping := make(map[int]string)
-	if speakerMappingJSON != "" {
-		// JSON keys are always strings, so unmarshal to map[string]string first
-		var tempMapping map[string]string
-		if err := json.Unmarshal([]byte(speakerMappingJSON), &tempMapping); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid speaker_mapping format"})
+	replay, subscription := h.stream.Subscribe(sessionID, lastEventID)
+	defer subscription.Close()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	// Ask EventSource clients to reconnect after 5 seconds
+	fmt.Fprint(c.Writer, "retry: 5000\n\n")
+	for _, event := range replay {
+		if err := writeSSEEvent(c.Writer, event); err != nil {
 			return
 		}
-		// Convert string keys to int keys
-		for key, value := range tempMapping {
-			var speakerID int
-			if _, err := fmt.Sscanf(key, "%d", &speakerID); err == nil {
-				speakerMapping[speakerID] = value
-			}
-		}
 	}
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(15 * time.Second)
+	defer heartbeat.Stop()
 
-	// Parse manual corrections JSON if provided
-	manualCorrectionsJSON := c.PostForm("manual_corrections")
-	manualCorrections := make(map[int]string)
-	if manualCorrectionsJSON != "" {
-		// JSON keys are always strings, so unmarshal to map[string]string first
-		var tempCorrections map[string]string
-		if err := json.Unmarshal([]byte(manualCorrectionsJSON), &tempCorrections); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid manual_corrections format"})
+	for {
+		select {
+		case <-c.Request.Context().Done():
 			return
-		}
-		// Convert string keys to int keys
-		for key, value := range tempCorrections {
-			var speakerID int
-			if _, err := fmt.Sscanf(key, "%d", &speakerID); err == nil {
-				manualCorrections[speakerID] = value
+		case event, ok := <-subscription.Events():
+			if !ok {
+				// Fell too far behind; the client reconnects and replays from its last event
+				return
+			}
+			if err := writeSSEEvent(c.Writer, event); err != nil {
+				return
 			}
+			c.Writer.Flush()
+		case <-heartbeat.C:
+			// Comment lines keep proxies from closing an idle stream
+			if _, err := fmt.Fprint(c.Writer, ": ping\n\n"); err != nil {
+				return
+			}
+			c.Writer.Flush()
 		}
 	}
+}
 
-	// Open the uploaded file
-	audioFile, err := file.Open()
+// writeSSEEvent writes one event in Server-Sent Events framing
+func writeSSEEvent(w io.Writer, event assessmentService.AssessmentEvent) error {
+	data, err := json.Marshal(event)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to open audio file"})
-		return
+		return err
 	}
-	defer audioFile.Close()
+	_, err = fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+	return err
+}
 
-	// Read file contents
-	audioData, err := io.ReadAll(audioFile)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to read audio file"})
-		return
-	}
+// IdentifySpeakerRequest represents the request for speaker identification
+type IdentifySpeakerRequest struct {
+	Transcript string `json:"transcript" binding:"required"`
+	SpeakerID  int    `json:"speaker_id" binding:"required"`
+}
 
-	// Submit to Soniox async API
-	sonioxAPIKey := os.Getenv("SONIOX_API_KEY")
-	if sonioxAPIKey == "" {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Soniox API key not configured"})
+// IdentifySpeaker uses LLM to identify speaker name from transcript
+func (h *SonioxHandler) IdentifySpeaker(c *gin.Context) {
+	var req IdentifySpeakerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	// Step 1: Upload audio file to Soniox
-	log.Printf("Step 1: Uploading audio file to Soniox: size=%d bytes", len(audioData))
-	var fileUploadBody bytes.Buffer
-	fileWriter := multipart.NewWriter(&fileUploadBody)
+	// Create context with timeout
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 30*time.Second)
+	defer cancel()
 
-	filePart, err := fileWriter.CreateFormFile("file", file.Filename)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create file upload form"})
-		return
+	// Call LLM service to identify speaker
+	identificationReq := assessmentService.SpeakerIdentificationRequest{
+		Transcript: req.Transcript,
+		SpeakerID:  req.SpeakerID,
 	}
-	filePart.Write(audioData)
-	fileWriter.Close()
 
-	uploadReq, err := http.NewRequest("POST", "https://api.soniox.com/v1/files", &fileUploadBody)
+	response, err := h.llmService.IdentifySpeaker(ctx, identificationReq)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create upload request"})
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": fmt.Sprintf("Failed to identify speaker: %v", err),
+		})
 		return
 	}
-	uploadReq.Header.Set("Content-Type", fileWriter.FormDataContentType())
-	uploadReq.Header.Set("Authorization", fmt.Sprintf("Bearer %s", sonioxAPIKey))
 
-	client := &http.Client{Timeout: 60 * time.Second}
-	uploadResp, err := client.Do(uploadReq)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("file upload error: %v", err)})
-		return
-	}
-	defer uploadResp.Body.Close()
+	// Return the identification result
+	c.JSON(http.StatusOK, gin.H{
+		"speaker_id":  response.SpeakerID,
+		"name":        response.Name,
+		"confidence":  response.Confidence,
+		"evidence":    response.Evidence,
+	})
+}
 
-	uploadRespBody, err := io.ReadAll(uploadResp.Body)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to read upload response"})
+// IdentifySpeakersRequest represents the request for conversation-level speaker identification
+type IdentifySpeakersRequest struct {
+	Segments           []assessmentService.TranscriptSegment `json:"segments" binding:"required"`
+	ParticipantMapping []ParticipantMapping                  `json:"participant_mapping"`
+}
+
+// IdentifySpeakers handles POST /api/v1/speakers/identify, identifying every speaker of a
+// diarized conversation together against the expected participants. It returns one
+// assignment per speaker, in order of first speaking.
+func (h *SonioxHandler) IdentifySpeakers(c *gin.Context) {
+	var req IdentifySpeakersRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	if uploadResp.StatusCode != http.StatusOK && uploadResp.StatusCode != http.StatusCreated {
-		log.Printf("Soniox file upload error: Status %d, Body: %s", uploadResp.StatusCode, string(uploadRespBody))
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 60*time.Second)
+	defer cancel()
+
+	speakers, transcript := assessmentService.DiarizedConversation(req.Segments)
+	response, err := h.llmService.IdentifySpeakers(ctx, assessmentService.ConversationIdentificationRequest{
+		Transcript: transcript,
+		Speakers:   speakers,
+		Roster:     sessionParticipants(req.ParticipantMapping),
+	})
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "Soniox file upload error",
-			"status":  uploadResp.StatusCode,
-			"details": string(uploadRespBody),
+			"error": fmt.Sprintf("Failed to identify speakers: %v", err),
 		})
 		return
 	}
 
-	var uploadResult struct {
-		ID string `json:"id"`
-	}
-	if err := json.Unmarshal(uploadRespBody, &uploadResult); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to parse upload response"})
+	c.JSON(http.StatusOK, response)
+}
+
+// SyncConsolidatedTranscript receives and processes consolidated transcript data from frontend
+func (h *SonioxHandler) SyncConsolidatedTranscript(c *gin.Context) {
+	var req ConsolidatedTranscriptSyncRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	log.Printf("File uploaded successfully: file_id=%s", uploadResult.ID)
-
-	// Step 2: Create transcription with uploaded file
-	log.Printf("Step 2: Creating transcription with file_id=%s", uploadResult.ID)
-	transcriptionReq := map[string]interface{}{
-		"model":                      "stt-async-preview-v1",
-		"file_id":                    uploadResult.ID,
-		"enable_speaker_diarization": true,
-		"language_hints":             []string{"en", "vi"},
-		"context":                    "GLOVIA, Heineken, MT, GT, ecommerce, assessment center",
+	// Validate session ID (allow "test" for demo)
+	if req.SessionID != "test" {
+		if _, err := uuid.Parse(req.SessionID); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid session_id format"})
+			return
+		}
 	}
 
-	transcriptionJSON, err := json.Marshal(transcriptionReq)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create transcription request"})
-		return
-	}
+	// Log received transcript
+	logger := h.logger.ForSession(req.SessionID)
+	logger.Info("consolidated transcript received", slog.Int("participants", len(req.Conversation)))
 
-	createReq, err := http.NewRequest("POST", "https://api.soniox.com/v1/transcriptions", bytes.NewBuffer(transcriptionJSON))
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create transcription request"})
+	// Retried submissions, and periodic syncs with no new speech, return the existing job
+	payload := consolidatedAssessmentPayload{Conversation: req.Conversation, ParticipantMapping: req.ParticipantMapping}
+	if h.findDuplicateSubmission(c, logger, assessmentService.JobTypeConsolidatedAssessment, req.SessionID, "", payload) {
 		return
 	}
-	createReq.Header.Set("Content-Type", "application/json")
-	createReq.Header.Set("Authorization", fmt.Sprintf("Bearer %s", sonioxAPIKey))
 
-	createResp, err := client.Do(createReq)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("transcription creation error: %v", err)})
-		return
+	// Store each participant's transcript
+	receivedAt := time.Now().UTC()
+	for _, participant := range req.Conversation {
+		if err := h.results.SaveTranscript(c.Request.Context(), assessmentService.TranscriptRecord{
+			SessionID:       req.SessionID,
+			ParticipantID:   participant.ParticipantID,
+			ParticipantName: participant.ParticipantName,
+			Role:            participant.Role,
+			Transcript:      participant.Transcript,
+			ClientTimestamp: req.Timestamp,
+			ReceivedAt:      receivedAt,
+		}); err != nil {
+			logger.Error("failed to store transcript", slog.String("error", err.Error()))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to store transcript"})
+			return
+		}
 	}
-	defer createResp.Body.Close()
 
-	createRespBody, err := io.ReadAll(createResp.Body)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to read transcription response"})
-		return
+	// Live recorders resend the growing conversation every sync; corrected transcripts
+	// (supersede) are always assessed
+	length := conversationLength(req.Conversation)
+	if !req.Supersede {
+		assess := false
+		if req.Final {
+			assess = h.consolidatedDebounce.Flush(req.SessionID, "", length) ||
+				length > 0 && !h.latestAssessed(c.Request.Context(), assessmentService.JobTypeConsolidatedAssessment, req.SessionID, "")
+		} else {
+			assess = h.consolidatedDebounce.Observe(req.SessionID, "", length)
+		}
+		if !assess {
+			if !req.Final {
+				h.deferredConsolidated.Store(req.SessionID, payload)
+			}
+			h.respondDeferredConsolidated(c, req.SessionID, req.Final)
+			return
+		}
 	}
 
-	if createResp.StatusCode != http.StatusOK && createResp.StatusCode != http.StatusCreated {
-		log.Printf("Soniox transcription creation error: Status %d, Body: %s", createResp.StatusCode, string(createRespBody))
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "Soniox transcription creation error",
-			"status":  createResp.StatusCode,
-			"details": string(createRespBody),
-		})
-		return
+	if req.Supersede {
+		if _, err := h.jobs.CancelMatching(c.Request.Context(), req.SessionID, assessmentService.JobTypeConsolidatedAssessment, ""); err != nil {
+			logger.Error("failed to cancel superseded assessment", slog.String("error", err.Error()))
+		}
 	}
 
-	// Parse response to get transcription ID
-	var sonioxResp struct {
-		ID string `json:"id"`
-	}
-	if err := json.Unmarshal(createRespBody, &sonioxResp); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to parse transcription response"})
+	// Queue assessment for all participants
+	job, duplicate, err := h.jobs.EnqueueIdempotent(c.Request.Context(), assessmentService.JobTypeConsolidatedAssessment,
+		req.SessionID, "", c.GetHeader("Idempotency-Key"), payload)
+	if err != nil {
+		logger.Error("failed to queue consolidated assessment", slog.String("error", err.Error()))
+		respondEnqueueError(c, err)
 		return
 	}
-
-	// Store transcription ID and speaker mapping for later retrieval
-	h.assessmentResults.Store(fmt.Sprintf("%s_async_id", sessionID), sonioxResp.ID)
-	h.assessmentResults.Store(fmt.Sprintf("%s_file_id", sessionID), uploadResult.ID)
-	if len(speakerMapping) > 0 {
-		h.assessmentResults.Store(fmt.Sprintf("%s_speaker_mapping", sessionID), speakerMapping)
+	if duplicate {
+		respondDuplicateSubmission(c, job)
+		return
 	}
-	if len(manualCorrections) > 0 {
-		h.assessmentResults.Store(fmt.Sprintf("%s_manual_corrections", sessionID), manualCorrections)
+	h.deferredConsolidated.Delete(req.SessionID)
+	if req.Final {
+		h.consolidatedDebounce.Forget(req.SessionID)
+	} else {
+		h.consolidatedDebounce.Assessed(req.SessionID, "", length)
 	}
 
-	log.Printf("Async transcription submitted for session %s, transcription_id: %s, file_id: %s", sessionID, sonioxResp.ID, uploadResult.ID)
-
+	// Return success
 	c.JSON(http.StatusOK, gin.H{
-		"transcription_id": sonioxResp.ID,
-		"file_id":          uploadResult.ID,
-		"status":           "processing",
+		"status": "received",
+		"message": "Consolidated transcript received and assessment queued",
+		"participants": len(req.Conversation),
+		"job_id": job.ID,
 	})
 }
 
-// GetAsyncTranscriptionStatus polls Soniox for async transcription results
-func (h *SonioxHandler) GetAsyncTranscriptionStatus(c *gin.Context) {
-	sessionID := c.Param("id")
-	if sessionID == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "session_id is required"})
-		return
+// respondDeferredConsolidated answers a consolidated sync that did not grow enough to
+// re-assess, pointing at the session's latest consolidated job
+func (h *SonioxHandler) respondDeferredConsolidated(c *gin.Context, sessionID string, final bool) {
+	response := gin.H{
+		"status":  "deferred",
+		"message": "Transcript received; assessment deferred until the transcript grows or goes quiet",
 	}
-
-	// Get transcription ID
-	transcriptionIDKey := fmt.Sprintf("%s_async_id", sessionID)
-	value, ok := h.assessmentResults.Load(transcriptionIDKey)
-	if !ok {
-		c.JSON(http.StatusNotFound, gin.H{"error": "no async transcription found for this session"})
-		return
+	if final {
+		response["status"] = "unchanged"
+		response["message"] = "Transcript unchanged since the last assessment"
 	}
-
-	transcriptionID := value.(string)
-
-	// Poll Soniox for results
-	sonioxAPIKey := os.Getenv("SONIOX_API_KEY")
-	if sonioxAPIKey == "" {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Soniox API key not configured"})
-		return
+	if job, err := h.jobs.Latest(c.Request.Context(), sessionID, assessmentService.JobTypeConsolidatedAssessment, ""); err == nil {
+		response["job_id"] = job.ID
 	}
+	c.JSON(http.StatusAccepted, response)
+}
 
-	// Check transcription status
-	statusReq, err := http.NewRequest("GET", fmt.Sprintf("https://api.soniox.com/v1/transcriptions/%s", transcriptionID), nil)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create request"})
-		return
-	}
-	statusReq.Header.Set("Authorization", fmt.Sprintf("Bearer %s", sonioxAPIKey))
+// latestAssessed reports whether the latest assessment of a transcript is queued, running
+// or succeeded, so a final sync that did not grow it has nothing to add
+func (h *SonioxHandler) latestAssessed(ctx context.Context, jobType, sessionID, participantID string) bool {
+	job, err := h.jobs.Latest(ctx, sessionID, jobType, participantID)
+	return err == nil && job.Status != assessmentService.JobStatusFailed && job.Status != assessmentService.JobStatusCancelled
+}
 
-	client := &http.Client{Timeout: 10 * time.Second}
-	statusResp, err := client.Do(statusReq)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Soniox API error: %v", err)})
+// assessIdleConsolidated assesses a session's deferred consolidated sync once no sync has
+// grown the conversation for the idle delay
+func (h *SonioxHandler) assessIdleConsolidated(sessionID, _ string) {
+	deferred, ok := h.deferredConsolidated.LoadAndDelete(sessionID)
+	if !ok {
 		return
 	}
-	defer statusResp.Body.Close()
+	payload := deferred.(consolidatedAssessmentPayload)
+	logger := h.logger.ForSession(sessionID)
 
-	statusBody, err := io.ReadAll(statusResp.Body)
+	job, _, err := h.jobs.EnqueueIdempotent(context.Background(), assessmentService.JobTypeConsolidatedAssessment, sessionID, "", "", payload)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to read status response"})
+		logger.Error("failed to queue idle consolidated assessment", slog.String("error", err.Error()))
 		return
 	}
+	h.consolidatedDebounce.Assessed(sessionID, "", conversationLength(payload.Conversation))
+	logger.Info("idle consolidated assessment queued", slog.String("job_id", job.ID))
+}
 
-	var statusResult struct {
-		Status       string `json:"status"`
-		ErrorMessage string `json:"error_message,omitempty"`
+// conversationLength counts the characters of a consolidated conversation
+func conversationLength(conversation []ConsolidatedTranscriptParticipant) int {
+	length := 0
+	for _, participant := range conversation {
+		length += utf8.RuneCountInString(participant.Transcript)
 	}
-	if err := json.Unmarshal(statusBody, &statusResult); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to parse status response"})
+	return length
+}
+
+// SyncTranscriptDelta handles POST /api/v1/sessions/transcript-delta. It appends a chunk
+// to the participant's live transcript and re-assesses the whole transcript once it has
+// grown meaningfully, has been quiet for a while, or the chunk is final.
+func (h *SonioxHandler) SyncTranscriptDelta(c *gin.Context) {
+	var req TranscriptDeltaRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	// If not completed yet, return status
-	if statusResult.Status != "completed" {
-		if statusResult.Status == "error" {
-			c.JSON(http.StatusOK, gin.H{
-				"status":        "error",
-				"error_message": statusResult.ErrorMessage,
-			})
+	// Validate session ID (allow "test" for demo)
+	if req.SessionID != "test" {
+		if _, err := uuid.Parse(req.SessionID); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid session_id format"})
 			return
 		}
-		c.JSON(http.StatusAccepted, gin.H{
-			"status":           statusResult.Status,
-			"transcription_id": transcriptionID,
-		})
-		return
 	}
 
-	// Fetch the actual transcript
-	log.Printf("Transcription completed, fetching transcript for ID: %s", transcriptionID)
-	transcriptReq, err := http.NewRequest("GET", fmt.Sprintf("https://api.soniox.com/v1/transcriptions/%s/transcript", transcriptionID), nil)
+	logger := h.logger.ForParticipant(req.SessionID, req.ParticipantID)
+	appended, err := h.results.AppendTranscriptChunk(c.Request.Context(), assessmentService.TranscriptChunk{
+		SessionID:       req.SessionID,
+		ParticipantID:   req.ParticipantID,
+		ParticipantName: req.ParticipantName,
+		Role:            req.Role,
+		Text:            req.Text,
+		ClientTimestamp: req.Timestamp,
+		ReceivedAt:      time.Now().UTC(),
+	})
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create transcript request"})
+		logger.Error("failed to store transcript chunk", slog.String("error", err.Error()))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to store transcript chunk"})
 		return
 	}
-	transcriptReq.Header.Set("Authorization", fmt.Sprintf("Bearer %s", sonioxAPIKey))
 
-	transcriptResp, err := client.Do(transcriptReq)
+	transcript, err := h.participantTranscript(c.Request.Context(), req.SessionID, req.ParticipantID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("transcript fetch error: %v", err)})
+		logger.Error("failed to load transcript chunks", slog.String("error", err.Error()))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load transcript"})
 		return
 	}
-	defer transcriptResp.Body.Close()
+	length := utf8.RuneCountInString(transcript)
 
-	transcriptBody, err := io.ReadAll(transcriptResp.Body)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to read transcript"})
-		return
-	}
-
-	// The response has tokens array directly at root level
-	var transcriptResult struct {
-		ID     string `json:"id"`
-		Text   string `json:"text"`
-		Tokens []struct {
-			Text          string  `json:"text"`
-			StartMs       int     `json:"start_ms"`
-			EndMs         int     `json:"end_ms"`
-			Confidence    float64 `json:"confidence"`
-			Speaker       string  `json:"speaker,omitempty"`
-			Language      string  `json:"language,omitempty"`
-			IsAudioEvent  *bool   `json:"is_audio_event,omitempty"`
-		} `json:"tokens"`
-	}
-	if err := json.Unmarshal(transcriptBody, &transcriptResult); err != nil {
-		log.Printf("Failed to parse transcript. Error: %v, Response (first 500 chars): %s", err, string(transcriptBody[:min(500, len(transcriptBody))]))
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":    "failed to parse transcript",
-			"details":  err.Error(),
-			"response": string(transcriptBody[:min(200, len(transcriptBody))]),
-		})
-		return
+	assess := false
+	if req.Final {
+		assess = h.transcriptDebounce.Flush(req.SessionID, req.ParticipantID, length) ||
+			length > 0 && !h.latestAssessed(c.Request.Context(), assessmentService.JobTypeParticipantAssessment, req.SessionID, req.ParticipantID)
+	} else if appended {
+		assess = h.transcriptDebounce.Observe(req.SessionID, req.ParticipantID, length)
 	}
 
-	// Load speaker mapping and manual corrections
-	speakerMappingValue, _ := h.assessmentResults.Load(fmt.Sprintf("%s_speaker_mapping", sessionID))
-	manualCorrectionsValue, _ := h.assessmentResults.Load(fmt.Sprintf("%s_manual_corrections", sessionID))
-
-	speakerMapping := make(map[int]string)
-	if speakerMappingValue != nil {
-		speakerMapping = speakerMappingValue.(map[int]string)
+	response := gin.H{
+		"status":            "received",
+		"characters":        length,
+		"assessment_queued": false,
+	}
+	if !appended {
+		response["status"] = "duplicate"
+	}
+	if assess {
+		job, err := h.queueTranscriptAssessment(c.Request.Context(), req.SessionID, req.ParticipantID, transcript)
+		if err != nil {
+			logger.Error("failed to queue assessment", slog.String("error", err.Error()))
+			respondEnqueueError(c, err)
+			return
+		}
+		response["assessment_queued"] = true
+		response["job_id"] = job.ID
 	}
+	c.JSON(http.StatusOK, response)
+}
+
+// CloseTranscriptSession handles POST /api/v1/sessions/:id/transcript/close. Every
+// participant whose live transcript grew since its last assessment is assessed.
+func (h *SonioxHandler) CloseTranscriptSession(c *gin.Context) {
+	sessionID := c.Param("id")
 
-	manualCorrections := make(map[int]string)
-	if manualCorrectionsValue != nil {
-		manualCorrections = manualCorrectionsValue.(map[int]string)
+	// Validate session ID (allow "test" for demo)
+	if sessionID != "test" {
+		if _, err := uuid.Parse(sessionID); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid session_id format"})
+			return
+		}
 	}
 
-	// Process tokens into segments with speaker names
-	type Segment struct {
-		Speaker   int     `json:"speaker"`
-		Name      string  `json:"name"`
-		Text      string  `json:"text"`
-		StartTime float64 `json:"start_time"`
-		EndTime   float64 `json:"end_time"`
+	logger := h.logger.ForSession(sessionID)
+	chunks, err := h.results.ListTranscriptChunks(c.Request.Context(), sessionID, "")
+	if err != nil {
+		logger.Error("failed to load transcript chunks", slog.String("error", err.Error()))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load transcripts"})
+		return
 	}
 
-	segments := []Segment{}
-	var currentSegment *Segment
+	participants := []string{}
+	byParticipant := make(map[string][]assessmentService.TranscriptChunk)
+	for _, chunk := range chunks {
+		if _, ok := byParticipant[chunk.ParticipantID]; !ok {
+			participants = append(participants, chunk.ParticipantID)
+		}
+		byParticipant[chunk.ParticipantID] = append(byParticipant[chunk.ParticipantID], chunk)
+	}
 
-	for _, token := range transcriptResult.Tokens {
-		// Skip audio events
-		if token.IsAudioEvent != nil && *token.IsAudioEvent {
+	jobs := []*assessmentService.Job{}
+	for _, participantID := range participants {
+		transcript := assessmentService.AssembleTranscript(byParticipant[participantID])
+		if !h.transcriptDebounce.Flush(sessionID, participantID, utf8.RuneCountInString(transcript)) {
 			continue
 		}
-
-		// Parse speaker ID from speaker string (e.g., "1" -> 1)
-		speakerID := 0
-		if token.Speaker != "" {
-			fmt.Sscanf(token.Speaker, "%d", &speakerID)
+		job, err := h.queueTranscriptAssessment(c.Request.Context(), sessionID, participantID, transcript)
+		if err != nil {
+			logger.Error("failed to queue assessment", slog.String("participant_id", participantID), slog.String("error", err.Error()))
+			respondEnqueueError(c, err)
+			return
 		}
+		jobs = append(jobs, job)
+	}
+	h.transcriptDebounce.Forget(sessionID)
 
-		// Apply manual corrections first, then fall back to auto-identified names
-		speakerName := fmt.Sprintf("Speaker %d", speakerID)
-		if name, ok := manualCorrections[speakerID]; ok {
-			speakerName = name
-		} else if name, ok := speakerMapping[speakerID]; ok {
-			speakerName = name
-		}
+	logger.Info("live transcripts closed", slog.Int("participants", len(participants)), slog.Int("jobs", len(jobs)))
+	c.JSON(http.StatusOK, gin.H{
+		"session_id": sessionID,
+		"jobs":       jobs,
+	})
+}
 
-		// Create new segment when speaker changes or first token
-		if currentSegment == nil || currentSegment.Speaker != speakerID {
-			if currentSegment != nil {
-				segments = append(segments, *currentSegment)
-			}
-			currentSegment = &Segment{
-				Speaker:   speakerID,
-				Name:      speakerName,
-				Text:      token.Text,
-				StartTime: float64(token.StartMs) / 1000.0,
-				EndTime:   float64(token.EndMs) / 1000.0,
-			}
-		} else {
-			// Append to current segment
-			currentSegment.Text += token.Text
-			currentSegment.EndTime = float64(token.EndMs) / 1000.0
-		}
+// participantTranscript assembles a participant's live transcript from its chunks
+func (h *SonioxHandler) participantTranscript(ctx context.Context, sessionID, participantID string) (string, error) {
+	chunks, err := h.results.ListTranscriptChunks(ctx, sessionID, participantID)
+	if err != nil {
+		return "", err
 	}
+	return assessmentService.AssembleTranscript(chunks), nil
+}
 
-	if currentSegment != nil {
-		segments = append(segments, *currentSegment)
+// queueTranscriptAssessment queues an assessment of a participant's assembled live
+// transcript. Identical transcripts are not assessed twice.
+func (h *SonioxHandler) queueTranscriptAssessment(ctx context.Context, sessionID, participantID, transcript string) (*assessmentService.Job, error) {
+	job, _, err := h.jobs.EnqueueIdempotent(ctx, assessmentService.JobTypeParticipantAssessment, sessionID, participantID, "",
+		participantAssessmentPayload{Transcript: transcript})
+	if err != nil {
+		return nil, err
 	}
+	h.transcriptDebounce.Assessed(sessionID, participantID, utf8.RuneCountInString(transcript))
+	return job, nil
+}
 
-	log.Printf("Async transcription completed for session %s: %d segments", sessionID, len(segments))
+// assessIdleTranscript assesses a live transcript that stopped growing before it
+// reached the growth threshold
+func (h *SonioxHandler) assessIdleTranscript(sessionID, participantID string) {
+	ctx := context.Background()
+	logger := h.logger.ForParticipant(sessionID, participantID)
 
-	// Return completed transcription
-	c.JSON(http.StatusOK, gin.H{
-		"status":           "completed",
-		"transcription_id": transcriptionID,
-		"segments":         segments,
-	})
+	transcript, err := h.participantTranscript(ctx, sessionID, participantID)
+	if err != nil {
+		logger.Error("failed to load transcript chunks", slog.String("error", err.Error()))
+		return
+	}
+	job, err := h.queueTranscriptAssessment(ctx, sessionID, participantID, transcript)
+	if err != nil {
+		logger.Error("failed to queue idle transcript assessment", slog.String("error", err.Error()))
+		return
+	}
+	logger.Info("idle transcript assessment queued", slog.String("job_id", job.ID))
+}
+
+// processConsolidatedAssessmentInBackground processes assessment for all participants
+func (h *SonioxHandler) processConsolidatedAssessmentInBackground(ctx context.Context, sessionID string, conversation []ConsolidatedTranscriptParticipant, participantMapping []ParticipantMapping, fullConversation string) error {
+	logger := h.logger.ForSession(sessionID)
+	logger.Info("consolidated assessment started", slog.Int("participants", len(conversation)))
+
+	// Log each participant's data without names or transcript content
+	for _, participant := range conversation {
+		logger.Debug("consolidated participant",
+			slog.String("participant_id", participant.ParticipantID),
+			assessmentService.RedactedName("participant_name", participant.ParticipantName),
+			slog.String("role", participant.Role),
+			assessmentService.RedactedText("transcript", participant.Transcript),
+		)
+	}
+	
+	// Use comprehensive assessment criteria
+	criteria := getComprehensiveAssessmentCriteria()
+
+	// Build full conversation context
+	if fullConversation == "" {
+		for _, participant := range conversation {
+			fullConversation += fmt.Sprintf("\n[%s - %s]:\n%s\n", participant.ParticipantName, participant.Role, participant.Transcript)
+		}
+	}
+	
+	logger.Debug("conversation context built", assessmentService.RedactedText("context", fullConversation))
+
+	// Participants and the group are assessed concurrently. Each finished assessment is
+	// published straight away as a partial consolidated result.
+	publisher := newConsolidatedPublisher(h.results, logger, sessionID)
+
+	var groupResult *assessmentService.GroupAssessmentResponse
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		// The group assessment only needs the full conversation
+		groupResult = h.processGroupAssessment(ctx, logger, sessionID, fullConversation)
+		publisher.group(ctx, groupResult)
+	}()
+
+	var err error
+	if len(conversation) == 1 && conversation[0].ParticipantID == "unified" {
+		err = h.assessUnifiedTranscript(ctx, logger, sessionID, conversation[0].Transcript, participantMapping, criteria, publisher)
+	} else {
+		err = h.assessParticipantsInParallel(ctx, logger, sessionID, conversation, fullConversation, criteria, publisher)
+	}
+	wg.Wait()
+
+	if ctx.Err() != nil {
+		// Cancelled or past the job deadline - leave the published results marked partial
+		return ctx.Err()
+	}
+	if err != nil {
+		return err
+	}
+
+	// Store consolidated results
+	results := publisher.assessments()
+	consolidatedData := &assessmentService.ConsolidatedResult{
+		SessionID:       sessionID,
+		Assessments:     results,
+		GroupAssessment: groupResult,
+		Timestamp:       time.Now().Unix(),
+	}
+
+	// Mark if this is from a unified transcript with individual participants
+	if len(results) > 0 && results[0].ParticipantID != "unified" {
+		consolidatedData.Type = "unified_individual"
+	}
+
+	if err := h.results.SaveConsolidatedResult(ctx, consolidatedData); err != nil {
+		return fmt.Errorf("failed to store consolidated assessment: %w", err)
+	}
+	
+	logger.Info("consolidated assessment completed", slog.Int("assessments", len(results)))
+	return nil
+}
+
+// assessUnifiedTranscript assesses every speaker of a unified transcript in one LLM call;
+// the response is validated against the participants of the mapping
+func (h *SonioxHandler) assessUnifiedTranscript(ctx context.Context, logger *assessmentService.AssessmentLogger, sessionID, transcript string, participantMapping []ParticipantMapping, criteria []assessmentService.AssessmentCriteria, publisher *consolidatedPublisher) error {
+	logger.Info("processing unified transcript with all speakers")
+	publisher.expect("unified")
+
+	expected := make([]string, 0, len(participantMapping))
+	for _, participant := range participantMapping {
+		if participant.ID != "" {
+			expected = append(expected, participant.ID)
+		}
+	}
+
+	// Process the unified transcript once
+	assessmentReq := assessmentService.AssessmentRequest{
+		ParticipantID: "unified",
+		SessionID:     sessionID,
+		Transcript:    transcript,
+		Criteria:      criteria,
+		Language:      "vietnamese",
+		Context:       "", // No separate context needed for unified
+		ExpectedParticipants: expected,
+	}
+
+	// Process assessment using LLM service - this will return assessments for all speakers
+	result, err := h.llmService.ProcessAssessment(ctx, assessmentReq)
+	if err != nil {
+		return fmt.Errorf("unified assessment failed: %w", err)
+	}
+
+	// Check if this is a unified assessment with multiple participants
+	if result.ParticipantID == "unified_multiple" {
+		logger.Info("unified transcript assessed", slog.Int("participants", len(result.Participants)))
+
+		// Store each participant's assessment individually
+		for _, participantAssessment := range result.Participants {
+			if err := h.results.SaveParticipantResult(ctx, participantAssessment); err != nil {
+				logger.Error("failed to store participant assessment", slog.String("participant_id", participantAssessment.ParticipantID), slog.String("error", err.Error()))
+			}
+
+			publisher.add(assessmentService.ConsolidatedParticipantResult{
+				ParticipantID:   participantAssessment.ParticipantID,
+				ParticipantName: participantAssessment.ParticipantID, // Name should be extracted from the assessment
+				Results:         participantAssessment.Results,
+			})
+		}
+	} else {
+		// Fallback to single result (shouldn't happen with unified transcript)
+		if err := h.results.SaveParticipantResult(ctx, result); err != nil {
+			logger.Error("failed to store unified assessment", slog.String("error", err.Error()))
+		}
+
+		publisher.add(assessmentService.ConsolidatedParticipantResult{
+			ParticipantID:   "unified",
+			ParticipantName: "All Speakers",
+			Results:         result.Results,
+		})
+	}
+
+	publisher.done(ctx, "unified")
+	logger.Info("unified assessment completed")
+	return nil
+}
+
+// assessParticipantsInParallel assesses each participant with the full conversation as
+// context, running up to participantParallelism LLM calls at once
+func (h *SonioxHandler) assessParticipantsInParallel(ctx context.Context, logger *assessmentService.AssessmentLogger, sessionID string, conversation []ConsolidatedTranscriptParticipant, fullConversation string, criteria []assessmentService.AssessmentCriteria, publisher *consolidatedPublisher) error {
+	for _, participant := range conversation {
+		publisher.expect(participant.ParticipantID)
+	}
+
+	slots := make(chan struct{}, h.participantParallelism)
+	var wg sync.WaitGroup
+	for _, participant := range conversation {
+		wg.Add(1)
+		go func(participant ConsolidatedTranscriptParticipant) {
+			defer wg.Done()
+
+			select {
+			case slots <- struct{}{}:
+				defer func() { <-slots }()
+			case <-ctx.Done():
+				return
+			}
+
+			// Create assessment request with full context
+			assessmentReq := assessmentService.AssessmentRequest{
+				ParticipantID: participant.ParticipantID,
+				SessionID:     sessionID,
+				Transcript:    participant.Transcript,
+				Criteria:      criteria,
+				Language:      "vietnamese",
+				Context:       fullConversation, // Add full conversation as context
+			}
+
+			// Process assessment using LLM service
+			result, err := h.llmService.ProcessAssessment(ctx, assessmentReq)
+			if err != nil {
+				logger.Error("participant assessment failed", slog.String("participant_id", participant.ParticipantID), slog.String("error", err.Error()))
+				publisher.done(ctx, participant.ParticipantID)
+				return
+			}
+
+			// Store individual result
+			if err := h.results.SaveParticipantResult(ctx, result); err != nil {
+				logger.Error("failed to store participant assessment", slog.String("participant_id", participant.ParticipantID), slog.String("error", err.Error()))
+			}
+
+			publisher.add(assessmentService.ConsolidatedParticipantResult{
+				ParticipantID:   participant.ParticipantID,
+				ParticipantName: participant.ParticipantName,
+				Results:         result.Results,
+			})
+			publisher.done(ctx, participant.ParticipantID)
+
+			logger.Info("participant assessment completed", slog.String("participant_id", participant.ParticipantID))
+		}(participant)
+	}
+	wg.Wait()
+
+	// Retry the job rather than publish a consolidated result with nobody in it
+	if len(publisher.assessments()) == 0 && len(conversation) > 0 && ctx.Err() == nil {
+		return fmt.Errorf("all %d participant assessments failed", len(conversation))
+	}
+	return nil
+}
+
+// processGroupAssessment runs and stores the group assessment; it returns nil if the assessment fails
+func (h *SonioxHandler) processGroupAssessment(ctx context.Context, logger *assessmentService.AssessmentLogger, sessionID, fullConversation string) *assessmentService.GroupAssessmentResponse {
+	logger.Info("group assessment started")
+	groupAssessmentReq := assessmentService.GroupAssessmentRequest{
+		SessionID:  sessionID,
+		Transcript: fullConversation,
+		Criteria:   getGroupAssessmentCriteria(),
+		Language:   "vietnamese",
+	}
+
+	groupResult, err := h.llmService.ProcessGroupAssessment(ctx, groupAssessmentReq)
+	if err != nil {
+		// Continue without group assessment if it fails
+		logger.Error("group assessment failed", slog.String("error", err.Error()))
+		return nil
+	}
+
+	logger.Info("group assessment completed")
+	if err := h.results.SaveGroupResult(ctx, groupResult); err != nil {
+		logger.Error("failed to store group assessment", slog.String("error", err.Error()))
+	}
+	return groupResult
+}
+
+// consolidatedPublisher collects assessments as they finish and stores a partial
+// consolidated result after each one, so the UI can show results before the job ends
+type consolidatedPublisher struct {
+	mu        sync.Mutex
+	results   assessmentService.ResultRepository
+	logger    *assessmentService.AssessmentLogger
+	sessionID string
+	order     []string
+	byID      map[string]assessmentService.ConsolidatedParticipantResult
+	pending   map[string]bool
+	groupDone bool
+	groupData *assessmentService.GroupAssessmentResponse
+}
+
+func newConsolidatedPublisher(results assessmentService.ResultRepository, logger *assessmentService.AssessmentLogger, sessionID string) *consolidatedPublisher {
+	return &consolidatedPublisher{
+		results:   results,
+		logger:    logger,
+		sessionID: sessionID,
+		byID:      make(map[string]assessmentService.ConsolidatedParticipantResult),
+		pending:   make(map[string]bool),
+	}
+}
+
+// expect registers an assessment that has not finished yet
+func (p *consolidatedPublisher) expect(id string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.pending[id] = true
+}
+
+// add records a participant's result, keeping first-seen order
+func (p *consolidatedPublisher) add(result assessmentService.ConsolidatedParticipantResult) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, ok := p.byID[result.ParticipantID]; !ok {
+		p.order = append(p.order, result.ParticipantID)
+	}
+	p.byID[result.ParticipantID] = result
+}
+
+// done marks an assessment finished, successfully or not, and publishes progress
+func (p *consolidatedPublisher) done(ctx context.Context, id string) {
+	p.mu.Lock()
+	delete(p.pending, id)
+	p.mu.Unlock()
+	p.publish(ctx)
+}
+
+// group records the group result and publishes progress
+func (p *consolidatedPublisher) group(ctx context.Context, result *assessmentService.GroupAssessmentResponse) {
+	p.mu.Lock()
+	p.groupDone = true
+	p.groupData = result
+	p.mu.Unlock()
+	p.publish(ctx)
+}
+
+// assessments returns the participant results collected so far
+func (p *consolidatedPublisher) assessments() []assessmentService.ConsolidatedParticipantResult {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	results := make([]assessmentService.ConsolidatedParticipantResult, 0, len(p.order))
+	for _, id := range p.order {
+		results = append(results, p.byID[id])
+	}
+	return results
+}
+
+// publish stores the results collected so far as a partial consolidated result
+func (p *consolidatedPublisher) publish(ctx context.Context) {
+	if ctx.Err() != nil {
+		return
+	}
+
+	results := p.assessments()
+	p.mu.Lock()
+	pending := make([]string, 0, len(p.pending)+1)
+	for id := range p.pending {
+		pending = append(pending, id)
+	}
+	if !p.groupDone {
+		pending = append(pending, "group")
+	}
+	partial := &assessmentService.ConsolidatedResult{
+		SessionID:       p.sessionID,
+		Assessments:     results,
+		GroupAssessment: p.groupData,
+		Timestamp:       time.Now().Unix(),
+		Partial:         true,
+		Pending:         pending,
+	}
+	// Serialize saves so an older snapshot never overwrites a newer one
+	defer p.mu.Unlock()
+
+	sort.Strings(partial.Pending)
+	if err := p.results.SaveConsolidatedResult(ctx, partial); err != nil {
+		p.logger.Error("failed to publish partial consolidated assessment", slog.String("error", err.Error()))
+	}
+}
+
+// GetConsolidatedAssessmentResults retrieves consolidated assessment results for all participants
+func (h *SonioxHandler) GetConsolidatedAssessmentResults(c *gin.Context) {
+	sessionID := c.Param("id")
+	
+	if sessionID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "session_id is required"})
+		return
+	}
+
+	// Validate session ID (allow "test" for demo)
+	if sessionID != "test" {
+		if _, err := uuid.Parse(sessionID); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid session_id format"})
+			return
+		}
+	}
+
+	// Try to get consolidated results first
+	ctx := c.Request.Context()
+	consolidated, err := h.results.GetConsolidatedResult(ctx, sessionID)
+	if err == nil {
+		c.JSON(http.StatusOK, consolidated)
+		return
+	}
+	if !errors.Is(err, assessmentService.ErrResultNotFound) {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load assessment results"})
+		return
+	}
+
+	// If no consolidated results, try to build from individual results
+	participantResults, err := h.results.ListParticipantResults(ctx, sessionID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load assessment results"})
+		return
+	}
+
+	if len(participantResults) > 0 {
+		results := make([]assessmentService.ConsolidatedParticipantResult, 0, len(participantResults))
+		for _, assessmentResult := range participantResults {
+			results = append(results, assessmentService.ConsolidatedParticipantResult{
+				ParticipantID: assessmentResult.ParticipantID,
+				Results:       assessmentResult.Results,
+			})
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"session_id": sessionID,
+			"assessments": results,
+		})
+		return
+	}
+
+	// No results found yet
+	job, err := h.jobs.Latest(ctx, sessionID, assessmentService.JobTypeConsolidatedAssessment, "")
+	h.respondJobState(c, job, err)
+}
+
+// SubmitAsyncTranscriptionRequest represents a request to start async transcription
+type SubmitAsyncTranscriptionRequest struct {
+	SessionID         string         `json:"session_id" binding:"required"`
+	SpeakerMapping    map[int]string `json:"speaker_mapping"`    // Manual speaker corrections
+	ManualCorrections map[int]string `json:"manual_corrections"` // Track which speakers were manually corrected
+	DurationMs        int64          `json:"duration_ms"`        // Recording length, checked before the audio is uploaded
+	// Roster the diarized speakers are bound to; defaults to the participants of the live transcript
+	ParticipantMapping []ParticipantMapping `json:"participant_mapping"`
+	// Speaker numbers bound to participant UUIDs on the session roster
+	SpeakerBindings map[int]string `json:"speaker_bindings"`
+	// Case study whose vocabulary is used; defaults to the session's
+	CaseStudy string `json:"case_study"`
+	// Language hints replacing the case study's, and terms added to its vocabulary
+	LanguageHints []string `json:"language_hints"`
+	Vocabulary    []string `json:"vocabulary"`
+}
+
+// SubmitAsyncTranscription submits audio file for async transcription with Soniox. The
+// multipart body is read part by part and the audio streamed straight through to Soniox,
+// so a long recording is never held in memory. Audio over the size or duration limit is
+// refused with 413, and audio that doesn't sniff as a supported format with 415. Fields
+// sent before the audio are validated before it is uploaded; audio uploaded for a
+// submission that then fails is deleted from Soniox.
+func (h *SonioxHandler) SubmitAsyncTranscription(c *gin.Context) {
+	// Refuse a declared body over the limit before reading any of it
+	if c.Request.ContentLength > h.audioLimits.MaxBytes+maxAsyncFormFieldBytes {
+		respondAudioUploadError(c, fmt.Errorf("%w: over %d MB", assessmentService.ErrAudioTooLarge, h.audioLimits.MaxBytes>>20))
+		return
+	}
+
+	form, err := c.Request.MultipartReader()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "multipart form data is required"})
+		return
+	}
+
+	// Fields may come before or after the audio; the audio is uploaded as soon as it arrives
+	ctx := c.Request.Context()
+	fields := make(map[string]string)
+	var sessionID string
+	var upload *audioUpload
+	var submission *asyncSubmission
+	fieldsAfterAudio := false
+	for {
+		part, err := form.NextPart()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "failed to read form data"})
+			h.abandonSubmission(ctx, sessionID, upload, "")
+			return
+		}
+
+		if part.FormName() != "audio" {
+			value, err := io.ReadAll(io.LimitReader(part, maxAsyncFormFieldBytes))
+			part.Close()
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "failed to read form data"})
+				h.abandonSubmission(ctx, sessionID, upload, "")
+				return
+			}
+			fields[part.FormName()] = string(value)
+			fieldsAfterAudio = upload != nil
+			continue
+		}
+
+		if upload != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "only one audio file may be submitted"})
+			h.abandonSubmission(ctx, sessionID, upload, "")
+			return
+		}
+		sessionID = fields["session_id"]
+		if sessionID == "" {
+			sessionID = c.Param("id")
+		}
+		if sessionID == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "session_id is required"})
+			return
+		}
+
+		// The browser knows the recording length; check it before uploading anything
+		if durationMs, err := strconv.ParseInt(fields["duration_ms"], 10, 64); err == nil {
+			if err := h.audioLimits.CheckDuration(time.Duration(durationMs) * time.Millisecond); err != nil {
+				respondAudioUploadError(c, err)
+				return
+			}
+		}
+		var ok bool
+		if submission, ok = h.prepareAsyncSubmission(c, sessionID, fields); !ok {
+			return
+		}
+
+		upload, err = h.streamAudio(ctx, h.logger.ForSession(sessionID), part.FileName(), part)
+		part.Close()
+		if err != nil {
+			respondAudioUploadError(c, err)
+			return
+		}
+	}
+	if upload == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "audio file is required"})
+		return
+	}
+	if fieldsAfterAudio {
+		var ok bool
+		if submission, ok = h.prepareAsyncSubmission(c, sessionID, fields); !ok {
+			h.abandonSubmission(ctx, sessionID, upload, "")
+			return
+		}
+	}
+
+	logger := h.logger.ForSession(sessionID)
+	logger.Info("audio file uploaded, creating transcription", slog.String("file_id", upload.FileID))
+
+	transcriptionReq := assessmentService.TranscriptionRequest{
+		FileID:        upload.FileID,
+		Diarization:   true,
+		LanguageHints: submission.vocabulary.LanguageHints,
+		Context:       submission.vocabulary.Context(),
+	}
+
+	// Have the backend call us back on completion, authenticated with a secret only this job knows
+	var webhookSecret string
+	if h.sonioxWebhookBaseURL != "" {
+		webhookSecret, err = assessmentService.NewTranscriptionWebhookSecret()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create webhook secret"})
+			h.abandonSubmission(ctx, sessionID, upload, "")
+			return
+		}
+		transcriptionReq.WebhookURL = h.sonioxWebhookBaseURL + "/api/v1/soniox/webhook/" + sessionID
+		transcriptionReq.WebhookAuthHeader = assessmentService.SonioxWebhookAuthHeader
+		transcriptionReq.WebhookAuthValue = webhookSecret
+	}
+
+	transcriptionID, err := h.transcriber.CreateTranscription(ctx, transcriptionReq)
+	if err != nil {
+		logger.Error("transcription creation failed", slog.String("transcriber", h.transcriber.Name()), slog.String("error", err.Error()))
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "transcription creation error",
+			"details": err.Error(),
+		})
+		h.abandonSubmission(ctx, sessionID, upload, "")
+		return
+	}
+
+	// The transcription this one replaces is recorded as pending deletion before its row is
+	// overwritten, so its remote data can't be lost track of
+	replaced, err := h.results.GetTranscriptionJob(ctx, sessionID)
+	if err != nil && !errors.Is(err, assessmentService.ErrResultNotFound) {
+		logger.Error("failed to load replaced transcription job", slog.String("error", err.Error()))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to store transcription job"})
+		h.abandonSubmission(ctx, sessionID, upload, transcriptionID)
+		return
+	}
+	if replaced != nil && (replaced.TranscriptionID == transcriptionID || !replaced.HasRemoteData()) {
+		replaced = nil
+	}
+	if replaced != nil {
+		if err := h.retention.Orphan(ctx, replaced, assessmentService.DeletionReplaced); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to store transcription job"})
+			h.abandonSubmission(ctx, sessionID, upload, transcriptionID)
+			return
+		}
+	}
+
+	// Store transcription ID and speaker mapping for later retrieval
+	if err := h.results.SaveTranscriptionJob(ctx, assessmentService.TranscriptionJob{
+		SessionID:         sessionID,
+		TranscriptionID:   transcriptionID,
+		FileID:            upload.FileID,
+		SpeakerMapping:    submission.speakerMapping,
+		ManualCorrections: submission.manualCorrections,
+		SpeakerBindings:   submission.speakerBindings,
+		Participants:      submission.roster,
+		Status:            assessmentService.TranscriptionProcessing,
+		WebhookSecret:     webhookSecret,
+		CreatedAt:         time.Now().UTC(),
+	}); err != nil {
+		logger.Error("failed to store transcription job", slog.String("error", err.Error()))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to store transcription job"})
+		h.abandonSubmission(ctx, sessionID, upload, transcriptionID)
+		return
+	}
+
+	logger.Info("async transcription submitted", slog.String("transcription_id", transcriptionID), slog.String("file_id", upload.FileID),
+		slog.String("format", upload.Format.Container), slog.Bool("webhook", webhookSecret != ""), slog.String("case_study", submission.vocabulary.CaseStudyID))
+	if replaced != nil {
+		// Failures are logged by the retention enforcer and retried by its sweep
+		h.retention.Delete(ctx, replaced, assessmentService.DeletionReplaced)
+	}
+	h.transcriptionChecks.Store(sessionID, time.Now())
+	h.events.Publish(ctx, assessmentService.NewAssessmentEvent(assessmentService.EventTranscriptionSubmitted, sessionID, gin.H{
+		"transcription_id": transcriptionID,
+	}))
+
+	c.JSON(http.StatusOK, gin.H{
+		"transcription_id": transcriptionID,
+		"file_id":          upload.FileID,
+		"case_study":       submission.vocabulary.CaseStudyID,
+		"status":           "processing",
+	})
+}
+
+// asyncSubmission is the validated form of an async transcription submission
+type asyncSubmission struct {
+	speakerMapping    map[int]string
+	manualCorrections map[int]string
+	speakerBindings   map[int]string
+	roster            []assessmentService.SessionParticipant
+	vocabulary        assessmentService.TranscriptionVocabulary
+}
+
+// prepareAsyncSubmission validates the non-audio fields of an async transcription
+// submission and resolves its roster and vocabulary. On failure it responds and
+// returns false.
+func (h *SonioxHandler) prepareAsyncSubmission(c *gin.Context, sessionID string, fields map[string]string) (*asyncSubmission, bool) {
+	ctx := c.Request.Context()
+	submission := &asyncSubmission{}
+	var err error
+	submission.speakerMapping, err = parseSpeakerMap(fields["speaker_mapping"])
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid speaker_mapping format"})
+		return nil, false
+	}
+	submission.manualCorrections, err = parseSpeakerMap(fields["manual_corrections"])
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid manual_corrections format"})
+		return nil, false
+	}
+	var participantMapping []ParticipantMapping
+	if raw := fields["participant_mapping"]; raw != "" {
+		if err := json.Unmarshal([]byte(raw), &participantMapping); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid participant_mapping format"})
+			return nil, false
+		}
+	}
+	submission.speakerBindings, err = parseSpeakerMap(fields["speaker_bindings"])
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid speaker_bindings format"})
+		return nil, false
+	}
+	for _, participantID := range submission.speakerBindings {
+		if _, err := uuid.Parse(participantID); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid participant_id format in speaker_bindings"})
+			return nil, false
+		}
+	}
+	var languageHints, terms []string
+	if raw := fields["language_hints"]; raw != "" {
+		if err := json.Unmarshal([]byte(raw), &languageHints); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid language_hints format"})
+			return nil, false
+		}
+	}
+	if raw := fields["vocabulary"]; raw != "" {
+		if err := json.Unmarshal([]byte(raw), &terms); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid vocabulary format"})
+			return nil, false
+		}
+	}
+
+	submission.roster, err = h.submissionRoster(ctx, sessionID, sessionParticipants(participantMapping))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load session roster"})
+		return nil, false
+	}
+	if err := assessmentService.ValidateSpeakerBindings(submission.speakerBindings, submission.roster); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return nil, false
+	}
+
+	// Participants' names are the words the transcript most needs to get right
+	for _, participant := range submission.roster {
+		terms = append(terms, participant.Name)
+	}
+	submission.vocabulary, err = h.transcriptionVocabulary(ctx, sessionID, assessmentService.SessionVocabulary{
+		CaseStudyID:   fields["case_study"],
+		LanguageHints: languageHints,
+		Terms:         terms,
+	})
+	if err != nil {
+		respondVocabularyError(c, h.logger.ForSession(sessionID), err)
+		return nil, false
+	}
+	return submission, true
+}
+
+// abandonSubmission deletes the audio, and the transcription if one was created, of a
+// submission that failed before its job was stored. The deletion runs detached from the
+// request, which the client may already have dropped.
+func (h *SonioxHandler) abandonSubmission(ctx context.Context, sessionID string, upload *audioUpload, transcriptionID string) {
+	if upload == nil {
+		return
+	}
+	h.retention.Abandon(context.WithoutCancel(ctx), &assessmentService.TranscriptionJob{
+		SessionID:       sessionID,
+		FileID:          upload.FileID,
+		TranscriptionID: transcriptionID,
+	})
+}
+
+// transcriptionVocabulary is the vocabulary a session is transcribed with: the requested
+// case study, else the session's, else the default. Requested language hints replace the
+// session's and requested terms are added to them.
+func (h *SonioxHandler) transcriptionVocabulary(ctx context.Context, sessionID string, requested assessmentService.SessionVocabulary) (assessmentService.TranscriptionVocabulary, error) {
+	var session assessmentService.SessionVocabulary
+	if h.sessionVocabulary != nil {
+		var err error
+		if session, err = h.sessionVocabulary(ctx, sessionID); err != nil {
+			return assessmentService.TranscriptionVocabulary{}, err
+		}
+	}
+	if strings.TrimSpace(requested.CaseStudyID) != "" {
+		session.CaseStudyID = requested.CaseStudyID
+	}
+	if len(requested.LanguageHints) > 0 {
+		session.LanguageHints = requested.LanguageHints
+	}
+	session.Terms = append(session.Terms, requested.Terms...)
+	return h.vocabulary.Vocabulary(session.CaseStudyID, session)
+}
+
+// respondVocabularyError reports an unknown case study with 422 and a failed session
+// lookup with 500
+func respondVocabularyError(c *gin.Context, logger *assessmentService.AssessmentLogger, err error) {
+	if errors.Is(err, assessmentService.ErrUnknownCaseStudy) {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	}
+	logger.Error("failed to load session vocabulary", slog.String("error", err.Error()))
+	c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load session vocabulary"})
+}
+
+// submissionRoster is the roster an async transcript's speakers are bound against: the
+// configured session roster, else the one submitted with it, else the live transcript's.
+// Submitted participants missing from the configured roster are dropped.
+func (h *SonioxHandler) submissionRoster(ctx context.Context, sessionID string, submitted []assessmentService.SessionParticipant) ([]assessmentService.SessionParticipant, error) {
+	if h.sessionRoster == nil {
+		if len(submitted) > 0 {
+			return submitted, nil
+		}
+		return h.liveRoster(ctx, sessionID)
+	}
+
+	roster, err := h.sessionRoster(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	// Keep the submitted roles and departments of participants the session does have
+	details := make(map[string]assessmentService.SessionParticipant, len(submitted))
+	for _, participant := range submitted {
+		details[participant.ID] = participant
+	}
+	for i, participant := range roster {
+		if detail, ok := details[participant.ID]; ok {
+			if participant.Role == "" {
+				roster[i].Role = detail.Role
+			}
+			if participant.Department == "" {
+				roster[i].Department = detail.Department
+			}
+		}
+	}
+	return roster, nil
+}
+
+// maxAsyncFormFieldBytes bounds each non-audio field of an async transcription upload
+const maxAsyncFormFieldBytes = 1 << 20
+
+// parseSpeakerMap decodes a speaker_mapping, manual_corrections or speaker_bindings form field. JSON keys
+// are always strings, so they are converted to speaker numbers here.
+func parseSpeakerMap(raw string) (map[int]string, error) {
+	speakers := make(map[int]string)
+	if raw == "" {
+		return speakers, nil
+	}
+	var byKey map[string]string
+	if err := json.Unmarshal([]byte(raw), &byKey); err != nil {
+		return nil, err
+	}
+	for key, value := range byKey {
+		var speakerID int
+		if _, err := fmt.Sscanf(key, "%d", &speakerID); err == nil {
+			speakers[speakerID] = value
+		}
+	}
+	return speakers, nil
+}
+
+// audioUpload is an audio file streamed to the transcription backend
+type audioUpload struct {
+	FileID string
+	Format assessmentService.AudioFormat
+	Bytes  int64
+}
+
+// streamAudio sniffs the audio and streams it to the transcription backend. Going over
+// the size limit aborts the upload.
+func (h *SonioxHandler) streamAudio(ctx context.Context, logger *assessmentService.AssessmentLogger, filename string, audio io.Reader) (*audioUpload, error) {
+	buffered := bufio.NewReaderSize(audio, assessmentService.AudioSniffLen)
+	header, err := buffered.Peek(assessmentService.AudioSniffLen)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to read audio: %w", err)
+	}
+	if len(header) == 0 {
+		return nil, fmt.Errorf("%w: empty file", assessmentService.ErrUnsupportedAudio)
+	}
+	format, err := assessmentService.SniffAudio(header)
+	if err != nil {
+		return nil, err
+	}
+	if err := h.audioLimits.CheckDuration(format.Duration); err != nil {
+		return nil, err
+	}
+	if filename == "" {
+		filename = "session-audio." + format.Container
+	}
+	logger.Info("streaming audio file", slog.String("transcriber", h.transcriber.Name()),
+		slog.String("container", format.Container), slog.String("codec", format.Codec))
+
+	counted := &countingReader{r: h.audioLimits.Limit(buffered)}
+	fileID, err := h.transcriber.UploadAudio(ctx, filename, counted)
+	if err != nil {
+		if !errors.Is(err, assessmentService.ErrAudioTooLarge) {
+			logger.Error("audio file upload failed", slog.String("error", err.Error()))
+		}
+		return nil, err
+	}
+	logger.Info("audio file streamed", slog.Int64("bytes", counted.n), slog.String("file_id", fileID))
+	return &audioUpload{FileID: fileID, Format: format, Bytes: counted.n}, nil
+}
+
+// respondAudioUploadError maps an audio upload failure to its HTTP status
+func respondAudioUploadError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, assessmentService.ErrAudioTooLarge), errors.Is(err, assessmentService.ErrAudioTooLong):
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": err.Error()})
+	case errors.Is(err, assessmentService.ErrUnsupportedAudio):
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to upload audio file", "details": err.Error()})
+	}
+}
+
+// countingReader counts the bytes read through it
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.n += int64(n)
+	return n, err
+}
+
+// GetAsyncTranscriptionStatus handles GET /api/v1/sessions/:id/async-transcription/status.
+// It reads the job from our own store; the transcriber is only asked directly, at most every
+// transcriptionCheckInterval, while a job is processing and its webhook hasn't arrived.
+// pause_ms, max_duration_ms and split_sentences override how segments are split.
+func (h *SonioxHandler) GetAsyncTranscriptionStatus(c *gin.Context) {
+	sessionID := c.Param("id")
+	if sessionID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "session_id is required"})
+		return
+	}
+
+	// Get transcription job
+	ctx := c.Request.Context()
+	job, err := h.results.GetTranscriptionJob(ctx, sessionID)
+	if errors.Is(err, assessmentService.ErrResultNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "no async transcription found for this session"})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load transcription job"})
+		return
+	}
+
+	segmentation, err := h.segmentationFromQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if job.Status == assessmentService.TranscriptionProcessing && h.dueTranscriptionCheck(sessionID) {
+		logger := h.logger.ForSession(sessionID).With(slog.String("transcription_id", job.TranscriptionID))
+		status, err := h.transcriber.TranscriptionStatus(ctx, job.TranscriptionID)
+		switch {
+		case err != nil:
+			// Keep reporting processing; the webhook or the next check will catch up
+			logger.Warn("failed to check transcription status", slog.String("error", err.Error()))
+		case status.Status == assessmentService.TranscriptionCompleted:
+			if err := h.completeTranscription(ctx, logger, job); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch transcript", "details": err.Error()})
+				return
+			}
+		case status.Status == assessmentService.TranscriptionFailed:
+			if err := h.failTranscription(ctx, logger, job, status.ErrorMessage); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to store transcription status"})
+				return
+			}
+		}
+	}
+
+	switch job.Status {
+	case assessmentService.TranscriptionCompleted:
+		c.JSON(http.StatusOK, gin.H{
+			"status":            "completed",
+			"transcription_id":  job.TranscriptionID,
+			"segments":          job.Segments(segmentation),
+			"speaker_names":     job.SpeakerNames,
+			"speakers_named":    job.SpeakersNamedAt != nil,
+			"assessment_job_id": job.AssessmentJobID,
+		})
+	case assessmentService.TranscriptionFailed:
+		c.JSON(http.StatusOK, gin.H{
+			"status":        "error",
+			"error_message": job.ErrorMessage,
+		})
+	default:
+		c.JSON(http.StatusAccepted, gin.H{
+			"status":           "processing",
+			"transcription_id": job.TranscriptionID,
+		})
+	}
+}
+
+// segmentationFromQuery applies the pause_ms, max_duration_ms and split_sentences query
+// parameters over the default segmentation
+func (h *SonioxHandler) segmentationFromQuery(c *gin.Context) (assessmentService.SegmentationConfig, error) {
+	config := h.segmentation
+	if raw := c.Query("pause_ms"); raw != "" {
+		pause, err := strconv.Atoi(raw)
+		if err != nil || pause < 0 {
+			return config, errors.New("pause_ms must be a non-negative integer")
+		}
+		config.PauseMs = pause
+	}
+	if raw := c.Query("max_duration_ms"); raw != "" {
+		maxDuration, err := strconv.Atoi(raw)
+		if err != nil || maxDuration < 0 {
+			return config, errors.New("max_duration_ms must be a non-negative integer")
+		}
+		config.MaxDurationMs = maxDuration
+	}
+	if raw := c.Query("split_sentences"); raw != "" {
+		sentences, err := strconv.ParseBool(raw)
+		if err != nil {
+			return config, errors.New("split_sentences must be true or false")
+		}
+		config.SplitSentences = sentences
+	}
+	return config, nil
+}
+
+// ExportTranscript handles GET /api/v1/sessions/:id/transcript/export?format=srt|vtt|docx|txt|md,
+// downloading the session's async transcript with speaker names applied. Segmentation
+// can be overridden as for GetAsyncTranscriptionStatus. X-Transcript-SHA256 fingerprints
+// the file; exporting the same transcript again gives identical bytes.
+func (h *SonioxHandler) ExportTranscript(c *gin.Context) {
+	sessionID := c.Param("id")
+	if sessionID != "test" {
+		if _, err := uuid.Parse(sessionID); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid session_id format"})
+			return
+		}
+	}
+	segmentation, err := h.segmentationFromQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	job, err := h.results.GetTranscriptionJob(c.Request.Context(), sessionID)
+	if errors.Is(err, assessmentService.ErrResultNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "no async transcription found for this session"})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load transcription job"})
+		return
+	}
+	if job.Status != assessmentService.TranscriptionCompleted {
+		c.JSON(http.StatusConflict, gin.H{"error": "transcript is not ready", "status": job.Status})
+		return
+	}
+
+	transcribedAt := job.CreatedAt
+	if job.CompletedAt != nil {
+		transcribedAt = *job.CompletedAt
+	}
+	export, err := assessmentService.ExportTranscript(c.DefaultQuery("format", assessmentService.ExportText), assessmentService.TranscriptDocument{
+		SessionID:       sessionID,
+		TranscriptionID: job.TranscriptionID,
+		TranscribedAt:   transcribedAt,
+		Segments:        job.Segments(segmentation),
+	})
+	if errors.Is(err, assessmentService.ErrUnknownExportFormat) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	} else if err != nil {
+		h.logger.ForSession(sessionID).Error("failed to export transcript", slog.String("error", err.Error()))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to export transcript"})
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, export.Filename))
+	c.Header("X-Transcript-SHA256", export.SHA256)
+	c.Data(http.StatusOK, export.ContentType, export.Body)
+}
+
+// SonioxWebhookRequest is the body Soniox posts when an async transcription finishes
+type SonioxWebhookRequest struct {
+	ID     string `json:"id"`
+	Status string `json:"status"`
+}
+
+// SonioxWebhook handles POST /api/v1/soniox/webhook/:id, Soniox's callback when a
+// session's async transcription completes or fails. It is authenticated by the job's
+// secret in SonioxWebhookAuthHeader rather than a user token. The transcript is fetched
+// and stored right away so status reads never go to Soniox.
+func (h *SonioxHandler) SonioxWebhook(c *gin.Context) {
+	sessionID := c.Param("id")
+	ctx := c.Request.Context()
+
+	job, err := h.results.GetTranscriptionJob(ctx, sessionID)
+	if errors.Is(err, assessmentService.ErrResultNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "no async transcription found for this session"})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load transcription job"})
+		return
+	}
+
+	if !assessmentService.VerifyTranscriptionWebhookSecret(job, c.GetHeader(assessmentService.SonioxWebhookAuthHeader)) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid webhook credentials"})
+		return
+	}
+
+	var req SonioxWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	logger := h.logger.ForSession(sessionID).With(slog.String("transcription_id", req.ID))
+	// A callback for a transcription the session has since replaced, or one already
+	// handled by a status check, is acknowledged so Soniox stops retrying
+	if req.ID != job.TranscriptionID || job.Status != assessmentService.TranscriptionProcessing {
+		logger.Info("ignoring Soniox webhook", slog.String("status", req.Status), slog.String("job_status", job.Status))
+		c.JSON(http.StatusOK, gin.H{"status": "ignored"})
+		return
+	}
+
+	switch req.Status {
+	case assessmentService.TranscriptionCompleted:
+		if err := h.completeTranscription(ctx, logger, job); err != nil {
+			// A non-2xx answer has Soniox deliver the callback again
+			c.JSON(http.StatusBadGateway, gin.H{"error": "failed to fetch transcript", "details": err.Error()})
+			return
+		}
+	case assessmentService.TranscriptionFailed:
+		// The callback only carries the status; the reason comes from the transcription
+		var errorMessage string
+		if status, err := h.transcriber.TranscriptionStatus(ctx, job.TranscriptionID); err != nil {
+			logger.Warn("failed to read transcription error message", slog.String("error", err.Error()))
+		} else {
+			errorMessage = status.ErrorMessage
+		}
+		if err := h.failTranscription(ctx, logger, job, errorMessage); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to store transcription status"})
+			return
+		}
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "unknown transcription status: " + req.Status})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": job.Status})
+}
+
+// transcriptionCheckInterval spaces out direct transcriber status checks from status reads. With a
+// webhook configured they are only a fallback for lost callbacks.
+func (h *SonioxHandler) transcriptionCheckInterval() time.Duration {
+	if h.sonioxWebhookBaseURL != "" {
+		return 2 * time.Minute
+	}
+	return 10 * time.Second
+}
+
+// dueTranscriptionCheck reports whether a session's transcription should be checked with the transcriber
+// now, and if so records the check
+func (h *SonioxHandler) dueTranscriptionCheck(sessionID string) bool {
+	now := time.Now()
+	if last, ok := h.transcriptionChecks.Load(sessionID); ok && now.Sub(last.(time.Time)) < h.transcriptionCheckInterval() {
+		return false
+	}
+	h.transcriptionChecks.Store(sessionID, now)
+	return true
+}
+
+// completeTranscription fetches a finished transcript from the transcriber, stores it on the job
+// and announces completion the first time
+func (h *SonioxHandler) completeTranscription(ctx context.Context, logger *assessmentService.AssessmentLogger, job *assessmentService.TranscriptionJob) error {
+	logger.Info("transcription completed, fetching transcript")
+	tokens, err := h.transcriber.Transcript(ctx, job.TranscriptionID)
+	if err != nil {
+		logger.Error("failed to fetch transcript", slog.String("error", err.Error()))
+		return err
+	}
+
+	announce := job.CompletedAt == nil
+	job.Tokens = tokens
+	job.Status = assessmentService.TranscriptionCompleted
+	job.ErrorMessage = ""
+	if announce {
+		completedAt := time.Now().UTC()
+		job.CompletedAt = &completedAt
+	}
+	if err := h.results.SaveTranscriptionJob(ctx, *job); err != nil {
+		logger.Error("failed to store transcript", slog.String("error", err.Error()))
+		return err
+	}
+	h.transcriptionChecks.Delete(job.SessionID)
+	// Failures are logged and left to the retention sweep
+	h.retention.AfterRetrieval(ctx, job)
+
+	segments := job.Segments(h.segmentation)
+	logger.Info("async transcription stored", slog.Int("tokens", len(tokens)), slog.Int("segments", len(segments)))
+	if announce {
+		h.events.Publish(ctx, assessmentService.NewAssessmentEvent(assessmentService.EventTranscriptionCompleted, job.SessionID, gin.H{
+			"transcription_id": job.TranscriptionID,
+			"segment_count":    len(segments),
+		}))
+	}
+
+	// The transcript is stored; a failure to queue its speaker naming is retried from the
+	// pipeline status rather than failing the transcription
+	if err := h.queueSpeakerNaming(ctx, job); err != nil {
+		logger.Error("failed to queue speaker naming of async transcript", slog.String("error", err.Error()))
+	}
+	return nil
+}
+
+// queueSpeakerNaming queues naming the speakers of a finished async transcript, which
+// queues its assessment when done. Completion can be seen by both the webhook and a
+// status check; both queue one job.
+func (h *SonioxHandler) queueSpeakerNaming(ctx context.Context, job *assessmentService.TranscriptionJob) error {
+	_, _, err := h.jobs.EnqueueIdempotent(ctx, assessmentService.JobTypeSpeakerNaming, job.SessionID, "", "transcription:"+job.TranscriptionID,
+		speakerNamingPayload{TranscriptionID: job.TranscriptionID})
+	return err
+}
+
+// nameSpeakers identifies every diarized speaker of a finished transcript together, from
+// the whole conversation and the session roster, and stores one distinct name per
+// speaker. Speakers the LLM can't identify fall back to the client's mapping or the roster.
+func (h *SonioxHandler) nameSpeakers(ctx context.Context, logger *assessmentService.AssessmentLogger, job *assessmentService.TranscriptionJob) error {
+	roster := job.Participants
+	if len(roster) == 0 {
+		var err error
+		if roster, err = h.liveRoster(ctx, job.SessionID); err != nil {
+			return err
+		}
+	}
+
+	// The conversation labels speakers by number, whatever they have been named so far
+	speakers, transcript := assessmentService.DiarizedConversation(job.Segments(h.segmentation))
+
+	unresolved := 0
+	for _, speaker := range speakers {
+		_, bound := job.SpeakerBindings[speaker]
+		_, corrected := job.ManualCorrections[speaker]
+		if !bound && !corrected {
+			unresolved++
+		}
+	}
+
+	var identified []assessmentService.SpeakerIdentificationResponse
+	if unresolved > 0 {
+		result, err := h.llmService.IdentifySpeakers(ctx, assessmentService.ConversationIdentificationRequest{
+			Transcript: transcript,
+			Speakers:   speakers,
+			Roster:     roster,
+		})
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			logger.Warn("speaker identification failed", slog.String("error", err.Error()))
+		} else {
+			identified = result.Assignments
+		}
+	}
+
+	job.SpeakerNames = job.ResolveSpeakerNames(speakers, identified, roster)
+	namedAt := time.Now().UTC()
+	job.SpeakersNamedAt = &namedAt
+	if err := h.results.SaveTranscriptionJob(ctx, *job); err != nil {
+		logger.Error("failed to store speaker names", slog.String("error", err.Error()))
+		return err
+	}
+
+	for _, name := range job.SpeakerNames {
+		logger.Info("speaker named", slog.Int("speaker", name.Speaker), assessmentService.RedactedName("name", name.Name),
+			slog.String("source", name.Source), slog.String("confidence", name.Confidence))
+	}
+	return nil
+}
+
+// queuePipelineAssessment turns a finished async transcript into conversation turns,
+// binds its speakers to the session roster and queues the consolidated and group
+// assessment, replacing any still running on the live transcript. It returns nil when
+// the transcript has nothing to assess.
+func (h *SonioxHandler) queuePipelineAssessment(ctx context.Context, logger *assessmentService.AssessmentLogger, job *assessmentService.TranscriptionJob) (*assessmentService.Job, error) {
+	roster := job.Participants
+	if len(roster) == 0 {
+		var err error
+		if roster, err = h.liveRoster(ctx, job.SessionID); err != nil {
+			return nil, err
+		}
+	}
+
+	segments := job.Segments(h.segmentation)
+	turns := assessmentService.ConversationTurns(segments, assessmentService.BindSpeakers(segments, roster))
+	if len(turns) == 0 {
+		logger.Info("async transcript has no speech to assess")
+		return nil, nil
+	}
+	payload := consolidatedAssessmentPayload{
+		Conversation:     conversationFromTurns(turns),
+		FullConversation: assessmentService.FormatConversation(turns),
+	}
+
+	// Completion can be seen by both the webhook and a status check; both queue one job
+	key := "transcription:" + job.TranscriptionID
+	assessment, err := h.jobs.FindIdempotent(ctx, assessmentService.JobTypeConsolidatedAssessment, job.SessionID, "", key, payload)
+	if errors.Is(err, assessmentService.ErrJobNotFound) {
+		if _, err := h.jobs.CancelMatching(ctx, job.SessionID, assessmentService.JobTypeConsolidatedAssessment, ""); err != nil {
+			logger.Error("failed to cancel superseded assessment", slog.String("error", err.Error()))
+		}
+		assessment, _, err = h.jobs.EnqueueIdempotent(ctx, assessmentService.JobTypeConsolidatedAssessment, job.SessionID, "", key, payload)
+	}
+	if err != nil {
+		return nil, err
+	}
+	h.consolidatedDebounce.Forget(job.SessionID)
+	h.deferredConsolidated.Delete(job.SessionID)
+
+	if job.AssessmentJobID != assessment.ID {
+		job.AssessmentJobID = assessment.ID
+		if err := h.results.SaveTranscriptionJob(ctx, *job); err != nil {
+			return nil, err
+		}
+		logger.Info("assessment of async transcript queued", slog.String("job_id", assessment.ID),
+			slog.Int("participants", len(payload.Conversation)), slog.Int("turns", len(turns)))
+	}
+	return assessment, nil
+}
+
+// liveRoster is the participants of a session's live transcript syncs
+func (h *SonioxHandler) liveRoster(ctx context.Context, sessionID string) ([]assessmentService.SessionParticipant, error) {
+	records, err := h.results.ListTranscripts(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	roster := []assessmentService.SessionParticipant{}
+	seen := make(map[string]bool)
+	for _, record := range records {
+		if record.ParticipantID == "" || record.ParticipantID == "unified" || seen[record.ParticipantID] {
+			continue
+		}
+		seen[record.ParticipantID] = true
+		roster = append(roster, assessmentService.SessionParticipant{
+			ID:   record.ParticipantID,
+			Name: record.ParticipantName,
+			Role: record.Role,
+		})
+	}
+	return roster, nil
+}
+
+// conversationFromTurns collects each participant's turns, in order of first speaking
+func conversationFromTurns(turns []assessmentService.ConversationTurn) []ConsolidatedTranscriptParticipant {
+	conversation := []ConsolidatedTranscriptParticipant{}
+	index := make(map[string]int)
+	for _, turn := range turns {
+		i, ok := index[turn.ParticipantID]
+		if !ok {
+			i = len(conversation)
+			index[turn.ParticipantID] = i
+			conversation = append(conversation, ConsolidatedTranscriptParticipant{
+				ParticipantID:   turn.ParticipantID,
+				ParticipantName: turn.Name,
+				Role:            turn.Role,
+			})
+		}
+		if conversation[i].Transcript != "" {
+			conversation[i].Transcript += "\n"
+		}
+		conversation[i].Transcript += turn.Text
+	}
+	return conversation
+}
+
+// sessionParticipants converts a participant mapping to the session roster
+func sessionParticipants(mapping []ParticipantMapping) []assessmentService.SessionParticipant {
+	roster := make([]assessmentService.SessionParticipant, 0, len(mapping))
+	for _, participant := range mapping {
+		roster = append(roster, assessmentService.SessionParticipant{
+			ID:         participant.ID,
+			Name:       participant.Name,
+			Role:       participant.Role,
+			Department: participant.Department,
+		})
+	}
+	return roster
+}
+
+// GetSessionPipeline handles GET /api/v1/sessions/:id/pipeline, the single status of a
+// session's async pipeline from transcription to consolidated assessment. A finished
+// transcript whose speaker naming or assessment couldn't be queued is queued again here.
+func (h *SonioxHandler) GetSessionPipeline(c *gin.Context) {
+	sessionID := c.Param("id")
+	if sessionID != "test" {
+		if _, err := uuid.Parse(sessionID); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid session_id format"})
+			return
+		}
+	}
+
+	ctx := c.Request.Context()
+	job, err := h.results.GetTranscriptionJob(ctx, sessionID)
+	if errors.Is(err, assessmentService.ErrResultNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "no async transcription found for this session"})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load transcription job"})
+		return
+	}
+
+	var assessment *assessmentService.Job
+	if job.AssessmentJobID != "" {
+		assessment, err = h.jobs.Get(ctx, job.AssessmentJobID)
+		if err != nil && !errors.Is(err, assessmentService.ErrJobNotFound) {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load assessment job"})
+			return
+		}
+	} else if job.Status == assessmentService.TranscriptionCompleted {
+		logger := h.logger.ForSession(sessionID).With(slog.String("transcription_id", job.TranscriptionID))
+		if job.SpeakersNamedAt == nil {
+			if err := h.queueSpeakerNaming(ctx, job); err != nil {
+				logger.Error("failed to queue speaker naming of async transcript", slog.String("error", err.Error()))
+			}
+		} else if assessment, err = h.queuePipelineAssessment(ctx, logger, job); err != nil {
+			logger.Error("failed to queue assessment of async transcript", slog.String("error", err.Error()))
+		}
+	}
+
+	c.JSON(http.StatusOK, assessmentService.NewPipelineStatus(job, assessment))
+}
+
+// ListTranscriptionDeletions handles GET /api/v1/sessions/:id/async-transcription/deletions,
+// the log of a session's audio files and transcriptions deleted from the transcriber
+func (h *SonioxHandler) ListTranscriptionDeletions(c *gin.Context) {
+	sessionID := c.Param("id")
+	if sessionID != "test" {
+		if _, err := uuid.Parse(sessionID); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid session_id format"})
+			return
+		}
+	}
+
+	deletions, err := h.results.ListRemoteDeletions(c.Request.Context(), sessionID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load deletion log"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"session_id": sessionID,
+		"deletions":  deletions,
+	})
+}
+
+// failTranscription records that the transcriber could not transcribe a job
+func (h *SonioxHandler) failTranscription(ctx context.Context, logger *assessmentService.AssessmentLogger, job *assessmentService.TranscriptionJob, errorMessage string) error {
+	job.Status = assessmentService.TranscriptionFailed
+	job.ErrorMessage = errorMessage
+	if err := h.results.SaveTranscriptionJob(ctx, *job); err != nil {
+		logger.Error("failed to store transcription failure", slog.String("error", err.Error()))
+		return err
+	}
+	h.transcriptionChecks.Delete(job.SessionID)
+
+	logger.Warn("async transcription failed", slog.String("error_message", errorMessage))
+	h.events.Publish(ctx, assessmentService.NewAssessmentEvent(assessmentService.EventTranscriptionFailed, job.SessionID, gin.H{
+		"transcription_id": job.TranscriptionID,
+		"error_message":    errorMessage,
+	}))
+	return nil
 }
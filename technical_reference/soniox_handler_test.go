@@ -0,0 +1,764 @@
+package assessment
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	assessmentService "newing.vn/competency/backend/internal/service/assessment"
+)
+
+// fakeRoster is the session roster of FakeConversation, in speaking order
+var fakeRoster = []ParticipantMapping{
+	{ID: "6f1c2a7e-1d0b-4a51-9a3e-1f6b2c9d0e01", Name: "Nguyễn Văn Minh", Role: "GT Channel Manager"},
+	{ID: "6f1c2a7e-1d0b-4a51-9a3e-1f6b2c9d0e02", Name: "Trần Thị Lan", Role: "Ecommerce Manager"},
+	{ID: "6f1c2a7e-1d0b-4a51-9a3e-1f6b2c9d0e03", Name: "Lê Thị Hoa", Role: "Finance Analyst"},
+}
+
+// asyncPipelineTest drives a SonioxHandler backed by FakeTranscriber and the in-memory
+// stores through its HTTP routes, without network access
+type asyncPipelineTest struct {
+	t           *testing.T
+	handler     *SonioxHandler
+	transcriber *assessmentService.FakeTranscriber
+	router      *gin.Engine
+}
+
+func newAsyncPipelineTest(t *testing.T, webhookBaseURL string) *asyncPipelineTest {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	// No LLM keys: speaker identification fails over to the roster and assessments can't run
+	t.Setenv("ANTHROPIC_API_KEY", "")
+	t.Setenv("GEMINI_API_KEY", "")
+	t.Setenv("ASSESSMENT_RECORD_DIR", "")
+	t.Setenv("SONIOX_WEBHOOK_BASE_URL", webhookBaseURL)
+
+	transcriber := assessmentService.NewFakeTranscriber()
+	handler := NewSonioxHandlerWithConfig(SonioxHandlerConfig{Transcriber: transcriber})
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		handler.Shutdown(ctx)
+	})
+
+	router := gin.New()
+	router.GET("/api/v1/sessions/:id/soniox/temporary-key", handler.GetTemporaryKey)
+	router.POST("/api/v1/sessions/:id/async-transcription", handler.SubmitAsyncTranscription)
+	router.GET("/api/v1/sessions/:id/async-transcription/status", handler.GetAsyncTranscriptionStatus)
+	router.GET("/api/v1/sessions/:id/async-transcription/deletions", handler.ListTranscriptionDeletions)
+	router.GET("/api/v1/sessions/:id/pipeline", handler.GetSessionPipeline)
+	router.POST("/api/v1/soniox/webhook/:id", handler.SonioxWebhook)
+	router.POST("/api/v1/sessions/consolidated-transcript-sync", handler.SyncConsolidatedTranscript)
+	router.GET("/api/v1/sessions/:id/participants/:participantId/assessment", handler.GetAssessmentResults)
+	router.GET("/api/v1/sessions/:id/events", handler.StreamSessionEvents)
+	router.PUT("/api/v1/sessions/:id/participants/:participantId/assessment/official", handler.SetOfficialAssessmentRun)
+	router.GET("/api/v1/sessions/:id/participants/:participantId/assessment/runs/diff", handler.DiffAssessmentRuns)
+	return &asyncPipelineTest{t: t, handler: handler, transcriber: transcriber, router: router}
+}
+
+// do serves a request and decodes the JSON response into out, returning the status code
+func (p *asyncPipelineTest) do(req *http.Request, out any) int {
+	p.t.Helper()
+	recorder := httptest.NewRecorder()
+	p.router.ServeHTTP(recorder, req)
+	if out != nil {
+		if err := json.Unmarshal(recorder.Body.Bytes(), out); err != nil {
+			p.t.Fatalf("%s %s: decoding %q: %v", req.Method, req.URL.Path, recorder.Body.String(), err)
+		}
+	}
+	return recorder.Code
+}
+
+func (p *asyncPipelineTest) get(path string, out any) int {
+	p.t.Helper()
+	return p.do(httptest.NewRequest(http.MethodGet, path, nil), out)
+}
+
+// postRecording uploads a short WAV recording with the fake roster and the given form
+// fields, decoding the response into out
+func (p *asyncPipelineTest) postRecording(sessionID string, fields map[string]string, out any) int {
+	p.t.Helper()
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	roster, _ := json.Marshal(fakeRoster)
+	form.WriteField("participant_mapping", string(roster))
+	for name, value := range fields {
+		form.WriteField(name, value)
+	}
+	audio, _ := form.CreateFormFile("audio", "session.wav")
+	audio.Write(silentWAV(time.Second))
+	form.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/sessions/"+sessionID+"/async-transcription", &body)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	return p.do(req, out)
+}
+
+// submit uploads a short WAV recording with the fake roster and returns the transcription ID
+func (p *asyncPipelineTest) submit(sessionID string) string {
+	p.t.Helper()
+	var response struct {
+		TranscriptionID string `json:"transcription_id"`
+		CaseStudy       string `json:"case_study"`
+		Status          string `json:"status"`
+	}
+	if code := p.postRecording(sessionID, map[string]string{"vocabulary": `["Minh", "Lan", "Hoa"]`}, &response); code != http.StatusOK {
+		p.t.Fatalf("submit: status %d", code)
+	}
+	if response.TranscriptionID == "" || response.Status != "processing" {
+		p.t.Fatalf("submit: got %+v", response)
+	}
+	if response.CaseStudy != "glovia" {
+		p.t.Errorf("submit: case study %q, want the default glovia", response.CaseStudy)
+	}
+	return response.TranscriptionID
+}
+
+// waitForPipeline polls the pipeline status until the speakers are named and the
+// assessment is queued
+func (p *asyncPipelineTest) waitForPipeline(sessionID string) assessmentService.PipelineStatus {
+	p.t.Helper()
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		var status assessmentService.PipelineStatus
+		if code := p.get("/api/v1/sessions/"+sessionID+"/pipeline", &status); code != http.StatusOK {
+			p.t.Fatalf("pipeline: status %d", code)
+		}
+		switch status.Stage {
+		case assessmentService.PipelineAssessing, assessmentService.PipelineCompleted, assessmentService.PipelineFailed:
+			return status
+		}
+		if time.Now().After(deadline) {
+			p.t.Fatalf("pipeline stuck at %q", status.Stage)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+// checkTranscript checks the finished transcript, its speaker names and the deletion of
+// its remote data after retrieval
+func (p *asyncPipelineTest) checkTranscript(sessionID, transcriptionID string) {
+	p.t.Helper()
+	pipeline := p.waitForPipeline(sessionID)
+	if pipeline.TranscriptionID != transcriptionID || pipeline.AssessmentJobID == "" {
+		p.t.Errorf("pipeline: got %+v", pipeline)
+	}
+	if len(pipeline.Speakers) != len(fakeRoster) {
+		p.t.Fatalf("pipeline: %d speakers named, want %d", len(pipeline.Speakers), len(fakeRoster))
+	}
+	for i, speaker := range pipeline.Speakers {
+		if speaker.Name != fakeRoster[i].Name || speaker.ParticipantID != fakeRoster[i].ID {
+			p.t.Errorf("speaker %d named %q (%s), want %q (%s)", speaker.Speaker, speaker.Name, speaker.ParticipantID, fakeRoster[i].Name, fakeRoster[i].ID)
+		}
+	}
+
+	var status struct {
+		Status        string                                `json:"status"`
+		Segments      []assessmentService.TranscriptSegment `json:"segments"`
+		SpeakersNamed bool                                  `json:"speakers_named"`
+	}
+	if code := p.get("/api/v1/sessions/"+sessionID+"/async-transcription/status", &status); code != http.StatusOK {
+		p.t.Fatalf("status: status %d", code)
+	}
+	if status.Status != "completed" || !status.SpeakersNamed || len(status.Segments) == 0 {
+		p.t.Fatalf("status: got %s with %d segments, speakers named %v", status.Status, len(status.Segments), status.SpeakersNamed)
+	}
+	if first := status.Segments[0]; first.Speaker != 1 || first.Name != fakeRoster[0].Name {
+		p.t.Errorf("first segment by speaker %d %q, want speaker 1 %q", first.Speaker, first.Name, fakeRoster[0].Name)
+	}
+
+	var deletions struct {
+		Deletions []assessmentService.RemoteDeletion `json:"deletions"`
+	}
+	p.get("/api/v1/sessions/"+sessionID+"/async-transcription/deletions", &deletions)
+	if len(deletions.Deletions) != 2 {
+		p.t.Fatalf("deletions: got %+v, want the file and the transcription", deletions.Deletions)
+	}
+	for _, deletion := range deletions.Deletions {
+		if deletion.Reason != assessmentService.DeletionRetrieved {
+			p.t.Errorf("deletion of %s %s for %q, want %q", deletion.Resource, deletion.RemoteID, deletion.Reason, assessmentService.DeletionRetrieved)
+		}
+	}
+}
+
+func TestAsyncTranscriptionWebhookPipeline(t *testing.T) {
+	p := newAsyncPipelineTest(t, "https://assessment.example.com")
+	sessionID := uuid.NewString()
+	transcriptionID := p.submit(sessionID)
+
+	var pipeline assessmentService.PipelineStatus
+	p.get("/api/v1/sessions/"+sessionID+"/pipeline", &pipeline)
+	if pipeline.Stage != assessmentService.PipelineTranscribing {
+		t.Fatalf("pipeline stage %q before the webhook, want %q", pipeline.Stage, assessmentService.PipelineTranscribing)
+	}
+
+	job, err := p.handler.results.GetTranscriptionJob(context.Background(), sessionID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	webhook := func(secret string) int {
+		body, _ := json.Marshal(SonioxWebhookRequest{ID: transcriptionID, Status: assessmentService.TranscriptionCompleted})
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/soniox/webhook/"+sessionID, bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(assessmentService.SonioxWebhookAuthHeader, secret)
+		return p.do(req, nil)
+	}
+	if code := webhook("wrong-secret"); code != http.StatusUnauthorized {
+		t.Fatalf("webhook with a wrong secret: status %d, want %d", code, http.StatusUnauthorized)
+	}
+	if code := webhook(job.WebhookSecret); code != http.StatusOK {
+		t.Fatalf("webhook: status %d", code)
+	}
+
+	p.checkTranscript(sessionID, transcriptionID)
+}
+
+func TestSonioxWebhook(t *testing.T) {
+	tests := []struct {
+		name            string
+		wrongSecret     bool
+		unknownSession  bool
+		otherID         bool // callback for a transcription the session has replaced
+		status          string
+		wantCode        int
+		wantStatus      string
+		wantTranscribed bool
+	}{
+		{name: "completed", status: assessmentService.TranscriptionCompleted, wantCode: http.StatusOK, wantStatus: assessmentService.TranscriptionCompleted, wantTranscribed: true},
+		{name: "failed", status: assessmentService.TranscriptionFailed, wantCode: http.StatusOK, wantStatus: assessmentService.TranscriptionFailed},
+		{name: "wrong secret", wrongSecret: true, status: assessmentService.TranscriptionCompleted, wantCode: http.StatusUnauthorized, wantStatus: assessmentService.TranscriptionProcessing},
+		{name: "unknown session", unknownSession: true, status: assessmentService.TranscriptionCompleted, wantCode: http.StatusNotFound, wantStatus: assessmentService.TranscriptionProcessing},
+		{name: "replaced transcription", otherID: true, status: assessmentService.TranscriptionCompleted, wantCode: http.StatusOK, wantStatus: assessmentService.TranscriptionProcessing},
+		{name: "unknown status", status: "queued", wantCode: http.StatusBadRequest, wantStatus: assessmentService.TranscriptionProcessing},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newAsyncPipelineTest(t, "https://assessment.example.com")
+			ctx := context.Background()
+			sessionID := uuid.NewString()
+			transcriptionID := p.submit(sessionID)
+			job, err := p.handler.results.GetTranscriptionJob(ctx, sessionID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if job.WebhookSecret == "" {
+				t.Fatal("transcription submitted with a webhook has no secret")
+			}
+
+			callback := SonioxWebhookRequest{ID: transcriptionID, Status: tt.status}
+			secret, target := job.WebhookSecret, sessionID
+			if tt.wrongSecret {
+				secret = "wrong-secret"
+			}
+			if tt.unknownSession {
+				target = uuid.NewString()
+			}
+			if tt.otherID {
+				callback.ID = "replaced-transcription"
+			}
+			body, _ := json.Marshal(callback)
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/soniox/webhook/"+target, bytes.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set(assessmentService.SonioxWebhookAuthHeader, secret)
+			if code := p.do(req, nil); code != tt.wantCode {
+				t.Fatalf("webhook: status %d, want %d", code, tt.wantCode)
+			}
+
+			job, err = p.handler.results.GetTranscriptionJob(ctx, sessionID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if job.Status != tt.wantStatus || (len(job.Tokens) > 0) != tt.wantTranscribed {
+				t.Errorf("transcription %s with %d tokens stored, want %s (transcript stored %v)", job.Status, len(job.Tokens), tt.wantStatus, tt.wantTranscribed)
+			}
+		})
+	}
+}
+
+func TestAsyncTranscriptionStatusPollingPipeline(t *testing.T) {
+	p := newAsyncPipelineTest(t, "")
+	p.transcriber.PendingPolls = 1
+	sessionID := uuid.NewString()
+	transcriptionID := p.submit(sessionID)
+
+	// Each read checks the transcriber once the check interval has passed
+	for i := 0; ; i++ {
+		p.handler.transcriptionChecks.Delete(sessionID)
+		var status struct {
+			Status string `json:"status"`
+		}
+		code := p.get("/api/v1/sessions/"+sessionID+"/async-transcription/status", &status)
+		if code == http.StatusOK && status.Status == "completed" {
+			break
+		}
+		if code != http.StatusAccepted || i > p.transcriber.PendingPolls {
+			t.Fatalf("status check %d: %d %q", i+1, code, status.Status)
+		}
+	}
+
+	p.checkTranscript(sessionID, transcriptionID)
+}
+
+func TestAsyncTranscriptionFailedSubmissionDeletesAudio(t *testing.T) {
+	p := newAsyncPipelineTest(t, "")
+	sessionID := uuid.NewString()
+
+	// The audio is uploaded before the invalid field after it is read
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	audio, _ := form.CreateFormFile("audio", "session.wav")
+	audio.Write(silentWAV(time.Second))
+	form.WriteField("speaker_bindings", `{"1": "not-a-uuid"}`)
+	form.Close()
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/sessions/"+sessionID+"/async-transcription", &body)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	if code := p.do(req, nil); code != http.StatusBadRequest {
+		t.Fatalf("submit: status %d, want %d", code, http.StatusBadRequest)
+	}
+
+	var deletions struct {
+		Deletions []assessmentService.RemoteDeletion `json:"deletions"`
+	}
+	p.get("/api/v1/sessions/"+sessionID+"/async-transcription/deletions", &deletions)
+	if len(deletions.Deletions) != 1 || deletions.Deletions[0].Resource != assessmentService.RemoteFile ||
+		deletions.Deletions[0].Reason != assessmentService.DeletionAbandoned {
+		t.Fatalf("deletions: got %+v, want the abandoned file", deletions.Deletions)
+	}
+	pending, err := p.handler.results.ListPendingRemoteDeletions(context.Background())
+	if err != nil || len(pending) != 0 {
+		t.Fatalf("pending deletions: got %+v, %v", pending, err)
+	}
+}
+
+func TestAsyncTranscriptionSpeakerBindings(t *testing.T) {
+	tests := []struct {
+		name     string
+		bindings string
+		wantCode int
+	}{
+		{name: "speaker bound to a participant", bindings: `{"1": "` + fakeRoster[2].ID + `"}`, wantCode: http.StatusOK},
+		{name: "participant bound to two speakers", bindings: `{"1": "` + fakeRoster[0].ID + `", "4": "` + fakeRoster[0].ID + `"}`, wantCode: http.StatusOK},
+		{name: "participant not in the session", bindings: `{"1": "` + uuid.NewString() + `"}`, wantCode: http.StatusUnprocessableEntity},
+		{name: "negative speaker number", bindings: `{"-1": "` + fakeRoster[0].ID + `"}`, wantCode: http.StatusUnprocessableEntity},
+		{name: "participant ID not a UUID", bindings: `{"1": "Minh"}`, wantCode: http.StatusBadRequest},
+		{name: "not a speaker map", bindings: `["Minh"]`, wantCode: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newAsyncPipelineTest(t, "")
+			sessionID := uuid.NewString()
+			if code := p.postRecording(sessionID, map[string]string{"speaker_bindings": tt.bindings}, nil); code != tt.wantCode {
+				t.Fatalf("submit: status %d, want %d", code, tt.wantCode)
+			}
+			if tt.wantCode != http.StatusOK {
+				return
+			}
+
+			var bindings map[int]string
+			json.Unmarshal([]byte(tt.bindings), &bindings)
+			var status struct {
+				Segments []assessmentService.TranscriptSegment `json:"segments"`
+			}
+			// Without a webhook, reading the status once the check interval has passed
+			// picks up the finished transcript
+			p.handler.transcriptionChecks.Delete(sessionID)
+			p.get("/api/v1/sessions/"+sessionID+"/async-transcription/status", &status)
+			pipeline := p.waitForPipeline(sessionID)
+			p.get("/api/v1/sessions/"+sessionID+"/async-transcription/status", &status)
+			if len(pipeline.Speakers) == 0 || len(status.Segments) == 0 {
+				t.Fatalf("%d speakers named and %d segments", len(pipeline.Speakers), len(status.Segments))
+			}
+			for _, speaker := range pipeline.Speakers {
+				participantID, bound := bindings[speaker.Speaker]
+				if bound && (speaker.ParticipantID != participantID || speaker.Source != assessmentService.SpeakerNameBound) {
+					t.Errorf("speaker %d named %q (%s, %s), want bound to %s", speaker.Speaker, speaker.Name, speaker.ParticipantID, speaker.Source, participantID)
+				}
+			}
+			for _, segment := range status.Segments {
+				if participantID, bound := bindings[segment.Speaker]; bound && segment.ParticipantID != participantID {
+					t.Errorf("speaker %d segment carries participant %q, want %s", segment.Speaker, segment.ParticipantID, participantID)
+				}
+			}
+		})
+	}
+}
+
+func TestTranscriptionVocabulary(t *testing.T) {
+	tests := []struct {
+		name              string
+		query             string
+		fields            map[string]string
+		wantCode          int
+		wantCaseStudy     string
+		wantLanguageHints []string
+		wantContext       string
+	}{
+		{
+			name:              "default case study",
+			wantCode:          http.StatusOK,
+			wantCaseStudy:     "glovia",
+			wantLanguageHints: []string{"en", "vi"},
+			wantContext:       "GLOVIA, Heineken, MT, GT, ecommerce, assessment center",
+		},
+		{
+			name:              "requested case study and session terms",
+			query:             "case_study=vietinbank&vocabulary=Thông+tư+41",
+			fields:            map[string]string{"case_study": "vietinbank", "vocabulary": `["Thông tư 41"]`},
+			wantCode:          http.StatusOK,
+			wantCaseStudy:     "vietinbank",
+			wantLanguageHints: []string{"vi", "en"},
+			wantContext:       "VietinBank, CASA, NPL, Basel III, assessment center, Thông tư 41",
+		},
+		{
+			name:              "requested language hints",
+			query:             "language_hints=vi",
+			fields:            map[string]string{"language_hints": `["vi"]`},
+			wantCode:          http.StatusOK,
+			wantCaseStudy:     "glovia",
+			wantLanguageHints: []string{"vi"},
+			wantContext:       "GLOVIA, Heineken, MT, GT, ecommerce, assessment center",
+		},
+		{
+			name:     "unknown case study",
+			query:    "case_study=acb",
+			fields:   map[string]string{"case_study": "acb"},
+			wantCode: http.StatusUnprocessableEntity,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newAsyncPipelineTest(t, "")
+			sessionID := uuid.NewString()
+
+			// The realtime flow is handed the vocabulary with its temporary key
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/sessions/"+sessionID+"/soniox/temporary-key?"+tt.query, nil)
+			req.Header.Set("X-Candidate-Token", "candidate")
+			var key struct {
+				CaseStudy     string   `json:"caseStudy"`
+				LanguageHints []string `json:"languageHints"`
+				Context       string   `json:"context"`
+			}
+			if code := p.do(req, &key); code != tt.wantCode {
+				t.Fatalf("temporary key: status %d, want %d", code, tt.wantCode)
+			}
+
+			// The async job is created with it
+			if code := p.postRecording(sessionID, tt.fields, nil); code != tt.wantCode {
+				t.Fatalf("submit: status %d, want %d", code, tt.wantCode)
+			}
+			if tt.wantCode != http.StatusOK {
+				return
+			}
+			job, err := p.handler.results.GetTranscriptionJob(context.Background(), sessionID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			request, ok := p.transcriber.Request(job.TranscriptionID)
+			if !ok {
+				t.Fatalf("no transcription %s", job.TranscriptionID)
+			}
+
+			// The async job also biases recognition towards the roster's names
+			wantAsyncContext := tt.wantContext
+			for _, participant := range fakeRoster {
+				wantAsyncContext += ", " + participant.Name
+			}
+			got := []string{key.CaseStudy, strings.Join(key.LanguageHints, ","), key.Context, strings.Join(request.LanguageHints, ","), request.Context}
+			want := []string{tt.wantCaseStudy, strings.Join(tt.wantLanguageHints, ","), tt.wantContext, strings.Join(tt.wantLanguageHints, ","), wantAsyncContext}
+			for i := range got {
+				if got[i] != want[i] {
+					t.Errorf("got %q, want %q", got, want)
+					break
+				}
+			}
+		})
+	}
+}
+
+// consolidatedSyncResponse is the answer to a consolidated transcript sync
+type consolidatedSyncResponse struct {
+	Status string `json:"status"`
+	JobID  string `json:"job_id"`
+}
+
+// syncConsolidated sends a unified consolidated transcript sync
+func (p *asyncPipelineTest) syncConsolidated(sessionID, transcript string, final bool) (int, consolidatedSyncResponse) {
+	p.t.Helper()
+	body, _ := json.Marshal(ConsolidatedTranscriptSyncRequest{
+		SessionID:          sessionID,
+		Conversation:       []ConsolidatedTranscriptParticipant{{ParticipantID: "unified", Transcript: transcript}},
+		ParticipantMapping: fakeRoster,
+		Final:              final,
+	})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/sessions/consolidated-transcript-sync", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	var response consolidatedSyncResponse
+	code := p.do(req, &response)
+	return code, response
+}
+
+// waitForJob waits until a job reaches one of the given states
+func (p *asyncPipelineTest) waitForJob(jobID string, statuses ...string) *assessmentService.Job {
+	p.t.Helper()
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		job, err := p.handler.jobs.Get(context.Background(), jobID)
+		if err != nil {
+			p.t.Fatal(err)
+		}
+		for _, status := range statuses {
+			if job.Status == status {
+				return job
+			}
+		}
+		if time.Now().After(deadline) {
+			p.t.Fatalf("job %s stuck at %q, want one of %v", jobID, job.Status, statuses)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+func TestConsolidatedSyncAssessedOnceQuiet(t *testing.T) {
+	t.Setenv("ASSESSMENT_REASSESS_IDLE", "50ms")
+	p := newAsyncPipelineTest(t, "")
+	sessionID := uuid.NewString()
+
+	code, response := p.syncConsolidated(sessionID, "Minh: Let's start with the MT channel.", false)
+	if code != http.StatusAccepted || response.Status != "deferred" || response.JobID != "" {
+		t.Fatalf("short sync: %d %+v, want a deferred sync without a job", code, response)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		job, err := p.handler.jobs.Latest(context.Background(), sessionID, assessmentService.JobTypeConsolidatedAssessment, "")
+		if err == nil {
+			if job.Status == assessmentService.JobStatusCancelled {
+				t.Fatalf("idle assessment job %s cancelled", job.ID)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("short conversation never assessed after going quiet")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+func TestConsolidatedFinalSyncRetriesFailedAssessment(t *testing.T) {
+	// No LLM keys: every unified assessment fails, and is not retried by the queue
+	t.Setenv("ASSESSMENT_JOB_MAX_ATTEMPTS", "1")
+	p := newAsyncPipelineTest(t, "")
+	sessionID := uuid.NewString()
+	transcript := "Minh: Let's start with the MT channel. Lan: Ecommerce is growing faster."
+
+	code, first := p.syncConsolidated(sessionID, transcript, true)
+	if code != http.StatusOK || first.Status != "received" {
+		t.Fatalf("final sync: %d %+v", code, first)
+	}
+	p.waitForJob(first.JobID, assessmentService.JobStatusFailed)
+
+	// The transcript did not grow, but its assessment failed, so a final sync runs it again
+	code, second := p.syncConsolidated(sessionID, transcript, true)
+	if code != http.StatusOK || second.Status != "received" || second.JobID == first.JobID {
+		t.Fatalf("final sync after a failed assessment: %d %+v, want a new job", code, second)
+	}
+	p.waitForJob(second.JobID, assessmentService.JobStatusFailed)
+}
+
+func TestAssessmentResultsReportNewerJob(t *testing.T) {
+	tests := []struct {
+		name          string
+		enqueue       bool // queue a participant assessment, which fails without LLM keys
+		resultFromJob bool // the stored result was produced by that job
+		wantStale     bool
+		wantJobStatus string
+	}{
+		{name: "no assessment job"},
+		{name: "result from the latest job", enqueue: true, resultFromJob: true, wantJobStatus: assessmentService.JobStatusFailed},
+		{name: "newer assessment failed", enqueue: true, wantStale: true, wantJobStatus: assessmentService.JobStatusFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("ASSESSMENT_JOB_MAX_ATTEMPTS", "1")
+			p := newAsyncPipelineTest(t, "")
+			ctx := context.Background()
+			sessionID, participantID := uuid.NewString(), fakeRoster[0].ID
+
+			result := &assessmentService.AssessmentResponse{
+				SessionID:     sessionID,
+				ParticipantID: participantID,
+				OverallScore:  3,
+				Provenance:    &assessmentService.AssessmentProvenance{JobID: "earlier-job"},
+			}
+			if tt.enqueue {
+				job, err := p.handler.jobs.Enqueue(ctx, assessmentService.JobTypeParticipantAssessment, sessionID, participantID, map[string]string{})
+				if err != nil {
+					t.Fatal(err)
+				}
+				p.waitForJob(job.ID, tt.wantJobStatus)
+				if tt.resultFromJob {
+					result.Provenance.JobID = job.ID
+				}
+			}
+			if err := p.handler.results.SaveParticipantResult(ctx, result); err != nil {
+				t.Fatal(err)
+			}
+
+			var response struct {
+				OverallScore float64                `json:"overall_score"`
+				LatestJob    *assessmentService.Job `json:"latest_job"`
+				Stale        bool                   `json:"stale"`
+			}
+			if code := p.get("/api/v1/sessions/"+sessionID+"/participants/"+participantID+"/assessment", &response); code != http.StatusOK {
+				t.Fatalf("results: status %d", code)
+			}
+			if response.OverallScore != 3 || response.Stale != tt.wantStale {
+				t.Errorf("got score %v, stale %v; want the stored result, stale %v", response.OverallScore, response.Stale, tt.wantStale)
+			}
+			switch {
+			case response.LatestJob == nil && tt.wantJobStatus != "":
+				t.Errorf("no latest job, want a %s one", tt.wantJobStatus)
+			case response.LatestJob != nil && response.LatestJob.Status != tt.wantJobStatus:
+				t.Errorf("latest job %s, want %q", response.LatestJob.Status, tt.wantJobStatus)
+			}
+		})
+	}
+}
+
+func TestAssessmentRunEndpointsValidateSession(t *testing.T) {
+	p := newAsyncPipelineTest(t, "")
+	sessionID, participantID := uuid.NewString(), fakeRoster[0].ID
+	for _, score := range []float64{2, 4} {
+		result := &assessmentService.AssessmentResponse{SessionID: sessionID, ParticipantID: participantID, OverallScore: score}
+		if err := p.handler.results.SaveParticipantResult(context.Background(), result); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		wantCode int
+	}{
+		{name: "diff", method: http.MethodGet, path: "/api/v1/sessions/" + sessionID + "/participants/" + participantID + "/assessment/runs/diff", wantCode: http.StatusOK},
+		{name: "diff with malformed session", method: http.MethodGet, path: "/api/v1/sessions/not-a-uuid/participants/" + participantID + "/assessment/runs/diff", wantCode: http.StatusBadRequest},
+		{name: "diff of demo session", method: http.MethodGet, path: "/api/v1/sessions/test/participants/" + participantID + "/assessment/runs/diff", wantCode: http.StatusNotFound},
+		{name: "official run", method: http.MethodPut, path: "/api/v1/sessions/" + sessionID + "/participants/" + participantID + "/assessment/official", wantCode: http.StatusOK},
+		{name: "official run with malformed session", method: http.MethodPut, path: "/api/v1/sessions/not-a-uuid/participants/" + participantID + "/assessment/official", wantCode: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, bytes.NewReader([]byte(`{}`)))
+			req.Header.Set("Content-Type", "application/json")
+			if code := p.do(req, nil); code != tt.wantCode {
+				t.Errorf("%s %s: status %d, want %d", tt.method, tt.path, code, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestSessionEventsReplayAfterLastEventID(t *testing.T) {
+	p := newAsyncPipelineTest(t, "")
+	server := httptest.NewServer(p.router)
+	defer server.Close()
+
+	tests := []struct {
+		name      string
+		lastEvent int  // index of the client's last event, or -1 on a first connection
+		query     bool // sent as ?last_event_id= instead of the header
+		wantFrom  int  // index of the first replayed event
+	}{
+		{name: "first connection", lastEvent: -1},
+		{name: "reconnect with Last-Event-ID", lastEvent: 0, wantFrom: 1},
+		{name: "reconnect with query parameter", lastEvent: 1, query: true, wantFrom: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sessionID := uuid.NewString()
+			published := []string{}
+			for _, eventType := range []string{assessmentService.EventTranscriptionSubmitted, assessmentService.EventTranscriptionCompleted, assessmentService.EventParticipantAssessed} {
+				event := assessmentService.NewAssessmentEvent(eventType, sessionID, nil)
+				p.handler.stream.Publish(context.Background(), event)
+				published = append(published, event.ID)
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			url := server.URL + "/api/v1/sessions/" + sessionID + "/events"
+			if tt.lastEvent >= 0 && tt.query {
+				url += "?last_event_id=" + published[tt.lastEvent]
+			}
+			req, _ := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+			if tt.lastEvent >= 0 && !tt.query {
+				req.Header.Set("Last-Event-ID", published[tt.lastEvent])
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "text/event-stream" {
+				t.Fatalf("status %d, content type %q", resp.StatusCode, resp.Header.Get("Content-Type"))
+			}
+
+			// The replay is followed by a live event, which ends the read
+			live := assessmentService.NewAssessmentEvent(assessmentService.EventGroupAssessed, sessionID, nil)
+			go func() {
+				time.Sleep(20 * time.Millisecond)
+				p.handler.stream.Publish(context.Background(), live)
+			}()
+			got := []string{}
+			lines := bufio.NewScanner(resp.Body)
+			for lines.Scan() {
+				id, ok := strings.CutPrefix(lines.Text(), "id: ")
+				if !ok {
+					continue
+				}
+				if id == live.ID {
+					break
+				}
+				got = append(got, id)
+			}
+			if want := published[tt.wantFrom:]; strings.Join(got, ",") != strings.Join(want, ",") {
+				t.Errorf("replayed %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+// silentWAV is a 16 kHz mono 16-bit PCM recording of silence
+func silentWAV(duration time.Duration) []byte {
+	const sampleRate, bytesPerSample = 16000, 2
+	dataSize := uint32(duration.Seconds() * sampleRate * bytesPerSample)
+
+	var b bytes.Buffer
+	b.WriteString("RIFF")
+	binary.Write(&b, binary.LittleEndian, 36+dataSize)
+	b.WriteString("WAVEfmt ")
+	for _, field := range []any{
+		uint32(16), uint16(1), uint16(1), uint32(sampleRate), uint32(sampleRate * bytesPerSample), uint16(bytesPerSample), uint16(16),
+	} {
+		binary.Write(&b, binary.LittleEndian, field)
+	}
+	b.WriteString("data")
+	binary.Write(&b, binary.LittleEndian, dataSize)
+	b.Write(make([]byte, dataSize))
+	return b.Bytes()
+}
@@ -0,0 +1,227 @@
+package assessment
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// JobTypeSpeakerNaming names the speakers of a finished async transcript and then queues
+// its assessment
+const JobTypeSpeakerNaming = "speaker_naming"
+
+// Where a speaker's name came from, in order of precedence
+const (
+	SpeakerNameBound      = "bound"
+	SpeakerNameManual     = "manual"
+	SpeakerNameIdentified = "identified"
+	SpeakerNameClient     = "client"
+	SpeakerNameRoster     = "roster"
+	SpeakerNameDefault    = "default"
+)
+
+// maxIdentificationChars caps the conversation sent for speaker identification;
+// introductions and being addressed by name happen early
+const maxIdentificationChars = 60000
+
+// SpeakerName is the name resolved for one diarized speaker of an async transcript
+type SpeakerName struct {
+	Speaker int    `json:"speaker"`
+	Name    string `json:"name"`
+	// ParticipantID is set when the name is a roster participant's
+	ParticipantID string `json:"participant_id,omitempty"`
+	Source        string `json:"source"`
+	// Confidence is "high", "medium", "low" or "none", as in speaker identification
+	Confidence string `json:"confidence"`
+	Evidence   string `json:"evidence,omitempty"`
+}
+
+// DiarizedConversation lists speakers in order of first speaking and renders the
+// conversation as "[Speaker N]: text" turns, cut to what identification reads
+func DiarizedConversation(segments []TranscriptSegment) ([]int, string) {
+	speakers := []int{}
+	bindings := make(map[int]SessionParticipant)
+	for _, segment := range segments {
+		if _, ok := bindings[segment.Speaker]; !ok {
+			bindings[segment.Speaker] = SessionParticipant{Name: fmt.Sprintf("Speaker %d", segment.Speaker)}
+			speakers = append(speakers, segment.Speaker)
+		}
+	}
+
+	transcript := FormatConversation(ConversationTurns(segments, bindings))
+	if len(transcript) > maxIdentificationChars {
+		transcript = strings.ToValidUTF8(transcript[:maxIdentificationChars], "")
+	}
+	return speakers, transcript
+}
+
+// ResolveSpeakerNames gives every speaker of the job a name no other speaker has. Bound
+// speakers are their participant, named as manually corrected or else after the
+// participant; other manual corrections are kept as given. Identified names are taken
+// most confident first, so when two speakers are identified as the same person the less
+// confident one falls through. Remaining speakers get their name from the client's
+// mapping, then from roster participants nobody was matched to, in speaking order, then
+// "Speaker N". Names that match a roster participant take the participant's full name.
+func (job *TranscriptionJob) ResolveSpeakerNames(speakers []int, identified []SpeakerIdentificationResponse, roster []SessionParticipant) []SpeakerName {
+	clientMapping, manualCorrections := job.SpeakerMapping, job.ManualCorrections
+	names := make(map[int]SpeakerName)
+	nameOwners := make(map[string]int)
+	takenParticipants := make(map[string]bool)
+	notes := make(map[int][]string)
+
+	isSpeaker := make(map[int]bool, len(speakers))
+	for _, speaker := range speakers {
+		isSpeaker[speaker] = true
+	}
+
+	// resolve matches a candidate to the roster by the participant it was identified as,
+	// else by name; canonical replaces its name with the participant's full name
+	resolve := func(name SpeakerName, canonical bool) SpeakerName {
+		participant, ok := rosterParticipant(name.ParticipantID, roster, takenParticipants)
+		if !ok {
+			participant, ok = rosterMatch(name.Name, roster, takenParticipants)
+		}
+		name.ParticipantID = ""
+		if ok {
+			name.ParticipantID = participant.ID
+			if canonical {
+				name.Name = participant.Name
+			}
+		}
+		return name
+	}
+	claim := func(name SpeakerName) {
+		if name.ParticipantID != "" {
+			takenParticipants[name.ParticipantID] = true
+		}
+		nameOwners[normalizeName(name.Name)] = name.Speaker
+		names[name.Speaker] = name
+	}
+	claimUnique := func(name SpeakerName) bool {
+		if owner, taken := nameOwners[normalizeName(name.Name)]; taken {
+			notes[name.Speaker] = append(notes[name.Speaker], fmt.Sprintf("%s name %s is already speaker %d's", name.Source, name.Name, owner))
+			return false
+		}
+		claim(name)
+		return true
+	}
+
+	for _, speaker := range speakers {
+		participantID, bound := job.SpeakerBindings[speaker]
+		if !bound {
+			continue
+		}
+		name := SpeakerName{Speaker: speaker, Name: fmt.Sprintf("Speaker %d", speaker), ParticipantID: participantID, Source: SpeakerNameBound, Confidence: "high", Evidence: "bound to participant"}
+		if participant, ok := rosterParticipant(participantID, roster, nil); ok {
+			name.Name = participant.Name
+		}
+		if corrected := strings.TrimSpace(manualCorrections[speaker]); corrected != "" {
+			name.Name = corrected
+		}
+		claim(name)
+	}
+	for _, speaker := range speakers {
+		if _, named := names[speaker]; named {
+			continue
+		}
+		if name := strings.TrimSpace(manualCorrections[speaker]); name != "" {
+			claim(resolve(SpeakerName{Speaker: speaker, Name: name, Source: SpeakerNameManual, Confidence: "high", Evidence: "manual correction"}, false))
+		}
+	}
+
+	ranked := append([]SpeakerIdentificationResponse(nil), identified...)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		if ri, rj := confidenceRank(ranked[i].Confidence), confidenceRank(ranked[j].Confidence); ri != rj {
+			return ri > rj
+		}
+		return ranked[i].SpeakerID < ranked[j].SpeakerID
+	})
+	for _, identification := range ranked {
+		_, named := names[identification.SpeakerID]
+		name := strings.TrimSpace(identification.Name)
+		if named || !isSpeaker[identification.SpeakerID] || name == "" || confidenceRank(identification.Confidence) == 0 {
+			continue
+		}
+		claimUnique(resolve(SpeakerName{
+			Speaker:       identification.SpeakerID,
+			Name:          name,
+			ParticipantID: identification.ParticipantID,
+			Source:        SpeakerNameIdentified,
+			Confidence:    identification.Confidence,
+			Evidence:      identification.Evidence,
+		}, true))
+	}
+
+	for _, speaker := range speakers {
+		name := strings.TrimSpace(clientMapping[speaker])
+		if _, named := names[speaker]; named || name == "" {
+			continue
+		}
+		claimUnique(resolve(SpeakerName{Speaker: speaker, Name: name, Source: SpeakerNameClient, Confidence: "medium", Evidence: "named during the live session"}, true))
+	}
+
+	unclaimed := []SessionParticipant{}
+	for _, participant := range roster {
+		if !takenParticipants[participant.ID] {
+			unclaimed = append(unclaimed, participant)
+		}
+	}
+	for _, speaker := range speakers {
+		if _, named := names[speaker]; named {
+			continue
+		}
+		for len(unclaimed) > 0 {
+			participant := unclaimed[0]
+			unclaimed = unclaimed[1:]
+			if claimUnique(SpeakerName{Speaker: speaker, Name: participant.Name, ParticipantID: participant.ID, Source: SpeakerNameRoster, Confidence: "low", Evidence: "next unassigned roster participant in speaking order"}) {
+				break
+			}
+		}
+		if _, named := names[speaker]; !named {
+			claim(SpeakerName{Speaker: speaker, Name: fmt.Sprintf("Speaker %d", speaker), Source: SpeakerNameDefault, Confidence: "none"})
+		}
+	}
+
+	resolved := make([]SpeakerName, 0, len(speakers))
+	for _, speaker := range speakers {
+		name := names[speaker]
+		if name.Evidence != "" {
+			notes[speaker] = append(notes[speaker], name.Evidence)
+		}
+		name.Evidence = strings.Join(notes[speaker], "; ")
+		resolved = append(resolved, name)
+	}
+	return resolved
+}
+
+// rosterParticipant finds a participant not yet taken by ID
+func rosterParticipant(id string, roster []SessionParticipant, taken map[string]bool) (SessionParticipant, bool) {
+	for _, participant := range roster {
+		if id != "" && participant.ID == id && !taken[id] {
+			return participant, true
+		}
+	}
+	return SessionParticipant{}, false
+}
+
+// confidenceRank orders identification confidence; anything unrecognised counts as none
+func confidenceRank(confidence string) int {
+	switch strings.ToLower(confidence) {
+	case "high":
+		return 3
+	case "medium":
+		return 2
+	case "low":
+		return 1
+	}
+	return 0
+}
+
+// speakerNameMapping is the speaker-to-name mapping of resolved names
+func speakerNameMapping(names []SpeakerName) map[int]string {
+	mapping := make(map[int]string, len(names))
+	for _, name := range names {
+		mapping[name.Speaker] = name.Name
+	}
+	return mapping
+}
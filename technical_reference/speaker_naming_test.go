@@ -0,0 +1,82 @@
+package assessment
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestResolveSpeakerNames(t *testing.T) {
+	tests := []struct {
+		name       string
+		speakers   []int
+		bindings   map[int]string
+		identified []SpeakerIdentificationResponse
+		want       []string // "name|participant|source" per speaker
+	}{
+		{
+			name:     "identified by participant ID",
+			speakers: []int{1, 2, 3},
+			identified: []SpeakerIdentificationResponse{
+				{SpeakerID: 1, Name: "Minh", ParticipantID: "p3", Confidence: "high"},
+				{SpeakerID: 2, Name: "Lan", ParticipantID: "p2", Confidence: "medium"},
+				{SpeakerID: 3, Name: "Minh", ParticipantID: "p1", Confidence: "low"},
+			},
+			want: []string{"Lê Văn Minh|p3|identified", "Trần Thị Lan|p2|identified", "Nguyễn Văn Minh|p1|identified"},
+		},
+		{
+			name:       "identified by name",
+			speakers:   []int{1, 2, 3},
+			identified: []SpeakerIdentificationResponse{{SpeakerID: 2, Name: "Lan", Confidence: "medium"}},
+			want:       []string{"Nguyễn Văn Minh|p1|roster", "Trần Thị Lan|p2|identified", "Lê Văn Minh|p3|roster"},
+		},
+		{
+			name:     "same participant identified twice",
+			speakers: []int{1, 2, 3},
+			identified: []SpeakerIdentificationResponse{
+				{SpeakerID: 2, Name: "Trần Thị Lan", ParticipantID: "p2", Confidence: "low"},
+				{SpeakerID: 1, Name: "Trần Thị Lan", ParticipantID: "p2", Confidence: "high"},
+			},
+			want: []string{"Trần Thị Lan|p2|identified", "Nguyễn Văn Minh|p1|roster", "Lê Văn Minh|p3|roster"},
+		},
+		{
+			name:     "unconfident and unrequested identifications ignored",
+			speakers: []int{1, 2, 3},
+			identified: []SpeakerIdentificationResponse{
+				{SpeakerID: 1, Name: "Lan", ParticipantID: "p2", Confidence: "none"},
+				{SpeakerID: 2, Name: "Lan", ParticipantID: "p2"},
+				{SpeakerID: 7, Name: "Lan", ParticipantID: "p2", Confidence: "high"},
+			},
+			want: []string{"Nguyễn Văn Minh|p1|roster", "Trần Thị Lan|p2|roster", "Lê Văn Minh|p3|roster"},
+		},
+		{
+			name:     "bindings win over identification",
+			speakers: []int{1, 2, 3},
+			bindings: map[int]string{1: "p3"},
+			identified: []SpeakerIdentificationResponse{
+				{SpeakerID: 1, Name: "Nguyễn Văn Minh", ParticipantID: "p1", Confidence: "high"},
+				{SpeakerID: 2, Name: "Lê Văn Minh", ParticipantID: "p3", Confidence: "high"},
+			},
+			want: []string{"Lê Văn Minh|p3|bound", "Nguyễn Văn Minh|p1|roster", "Trần Thị Lan|p2|roster"},
+		},
+		{
+			name:     "more speakers than participants",
+			speakers: []int{1, 2, 3, 4},
+			want:     []string{"Nguyễn Văn Minh|p1|roster", "Trần Thị Lan|p2|roster", "Lê Văn Minh|p3|roster", "Speaker 4||default"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			job := &TranscriptionJob{SpeakerBindings: tt.bindings}
+			names := job.ResolveSpeakerNames(tt.speakers, tt.identified, testRoster)
+			got := []string{}
+			for i, name := range names {
+				if name.Speaker != tt.speakers[i] {
+					t.Errorf("name %d is speaker %d's, want speaker %d's", i, name.Speaker, tt.speakers[i])
+				}
+				got = append(got, fmt.Sprintf("%s|%s|%s", name.Name, name.ParticipantID, name.Source))
+			}
+			assertSameJSON(t, got, tt.want)
+		})
+	}
+}
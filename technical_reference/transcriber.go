@@ -0,0 +1,81 @@
+package assessment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// ErrTranscriberNotConfigured is returned when a transcription backend is missing its
+// credentials
+var ErrTranscriberNotConfigured = errors.New("transcription backend not configured")
+
+// Transcriber is a speech-to-text backend: it issues keys for realtime transcription in
+// the browser and runs async transcriptions of uploaded recordings. Statuses and tokens
+// use the shared TranscriptionProcessing/Completed/Failed and TranscriptToken model so
+// callers don't depend on one vendor.
+type Transcriber interface {
+	// Name identifies the backend in logs
+	Name() string
+	// TemporaryKey issues a short-lived key for realtime transcription from the browser
+	TemporaryKey(ctx context.Context, expiresIn time.Duration) (string, error)
+	// UploadAudio streams an audio file to the backend and returns its file ID. An error
+	// reading audio is returned as is.
+	UploadAudio(ctx context.Context, filename string, audio io.Reader) (string, error)
+	// CreateTranscription starts an async transcription of an uploaded file and returns
+	// its ID
+	CreateTranscription(ctx context.Context, req TranscriptionRequest) (string, error)
+	// TranscriptionStatus reports an async transcription's progress
+	TranscriptionStatus(ctx context.Context, transcriptionID string) (*TranscriptionStatus, error)
+	// Transcript returns a completed transcription's tokens
+	Transcript(ctx context.Context, transcriptionID string) ([]TranscriptToken, error)
+	// DeleteTranscription and DeleteFile remove a transcription and an uploaded file from
+	// the backend. Deleting one that is already gone is not an error.
+	DeleteTranscription(ctx context.Context, transcriptionID string) error
+	DeleteFile(ctx context.Context, fileID string) error
+}
+
+// TranscriptionRequest describes an async transcription to start
+type TranscriptionRequest struct {
+	FileID        string
+	LanguageHints []string
+	// Context is free text (names, jargon) the backend uses to bias recognition
+	Context     string
+	Diarization bool
+	// WebhookURL, if set, is called on completion with WebhookAuthHeader set to
+	// WebhookAuthValue
+	WebhookURL        string
+	WebhookAuthHeader string
+	WebhookAuthValue  string
+}
+
+// TranscriptionStatus is an async transcription's progress
+type TranscriptionStatus struct {
+	// Status is TranscriptionProcessing, TranscriptionCompleted or TranscriptionFailed
+	Status       string `json:"status"`
+	ErrorMessage string `json:"error_message,omitempty"`
+}
+
+// TranscriberAPIError is a backend answering a request with an error status
+type TranscriberAPIError struct {
+	Provider   string
+	Operation  string
+	StatusCode int
+	Body       string
+}
+
+func (e *TranscriberAPIError) Error() string {
+	return fmt.Sprintf("%s %s returned %d: %s", e.Provider, e.Operation, e.StatusCode, e.Body)
+}
+
+// TranscriberFromEnv selects the backend named by TRANSCRIBER: "fake" for the offline
+// FakeTranscriber, otherwise Soniox
+func TranscriberFromEnv() Transcriber {
+	if os.Getenv("TRANSCRIBER") == "fake" {
+		return NewFakeTranscriber()
+	}
+	return SonioxTranscriberFromEnv()
+}
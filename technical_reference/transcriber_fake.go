@@ -0,0 +1,176 @@
+package assessment
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FakeTranscriber is an offline Transcriber for local development and end-to-end runs
+// without network access. Every transcription returns the same diarized conversation,
+// after PendingPolls status checks report it still processing. Its temporary keys are
+// not accepted by Soniox's realtime API.
+type FakeTranscriber struct {
+	// Tokens is the transcript every transcription returns
+	Tokens []TranscriptToken
+	// PendingPolls is how many status checks report processing before completion
+	PendingPolls int
+
+	mu             sync.Mutex
+	files          map[string]int64
+	transcriptions map[string]*fakeTranscription
+	sequence       int
+}
+
+type fakeTranscription struct {
+	request TranscriptionRequest
+	polls   int
+}
+
+// NewFakeTranscriber creates a fake returning FakeConversation that completes on the
+// first status check
+func NewFakeTranscriber() *FakeTranscriber {
+	return &FakeTranscriber{
+		Tokens:         FakeConversation(),
+		files:          make(map[string]int64),
+		transcriptions: make(map[string]*fakeTranscription),
+	}
+}
+
+func (f *FakeTranscriber) Name() string {
+	return "fake"
+}
+
+func (f *FakeTranscriber) TemporaryKey(ctx context.Context, expiresIn time.Duration) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return fmt.Sprintf("fake-temporary-key-%d", f.next()), nil
+}
+
+// UploadAudio reads the whole upload, so size limits and read errors behave as with a
+// real backend, and keeps only its length
+func (f *FakeTranscriber) UploadAudio(ctx context.Context, filename string, audio io.Reader) (string, error) {
+	size, err := io.Copy(io.Discard, audio)
+	if err != nil {
+		return "", err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	fileID := fmt.Sprintf("fake-file-%d", f.next())
+	f.files[fileID] = size
+	return fileID, nil
+}
+
+func (f *FakeTranscriber) CreateTranscription(ctx context.Context, req TranscriptionRequest) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.files[req.FileID]; !ok {
+		return "", &TranscriberAPIError{Provider: f.Name(), Operation: "transcription create", StatusCode: 404, Body: "unknown file " + req.FileID}
+	}
+	transcriptionID := fmt.Sprintf("fake-transcription-%d", f.next())
+	f.transcriptions[transcriptionID] = &fakeTranscription{request: req}
+	return transcriptionID, nil
+}
+
+func (f *FakeTranscriber) TranscriptionStatus(ctx context.Context, transcriptionID string) (*TranscriptionStatus, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	transcription, ok := f.transcriptions[transcriptionID]
+	if !ok {
+		return nil, &TranscriberAPIError{Provider: f.Name(), Operation: "transcription status", StatusCode: 404, Body: "unknown transcription " + transcriptionID}
+	}
+	transcription.polls++
+	if transcription.polls <= f.PendingPolls {
+		return &TranscriptionStatus{Status: TranscriptionProcessing}, nil
+	}
+	return &TranscriptionStatus{Status: TranscriptionCompleted}, nil
+}
+
+func (f *FakeTranscriber) Transcript(ctx context.Context, transcriptionID string) ([]TranscriptToken, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.transcriptions[transcriptionID]; !ok {
+		return nil, &TranscriberAPIError{Provider: f.Name(), Operation: "transcript fetch", StatusCode: 404, Body: "unknown transcription " + transcriptionID}
+	}
+	return append([]TranscriptToken(nil), f.Tokens...), nil
+}
+
+func (f *FakeTranscriber) DeleteTranscription(ctx context.Context, transcriptionID string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.transcriptions, transcriptionID)
+	return nil
+}
+
+func (f *FakeTranscriber) DeleteFile(ctx context.Context, fileID string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.files, fileID)
+	return nil
+}
+
+// Request returns the request a transcription not yet deleted was created with
+func (f *FakeTranscriber) Request(transcriptionID string) (TranscriptionRequest, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	transcription, ok := f.transcriptions[transcriptionID]
+	if !ok {
+		return TranscriptionRequest{}, false
+	}
+	return transcription.request, true
+}
+
+// next returns the next ID number. Callers hold f.mu.
+func (f *FakeTranscriber) next() int {
+	f.sequence++
+	return f.sequence
+}
+
+// fakeLine is one utterance of FakeConversation
+type fakeLine struct {
+	speaker  string
+	language string
+	startMs  int
+	text     string
+}
+
+// fakeLines is a short bilingual group discussion between three speakers, with a long
+// pause before the third line
+var fakeLines = []fakeLine{
+	{"1", "vi", 500, "Chào mọi người, em là Minh, hôm nay mình thảo luận về case study GLOVIA."},
+	{"2", "en", 6200, "Thanks Minh. I think we should start with the ecommerce channel because MT is slowing down."},
+	{"1", "vi", 14800, "Chị Lan nói đúng, nhưng kênh GT vẫn chiếm sáu mươi phần trăm doanh số."},
+	{"3", "vi", 21500, "Em đồng ý với anh Minh. Chúng ta cần số liệu cụ thể trước khi quyết định."},
+	{"2", "en", 27900, "Okay, let's split the work. Minh takes GT, I take ecommerce, Hoa prepares the budget."},
+	{"3", "vi", 35600, "Dạ được, em sẽ gửi bảng ngân sách trước năm giờ chiều."},
+}
+
+// FakeConversation returns FakeTranscriber's canned transcript as word tokens, laid out
+// the way Soniox returns them: a leading space on every word but the first, 350ms per
+// word and a confidence that varies with the word's position
+func FakeConversation() []TranscriptToken {
+	const wordMs = 350
+	tokens := []TranscriptToken{}
+	for _, line := range fakeLines {
+		for i, word := range strings.Fields(line.text) {
+			text := word
+			if len(tokens) > 0 {
+				text = " " + word
+			}
+			start := line.startMs + i*wordMs
+			tokens = append(tokens, TranscriptToken{
+				Text:       text,
+				StartMs:    start,
+				EndMs:      start + wordMs - 50,
+				Confidence: 0.78 + float64((i*7)%20)/100,
+				Speaker:    line.speaker,
+				Language:   line.language,
+			})
+		}
+	}
+	return tokens
+}
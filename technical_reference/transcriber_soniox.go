@@ -0,0 +1,240 @@
+package assessment
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// sonioxAsyncModel is the Soniox model used for async transcription
+const sonioxAsyncModel = "stt-async-preview-v1"
+
+// SonioxTranscriber runs transcription on Soniox's REST API
+type SonioxTranscriber struct {
+	apiKey  string
+	baseURL string
+	client  *http.Client
+	// Uploads run as fast as the browser sends the audio, so they get far longer than an
+	// API call
+	uploadClient *http.Client
+}
+
+// NewSonioxTranscriber creates a Soniox backend; baseURL defaults to https://api.soniox.com
+func NewSonioxTranscriber(apiKey, baseURL string) *SonioxTranscriber {
+	if baseURL == "" {
+		baseURL = "https://api.soniox.com"
+	}
+	return &SonioxTranscriber{
+		apiKey:       apiKey,
+		baseURL:      strings.TrimRight(baseURL, "/"),
+		client:       &http.Client{Timeout: 60 * time.Second},
+		uploadClient: &http.Client{Timeout: 30 * time.Minute},
+	}
+}
+
+// SonioxTranscriberFromEnv reads SONIOX_API_KEY and, optionally, SONIOX_API_URL
+func SonioxTranscriberFromEnv() *SonioxTranscriber {
+	return NewSonioxTranscriber(os.Getenv("SONIOX_API_KEY"), os.Getenv("SONIOX_API_URL"))
+}
+
+func (s *SonioxTranscriber) Name() string {
+	return "soniox"
+}
+
+func (s *SonioxTranscriber) TemporaryKey(ctx context.Context, expiresIn time.Duration) (string, error) {
+	var key struct {
+		APIKey string `json:"api_key"`
+	}
+	err := s.call(ctx, "temporary key", "POST", "/v1/auth/temporary-api-key", map[string]any{
+		"usage_type":         "transcribe_websocket",
+		"expires_in_seconds": int(expiresIn.Seconds()),
+	}, &key)
+	if err != nil {
+		return "", err
+	}
+	if key.APIKey == "" {
+		return "", errors.New("soniox temporary key response has no api_key")
+	}
+	return key.APIKey, nil
+}
+
+// UploadAudio streams the audio to Soniox through a pipe, so only a small buffer is held
+// at a time
+func (s *SonioxTranscriber) UploadAudio(ctx context.Context, filename string, audio io.Reader) (string, error) {
+	if s.apiKey == "" {
+		return "", ErrTranscriberNotConfigured
+	}
+
+	body, pipe := io.Pipe()
+	form := multipart.NewWriter(pipe)
+	copied := make(chan error, 1)
+	go func() {
+		part, err := form.CreateFormFile("file", filename)
+		if err == nil {
+			_, err = io.Copy(part, audio)
+		}
+		if err == nil {
+			err = form.Close()
+		}
+		copied <- err
+		pipe.CloseWithError(err)
+	}()
+
+	req, err := http.NewRequestWithContext(ctx, "POST", s.baseURL+"/v1/files", body)
+	if err != nil {
+		body.Close()
+		<-copied
+		return "", err
+	}
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	req.Header.Set("Authorization", "Bearer "+s.apiKey)
+
+	resp, err := s.uploadClient.Do(req)
+	// Unblock the copy if Soniox stopped reading early, then prefer its error, which says
+	// why the body ended (too large, browser went away)
+	body.Close()
+	if copyErr := <-copied; copyErr != nil && !errors.Is(copyErr, io.ErrClosedPipe) {
+		if resp != nil {
+			resp.Body.Close()
+		}
+		return "", copyErr
+	}
+	if err != nil {
+		return "", fmt.Errorf("soniox file upload: %w", err)
+	}
+
+	var file struct {
+		ID string `json:"id"`
+	}
+	if err := s.decode(resp, "file upload", &file); err != nil {
+		return "", err
+	}
+	return file.ID, nil
+}
+
+func (s *SonioxTranscriber) CreateTranscription(ctx context.Context, req TranscriptionRequest) (string, error) {
+	body := map[string]any{
+		"model":                      sonioxAsyncModel,
+		"file_id":                    req.FileID,
+		"enable_speaker_diarization": req.Diarization,
+	}
+	if len(req.LanguageHints) > 0 {
+		body["language_hints"] = req.LanguageHints
+	}
+	if req.Context != "" {
+		body["context"] = req.Context
+	}
+	if req.WebhookURL != "" {
+		body["webhook_url"] = req.WebhookURL
+		body["webhook_auth_header_name"] = req.WebhookAuthHeader
+		body["webhook_auth_header_value"] = req.WebhookAuthValue
+	}
+
+	var transcription struct {
+		ID string `json:"id"`
+	}
+	if err := s.call(ctx, "transcription create", "POST", "/v1/transcriptions", body, &transcription); err != nil {
+		return "", err
+	}
+	return transcription.ID, nil
+}
+
+func (s *SonioxTranscriber) TranscriptionStatus(ctx context.Context, transcriptionID string) (*TranscriptionStatus, error) {
+	var status TranscriptionStatus
+	if err := s.call(ctx, "transcription status", "GET", "/v1/transcriptions/"+transcriptionID, nil, &status); err != nil {
+		return nil, err
+	}
+	// Soniox reports "queued" before "processing"; both are still running
+	if status.Status != TranscriptionCompleted && status.Status != TranscriptionFailed {
+		status.Status = TranscriptionProcessing
+	}
+	return &status, nil
+}
+
+func (s *SonioxTranscriber) Transcript(ctx context.Context, transcriptionID string) ([]TranscriptToken, error) {
+	// The response has tokens array directly at root level
+	var transcript struct {
+		ID     string            `json:"id"`
+		Text   string            `json:"text"`
+		Tokens []TranscriptToken `json:"tokens"`
+	}
+	if err := s.call(ctx, "transcript fetch", "GET", "/v1/transcriptions/"+transcriptionID+"/transcript", nil, &transcript); err != nil {
+		return nil, err
+	}
+	return transcript.Tokens, nil
+}
+
+func (s *SonioxTranscriber) DeleteTranscription(ctx context.Context, transcriptionID string) error {
+	return s.delete(ctx, "transcription delete", "/v1/transcriptions/"+transcriptionID)
+}
+
+func (s *SonioxTranscriber) DeleteFile(ctx context.Context, fileID string) error {
+	return s.delete(ctx, "file delete", "/v1/files/"+fileID)
+}
+
+// delete removes a Soniox resource, treating one already gone as deleted
+func (s *SonioxTranscriber) delete(ctx context.Context, operation, path string) error {
+	err := s.call(ctx, operation, "DELETE", path, nil, nil)
+	var apiErr *TranscriberAPIError
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+		return nil
+	}
+	return err
+}
+
+// call sends a JSON request to the Soniox API and decodes a successful JSON answer into
+// out, unless out is nil
+func (s *SonioxTranscriber) call(ctx context.Context, operation, method, path string, body, out any) error {
+	if s.apiKey == "" {
+		return ErrTranscriberNotConfigured
+	}
+
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, s.baseURL+path, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Authorization", "Bearer "+s.apiKey)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("soniox %s: %w", operation, err)
+	}
+	return s.decode(resp, operation, out)
+}
+
+func (s *SonioxTranscriber) decode(resp *http.Response, operation string, out any) error {
+	defer resp.Body.Close()
+	payload, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("soniox %s: failed to read response: %w", operation, err)
+	}
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusNoContent {
+		return &TranscriberAPIError{Provider: s.Name(), Operation: operation, StatusCode: resp.StatusCode, Body: string(payload)}
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(payload, out); err != nil {
+		return fmt.Errorf("soniox %s: failed to parse response: %w", operation, err)
+	}
+	return nil
+}
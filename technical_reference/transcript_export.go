@@ -0,0 +1,268 @@
+package assessment
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Transcript export formats
+const (
+	ExportSRT      = "srt"
+	ExportWebVTT   = "vtt"
+	ExportDOCX     = "docx"
+	ExportText     = "txt"
+	ExportMarkdown = "md"
+)
+
+// ErrUnknownExportFormat is returned by ExportTranscript for an unsupported format
+var ErrUnknownExportFormat = errors.New("unknown transcript export format")
+
+// TranscriptDocument is a finished transcript ready to export
+type TranscriptDocument struct {
+	SessionID       string
+	TranscriptionID string
+	// TranscribedAt stamps the document; exports of the same transcript are byte for byte
+	// identical, so their SHA256 can be recorded as the archived copy's fingerprint
+	TranscribedAt time.Time
+	Segments      []TranscriptSegment
+}
+
+// ExportedTranscript is a rendered transcript file
+type ExportedTranscript struct {
+	Format      string
+	ContentType string
+	Filename    string
+	Body        []byte
+	// SHA256 is the hex digest of Body
+	SHA256 string
+}
+
+// ExportTranscript renders a transcript as SRT, WebVTT, DOCX, or timestamped plain text
+// or Markdown
+func ExportTranscript(format string, doc TranscriptDocument) (*ExportedTranscript, error) {
+	export := &ExportedTranscript{
+		Format:   format,
+		Filename: fmt.Sprintf("transcript-%s.%s", doc.SessionID, format),
+	}
+
+	var err error
+	switch format {
+	case ExportSRT:
+		export.ContentType = "application/x-subrip; charset=utf-8"
+		export.Body = renderSRT(doc)
+	case ExportWebVTT:
+		export.ContentType = "text/vtt; charset=utf-8"
+		export.Body = renderWebVTT(doc)
+	case ExportText:
+		export.ContentType = "text/plain; charset=utf-8"
+		export.Body = renderText(doc)
+	case ExportMarkdown:
+		export.ContentType = "text/markdown; charset=utf-8"
+		export.Body = renderMarkdown(doc)
+	case ExportDOCX:
+		export.ContentType = "application/vnd.openxmlformats-officedocument.wordprocessingml.document"
+		export.Body, err = renderDOCX(doc)
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownExportFormat, format)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	sum := sha256.Sum256(export.Body)
+	export.SHA256 = hex.EncodeToString(sum[:])
+	return export, nil
+}
+
+// documentSpeaker is one speaker of an exported transcript
+type documentSpeaker struct {
+	speaker       int
+	name          string
+	participantID string
+}
+
+// speakers lists the document's speakers in order of first speaking
+func (doc TranscriptDocument) speakers() []documentSpeaker {
+	speakers := []documentSpeaker{}
+	seen := make(map[int]bool)
+	for _, segment := range doc.Segments {
+		if seen[segment.Speaker] {
+			continue
+		}
+		seen[segment.Speaker] = true
+		speakers = append(speakers, documentSpeaker{segment.Speaker, segment.Name, segment.ParticipantID})
+	}
+	return speakers
+}
+
+// String renders a speaker as "Speaker N: Name (participant ID)"
+func (s documentSpeaker) String() string {
+	if s.participantID == "" {
+		return fmt.Sprintf("Speaker %d: %s", s.speaker, s.name)
+	}
+	return fmt.Sprintf("Speaker %d: %s (participant %s)", s.speaker, s.name, s.participantID)
+}
+
+// cueTime formats seconds as HH:MM:SS followed by sep and milliseconds
+func cueTime(seconds float64, sep string) string {
+	ms := int64(seconds*1000 + 0.5)
+	return fmt.Sprintf("%02d:%02d:%02d%s%03d", ms/3600000, ms/60000%60, ms/1000%60, sep, ms%1000)
+}
+
+// clockTime formats seconds as HH:MM:SS
+func clockTime(seconds float64) string {
+	s := int64(seconds)
+	return fmt.Sprintf("%02d:%02d:%02d", s/3600, s/60%60, s%60)
+}
+
+func renderSRT(doc TranscriptDocument) []byte {
+	var b bytes.Buffer
+	for i, segment := range doc.Segments {
+		fmt.Fprintf(&b, "%d\n%s --> %s\n%s: %s\n\n", i+1,
+			cueTime(segment.StartTime, ","), cueTime(segment.EndTime, ","), segment.Name, segment.Text)
+	}
+	return b.Bytes()
+}
+
+// vttEscaper escapes the characters WebVTT cue text reserves
+var vttEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+func renderWebVTT(doc TranscriptDocument) []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "WEBVTT\n\nNOTE session %s, transcription %s\n\n", doc.SessionID, doc.TranscriptionID)
+	if speakers := doc.speakers(); len(speakers) > 0 {
+		b.WriteString("NOTE speakers\n")
+		for _, speaker := range speakers {
+			// "-->" may not appear in a note
+			fmt.Fprintf(&b, "%s\n", strings.ReplaceAll(speaker.String(), "-->", "->"))
+		}
+		b.WriteString("\n")
+	}
+	for i, segment := range doc.Segments {
+		fmt.Fprintf(&b, "%d\n%s --> %s\n<v %s>%s\n\n", i+1,
+			cueTime(segment.StartTime, "."), cueTime(segment.EndTime, "."), vttEscaper.Replace(segment.Name), vttEscaper.Replace(segment.Text))
+	}
+	return b.Bytes()
+}
+
+func renderText(doc TranscriptDocument) []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "Session: %s\nTranscription: %s\nTranscribed: %s\n",
+		doc.SessionID, doc.TranscriptionID, doc.TranscribedAt.UTC().Format(time.RFC3339))
+	for _, speaker := range doc.speakers() {
+		fmt.Fprintf(&b, "%s\n", speaker)
+	}
+	b.WriteString("\n")
+	for _, segment := range doc.Segments {
+		fmt.Fprintf(&b, "[%s] %s: %s\n", clockTime(segment.StartTime), segment.Name, segment.Text)
+	}
+	return b.Bytes()
+}
+
+// markdownEscaper escapes characters that would start Markdown formatting mid-line
+var markdownEscaper = strings.NewReplacer(`\`, `\\`, "*", `\*`, "_", `\_`, "`", "\\`", "[", `\[`, "]", `\]`, "<", `\<`)
+
+func renderMarkdown(doc TranscriptDocument) []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "# Transcript\n\n- Session: `%s`\n- Transcription: `%s`\n- Transcribed: %s\n\n",
+		doc.SessionID, doc.TranscriptionID, doc.TranscribedAt.UTC().Format(time.RFC3339))
+	if speakers := doc.speakers(); len(speakers) > 0 {
+		b.WriteString("## Speakers\n\n")
+		for _, speaker := range speakers {
+			fmt.Fprintf(&b, "- %s\n", markdownEscaper.Replace(speaker.String()))
+		}
+		b.WriteString("\n")
+	}
+	for _, segment := range doc.Segments {
+		fmt.Fprintf(&b, "**\\[%s\\] %s:** %s\n\n", clockTime(segment.StartTime), markdownEscaper.Replace(segment.Name), markdownEscaper.Replace(segment.Text))
+	}
+	return b.Bytes()
+}
+
+// docxPart is one file of a DOCX package
+type docxPart struct{ name, body string }
+
+// docxParts are the fixed parts of a minimal WordprocessingML package
+var docxParts = []docxPart{
+	{"[Content_Types].xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/word/document.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.document.main+xml"/>` +
+		`</Types>`},
+	{"_rels/.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="word/document.xml"/>` +
+		`</Relationships>`},
+}
+
+func renderDOCX(doc TranscriptDocument) ([]byte, error) {
+	var body bytes.Buffer
+	body.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body>`)
+	docxParagraph(&body, docxRun{text: "Transcript", bold: true, size: 32})
+	docxParagraph(&body, docxRun{text: "Session: " + doc.SessionID})
+	docxParagraph(&body, docxRun{text: "Transcription: " + doc.TranscriptionID})
+	docxParagraph(&body, docxRun{text: "Transcribed: " + doc.TranscribedAt.UTC().Format(time.RFC3339)})
+	for _, speaker := range doc.speakers() {
+		docxParagraph(&body, docxRun{text: speaker.String()})
+	}
+	for _, segment := range doc.Segments {
+		docxParagraph(&body,
+			docxRun{text: fmt.Sprintf("[%s] %s: ", clockTime(segment.StartTime), segment.Name), bold: true},
+			docxRun{text: segment.Text})
+	}
+	body.WriteString(`</w:body></w:document>`)
+
+	// Entries carry the transcription time rather than now so the package is reproducible
+	var archive bytes.Buffer
+	zipWriter := zip.NewWriter(&archive)
+	parts := append(docxParts[:len(docxParts):len(docxParts)], docxPart{"word/document.xml", body.String()})
+	for _, part := range parts {
+		w, err := zipWriter.CreateHeader(&zip.FileHeader{Name: part.name, Method: zip.Deflate, Modified: doc.TranscribedAt.UTC()})
+		if err != nil {
+			return nil, err
+		}
+		if _, err := w.Write([]byte(part.body)); err != nil {
+			return nil, err
+		}
+	}
+	if err := zipWriter.Close(); err != nil {
+		return nil, err
+	}
+	return archive.Bytes(), nil
+}
+
+type docxRun struct {
+	text string
+	bold bool
+	size int // Half-points; 0 keeps the default
+}
+
+func docxParagraph(b *bytes.Buffer, runs ...docxRun) {
+	b.WriteString("<w:p>")
+	for _, run := range runs {
+		b.WriteString("<w:r>")
+		if run.bold || run.size > 0 {
+			b.WriteString("<w:rPr>")
+			if run.bold {
+				b.WriteString("<w:b/>")
+			}
+			if run.size > 0 {
+				fmt.Fprintf(b, `<w:sz w:val="%d"/>`, run.size)
+			}
+			b.WriteString("</w:rPr>")
+		}
+		b.WriteString(`<w:t xml:space="preserve">`)
+		xml.EscapeText(b, []byte(run.text))
+		b.WriteString("</w:t></w:r>")
+	}
+	b.WriteString("</w:p>")
+}
@@ -0,0 +1,144 @@
+package assessment
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testTranscriptDocument has a bound and an unbound speaker, text that needs escaping,
+// and a cue past the first hour
+var testTranscriptDocument = TranscriptDocument{
+	SessionID:       "s1",
+	TranscriptionID: "t1",
+	TranscribedAt:   time.Date(2026, 3, 2, 16, 30, 0, 0, time.FixedZone("ICT", 7*3600)),
+	Segments: []TranscriptSegment{
+		{Speaker: 1, Name: "Minh", ParticipantID: "p1", Text: "Chào <team> & co.", StartTime: 1.5, EndTime: 3.25},
+		{Speaker: 2, Name: "Lan", Text: "Focus on *GT* first.", StartTime: 3661, EndTime: 3662.0049},
+	},
+}
+
+// docxText returns the text of a DOCX package's document, one line per paragraph
+func docxText(body []byte) (string, error) {
+	archive, err := zip.NewReader(bytes.NewReader(body), int64(len(body)))
+	if err != nil {
+		return "", err
+	}
+	document, err := archive.Open("word/document.xml")
+	if err != nil {
+		return "", err
+	}
+	defer document.Close()
+
+	var text strings.Builder
+	inText := false
+	decoder := xml.NewDecoder(document)
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			return text.String(), nil
+		} else if err != nil {
+			return "", err
+		}
+		switch token := token.(type) {
+		case xml.StartElement:
+			inText = token.Name.Local == "t"
+		case xml.CharData:
+			if inText {
+				text.Write(token)
+			}
+		case xml.EndElement:
+			inText = false
+			if token.Name.Local == "p" {
+				text.WriteString("\n")
+			}
+		}
+	}
+}
+
+func TestExportTranscript(t *testing.T) {
+	tests := []struct {
+		format          string
+		wantContentType string
+		wantBody        string // For DOCX, the document's text
+	}{
+		{
+			format:          ExportSRT,
+			wantContentType: "application/x-subrip; charset=utf-8",
+			wantBody: "1\n00:00:01,500 --> 00:00:03,250\nMinh: Chào <team> & co.\n\n" +
+				"2\n01:01:01,000 --> 01:01:02,005\nLan: Focus on *GT* first.\n\n",
+		},
+		{
+			format:          ExportWebVTT,
+			wantContentType: "text/vtt; charset=utf-8",
+			wantBody: "WEBVTT\n\nNOTE session s1, transcription t1\n\n" +
+				"NOTE speakers\nSpeaker 1: Minh (participant p1)\nSpeaker 2: Lan\n\n" +
+				"1\n00:00:01.500 --> 00:00:03.250\n<v Minh>Chào &lt;team&gt; &amp; co.\n\n" +
+				"2\n01:01:01.000 --> 01:01:02.005\n<v Lan>Focus on *GT* first.\n\n",
+		},
+		{
+			format:          ExportText,
+			wantContentType: "text/plain; charset=utf-8",
+			wantBody: "Session: s1\nTranscription: t1\nTranscribed: 2026-03-02T09:30:00Z\n" +
+				"Speaker 1: Minh (participant p1)\nSpeaker 2: Lan\n\n" +
+				"[00:00:01] Minh: Chào <team> & co.\n[01:01:01] Lan: Focus on *GT* first.\n",
+		},
+		{
+			format:          ExportMarkdown,
+			wantContentType: "text/markdown; charset=utf-8",
+			wantBody: "# Transcript\n\n- Session: `s1`\n- Transcription: `t1`\n- Transcribed: 2026-03-02T09:30:00Z\n\n" +
+				"## Speakers\n\n- Speaker 1: Minh (participant p1)\n- Speaker 2: Lan\n\n" +
+				"**\\[00:00:01\\] Minh:** Chào \\<team> & co.\n\n**\\[01:01:01\\] Lan:** Focus on \\*GT\\* first.\n\n",
+		},
+		{
+			format:          ExportDOCX,
+			wantContentType: "application/vnd.openxmlformats-officedocument.wordprocessingml.document",
+			wantBody: "Transcript\nSession: s1\nTranscription: t1\nTranscribed: 2026-03-02T09:30:00Z\n" +
+				"Speaker 1: Minh (participant p1)\nSpeaker 2: Lan\n" +
+				"[00:00:01] Minh: Chào <team> & co.\n[01:01:01] Lan: Focus on *GT* first.\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			export, err := ExportTranscript(tt.format, testTranscriptDocument)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if export.ContentType != tt.wantContentType {
+				t.Errorf("content type %q, want %q", export.ContentType, tt.wantContentType)
+			}
+			if want := "transcript-s1." + tt.format; export.Filename != want {
+				t.Errorf("filename %q, want %q", export.Filename, want)
+			}
+
+			body := string(export.Body)
+			if tt.format == ExportDOCX {
+				if body, err = docxText(export.Body); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if body != tt.wantBody {
+				t.Errorf("body\n%s\nwant\n%s", body, tt.wantBody)
+			}
+
+			// Exporting again gives the same bytes, so the digest fingerprints the transcript
+			again, err := ExportTranscript(tt.format, testTranscriptDocument)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if again.SHA256 != export.SHA256 || len(export.SHA256) != 64 {
+				t.Errorf("digests %s and %s, want the same SHA256", export.SHA256, again.SHA256)
+			}
+		})
+	}
+
+	if _, err := ExportTranscript("pdf", testTranscriptDocument); !errors.Is(err, ErrUnknownExportFormat) {
+		t.Errorf("pdf export: %v, want %v", err, ErrUnknownExportFormat)
+	}
+}
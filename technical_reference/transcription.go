@@ -0,0 +1,278 @@
+package assessment
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Async transcription job statuses
+const (
+	TranscriptionProcessing = "processing"
+	TranscriptionCompleted  = "completed"
+	TranscriptionFailed     = "error"
+)
+
+// SonioxWebhookAuthHeader carries a transcription job's webhook secret on Soniox callbacks
+const SonioxWebhookAuthHeader = "X-Soniox-Webhook-Token"
+
+// TranscriptToken is one token of a finished async transcription, as returned by Soniox
+type TranscriptToken struct {
+	Text         string  `json:"text"`
+	StartMs      int     `json:"start_ms"`
+	EndMs        int     `json:"end_ms"`
+	Confidence   float64 `json:"confidence"`
+	Speaker      string  `json:"speaker,omitempty"`
+	Language     string  `json:"language,omitempty"`
+	IsAudioEvent *bool   `json:"is_audio_event,omitempty"`
+}
+
+// TranscriptSegment is a run of consecutive tokens from one speaker, split further by
+// SegmentationConfig
+type TranscriptSegment struct {
+	Speaker int    `json:"speaker"`
+	Name    string `json:"name"`
+	// ParticipantID is the roster participant the speaker is bound to, if any
+	ParticipantID string  `json:"participant_id,omitempty"`
+	Text          string  `json:"text"`
+	StartTime     float64 `json:"start_time"`
+	EndTime       float64 `json:"end_time"`
+	// Token confidence across the segment
+	AvgConfidence float64 `json:"avg_confidence"`
+	MinConfidence float64 `json:"min_confidence"`
+	// Language is the language most of the segment's text is in ("vi", "en")
+	Language string           `json:"language,omitempty"`
+	Words    []TranscriptWord `json:"words"`
+}
+
+// TranscriptWord is one word of a segment with its timing, for linking evidence back to
+// the recording. Its confidence is that of its least confident token.
+type TranscriptWord struct {
+	Text       string  `json:"text"`
+	StartMs    int     `json:"start_ms"`
+	EndMs      int     `json:"end_ms"`
+	Confidence float64 `json:"confidence"`
+}
+
+// SegmentationConfig controls where a speaker's speech is split into segments besides
+// speaker changes. Splits only fall between words.
+type SegmentationConfig struct {
+	// PauseMs splits at a silence longer than this; 0 disables
+	PauseMs int
+	// SplitSentences splits after sentence-ending punctuation
+	SplitSentences bool
+	// MaxDurationMs splits before the word that would make a segment longer than this;
+	// 0 disables
+	MaxDurationMs int
+}
+
+// SegmentationConfigFromEnv reads TRANSCRIPT_SPLIT_PAUSE_MS, TRANSCRIPT_SPLIT_SENTENCES
+// and TRANSCRIPT_SEGMENT_MAX_MS
+func SegmentationConfigFromEnv() SegmentationConfig {
+	config := SegmentationConfig{
+		PauseMs:        1500,
+		SplitSentences: false,
+		MaxDurationMs:  30000,
+	}
+	if pause, err := strconv.Atoi(os.Getenv("TRANSCRIPT_SPLIT_PAUSE_MS")); err == nil && pause >= 0 {
+		config.PauseMs = pause
+	}
+	if sentences, err := strconv.ParseBool(os.Getenv("TRANSCRIPT_SPLIT_SENTENCES")); err == nil {
+		config.SplitSentences = sentences
+	}
+	if maxDuration, err := strconv.Atoi(os.Getenv("TRANSCRIPT_SEGMENT_MAX_MS")); err == nil && maxDuration >= 0 {
+		config.MaxDurationMs = maxDuration
+	}
+	return config
+}
+
+// Segments groups the job's stored tokens into speaker segments, named by the resolved
+// speaker names once there are any, and carrying the participant each speaker is bound to.
+// Until names are resolved, a bound speaker without a manual correction is named after
+// its participant.
+func (job *TranscriptionJob) Segments(config SegmentationConfig) []TranscriptSegment {
+	mapping := job.SpeakerMapping
+	participants := make(map[int]string)
+	if len(job.SpeakerNames) > 0 {
+		mapping = speakerNameMapping(job.SpeakerNames)
+		for _, name := range job.SpeakerNames {
+			participants[name.Speaker] = name.ParticipantID
+		}
+	} else if len(job.SpeakerBindings) > 0 {
+		mapping = make(map[int]string, len(job.SpeakerMapping)+len(job.SpeakerBindings))
+		for speaker, name := range job.SpeakerMapping {
+			mapping[speaker] = name
+		}
+		for speaker, participantID := range job.SpeakerBindings {
+			if participant, ok := rosterParticipant(participantID, job.Participants, nil); ok {
+				mapping[speaker] = participant.Name
+			}
+		}
+	}
+	for speaker, participantID := range job.SpeakerBindings {
+		participants[speaker] = participantID
+	}
+
+	segments := SegmentTranscript(job.Tokens, mapping, job.ManualCorrections, config)
+	for i := range segments {
+		segments[i].ParticipantID = participants[segments[i].Speaker]
+	}
+	return segments
+}
+
+// SegmentTranscript groups tokens into speaker segments, skipping audio events, and
+// splits them further as config says. Speakers are named from manualCorrections first,
+// then speakerMapping, then "Speaker N".
+func SegmentTranscript(tokens []TranscriptToken, speakerMapping, manualCorrections map[int]string, config SegmentationConfig) []TranscriptSegment {
+	segments := []TranscriptSegment{}
+	var current *segmentBuilder
+
+	for _, token := range tokens {
+		if token.IsAudioEvent != nil && *token.IsAudioEvent {
+			continue
+		}
+
+		// Soniox speakers are numbered strings ("1", "2", ...)
+		speakerID := 0
+		if token.Speaker != "" {
+			fmt.Sscanf(token.Speaker, "%d", &speakerID)
+		}
+
+		if current != nil && (current.speaker != speakerID || current.splitBefore(token, config)) {
+			segments = append(segments, current.segment())
+			current = nil
+		}
+		if current == nil {
+			current = &segmentBuilder{
+				speaker:   speakerID,
+				name:      speakerName(speakerID, speakerMapping, manualCorrections),
+				startMs:   token.StartMs,
+				minConf:   1,
+				languages: make(map[string]int),
+			}
+		}
+		current.add(token)
+	}
+
+	if current != nil {
+		segments = append(segments, current.segment())
+	}
+	return segments
+}
+
+// segmentBuilder accumulates one segment's tokens
+type segmentBuilder struct {
+	speaker   int
+	name      string
+	text      strings.Builder
+	startMs   int
+	endMs     int
+	lastText  string
+	confSum   float64
+	minConf   float64
+	tokens    int
+	languages map[string]int
+	words     []TranscriptWord
+}
+
+// startsWord reports whether a token begins a new word. Soniox tokens can be pieces of
+// a word; a new word's token starts with a space.
+func startsWord(token TranscriptToken) bool {
+	return token.Text != "" && unicode.IsSpace([]rune(token.Text)[0])
+}
+
+func (b *segmentBuilder) splitBefore(token TranscriptToken, config SegmentationConfig) bool {
+	if !startsWord(token) {
+		return false
+	}
+	if config.PauseMs > 0 && token.StartMs-b.endMs > config.PauseMs {
+		return true
+	}
+	if config.SplitSentences && strings.ContainsAny(lastRune(strings.TrimSpace(b.lastText)), ".?!…") {
+		return true
+	}
+	return config.MaxDurationMs > 0 && token.EndMs-b.startMs > config.MaxDurationMs
+}
+
+func lastRune(s string) string {
+	if s == "" {
+		return ""
+	}
+	runes := []rune(s)
+	return string(runes[len(runes)-1])
+}
+
+func (b *segmentBuilder) add(token TranscriptToken) {
+	b.text.WriteString(token.Text)
+	b.endMs = token.EndMs
+	b.lastText = token.Text
+	b.confSum += token.Confidence
+	b.minConf = min(b.minConf, token.Confidence)
+	b.tokens++
+	if token.Language != "" {
+		b.languages[token.Language] += len([]rune(strings.TrimSpace(token.Text)))
+	}
+
+	text := strings.TrimSpace(token.Text)
+	if len(b.words) == 0 || startsWord(token) {
+		b.words = append(b.words, TranscriptWord{Text: text, StartMs: token.StartMs, EndMs: token.EndMs, Confidence: token.Confidence})
+		return
+	}
+	word := &b.words[len(b.words)-1]
+	word.Text += text
+	word.EndMs = token.EndMs
+	word.Confidence = min(word.Confidence, token.Confidence)
+}
+
+func (b *segmentBuilder) segment() TranscriptSegment {
+	segment := TranscriptSegment{
+		Speaker:       b.speaker,
+		Name:          b.name,
+		Text:          strings.TrimSpace(b.text.String()),
+		StartTime:     float64(b.startMs) / 1000.0,
+		EndTime:       float64(b.endMs) / 1000.0,
+		AvgConfidence: b.confSum / float64(b.tokens),
+		MinConfidence: b.minConf,
+		Words:         b.words,
+	}
+	// Ties go to the alphabetically first language so output is stable
+	for language, chars := range b.languages {
+		if chars > b.languages[segment.Language] || (chars == b.languages[segment.Language] && language < segment.Language) {
+			segment.Language = language
+		}
+	}
+	return segment
+}
+
+func speakerName(speakerID int, speakerMapping, manualCorrections map[int]string) string {
+	if name, ok := manualCorrections[speakerID]; ok {
+		return name
+	}
+	if name, ok := speakerMapping[speakerID]; ok {
+		return name
+	}
+	return fmt.Sprintf("Speaker %d", speakerID)
+}
+
+// NewTranscriptionWebhookSecret generates the secret Soniox sends back on a job's callback
+func NewTranscriptionWebhookSecret() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(secret), nil
+}
+
+// VerifyTranscriptionWebhookSecret reports whether a callback carried the job's secret.
+// Jobs submitted without a webhook have no secret and never verify.
+func VerifyTranscriptionWebhookSecret(job *TranscriptionJob, presented string) bool {
+	if job.WebhookSecret == "" {
+		return false
+	}
+	return hmac.Equal([]byte(job.WebhookSecret), []byte(presented))
+}
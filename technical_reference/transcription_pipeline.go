@@ -0,0 +1,236 @@
+package assessment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Stages of the async pipeline, from uploaded recording to consolidated assessment
+const (
+	PipelineTranscribing = "transcribing"
+	// PipelineNamingSpeakers is a finished transcript whose speakers are being identified
+	PipelineNamingSpeakers = "naming_speakers"
+	// PipelineTranscribed is a finished transcript whose assessment hasn't been queued
+	PipelineTranscribed = "transcribed"
+	PipelineAssessing   = "assessing"
+	PipelineCompleted   = "completed"
+	PipelineFailed      = "failed"
+)
+
+// SessionParticipant is someone on a session's roster
+type SessionParticipant struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	Role       string `json:"role,omitempty"`
+	Department string `json:"department,omitempty"`
+}
+
+// SessionRosterResolver returns the participants of a session from the system of record,
+// so speaker bindings can be checked against it
+type SessionRosterResolver func(ctx context.Context, sessionID string) ([]SessionParticipant, error)
+
+// ErrInvalidSpeakerBinding is returned by ValidateSpeakerBindings
+var ErrInvalidSpeakerBinding = errors.New("invalid speaker binding")
+
+// ValidateSpeakerBindings checks that every bound speaker's participant is on the session
+// roster. A participant may be bound to several speakers, since diarization can split one
+// person's speech.
+func ValidateSpeakerBindings(bindings map[int]string, roster []SessionParticipant) error {
+	onRoster := make(map[string]bool, len(roster))
+	for _, participant := range roster {
+		onRoster[participant.ID] = true
+	}
+	speakers := make([]int, 0, len(bindings))
+	for speaker := range bindings {
+		speakers = append(speakers, speaker)
+	}
+	sort.Ints(speakers)
+	for _, speaker := range speakers {
+		if speaker < 0 {
+			return fmt.Errorf("%w: speaker %d is not a diarized speaker number", ErrInvalidSpeakerBinding, speaker)
+		}
+		if !onRoster[bindings[speaker]] {
+			return fmt.Errorf("%w: speaker %d: participant %s is not in this session", ErrInvalidSpeakerBinding, speaker, bindings[speaker])
+		}
+	}
+	return nil
+}
+
+// ConversationTurn is one speaker's uninterrupted turn in a transcribed conversation
+type ConversationTurn struct {
+	Speaker       int     `json:"speaker"`
+	ParticipantID string  `json:"participant_id"`
+	Name          string  `json:"name"`
+	Role          string  `json:"role,omitempty"`
+	Text          string  `json:"text"`
+	StartTime     float64 `json:"start_time"`
+	EndTime       float64 `json:"end_time"`
+}
+
+// PipelineStatus is the single status of a session's async pipeline: transcription,
+// then the consolidated and group assessment queued from it
+type PipelineStatus struct {
+	SessionID           string        `json:"session_id"`
+	Stage               string        `json:"stage"`
+	TranscriptionID     string        `json:"transcription_id"`
+	TranscriptionStatus string        `json:"transcription_status"`
+	Speakers            []SpeakerName `json:"speakers,omitempty"`
+	AssessmentJobID     string        `json:"assessment_job_id,omitempty"`
+	AssessmentStatus    string        `json:"assessment_status,omitempty"`
+	Error               string        `json:"error,omitempty"`
+	SubmittedAt         time.Time     `json:"submitted_at"`
+	TranscribedAt       *time.Time    `json:"transcribed_at,omitempty"`
+	AssessedAt          *time.Time    `json:"assessed_at,omitempty"`
+}
+
+// NewPipelineStatus derives the pipeline status from a transcription job and the
+// assessment job queued from it, which is nil when there is none yet
+func NewPipelineStatus(transcription *TranscriptionJob, assessment *Job) *PipelineStatus {
+	status := &PipelineStatus{
+		SessionID:           transcription.SessionID,
+		TranscriptionID:     transcription.TranscriptionID,
+		TranscriptionStatus: transcription.Status,
+		SubmittedAt:         transcription.CreatedAt,
+		TranscribedAt:       transcription.CompletedAt,
+		Speakers:            transcription.SpeakerNames,
+	}
+
+	switch {
+	case transcription.Status == TranscriptionFailed:
+		status.Stage = PipelineFailed
+		status.Error = transcription.ErrorMessage
+		return status
+	case transcription.Status != TranscriptionCompleted:
+		status.Stage = PipelineTranscribing
+		return status
+	case assessment == nil && transcription.SpeakersNamedAt == nil:
+		status.Stage = PipelineNamingSpeakers
+		return status
+	case assessment == nil:
+		status.Stage = PipelineTranscribed
+		return status
+	}
+
+	status.AssessmentJobID = assessment.ID
+	status.AssessmentStatus = assessment.Status
+	switch assessment.Status {
+	case JobStatusSucceeded:
+		status.Stage = PipelineCompleted
+		status.AssessedAt = assessment.FinishedAt
+	case JobStatusFailed, JobStatusCancelled:
+		status.Stage = PipelineFailed
+		status.Error = assessment.LastError
+	default:
+		status.Stage = PipelineAssessing
+	}
+	return status
+}
+
+// BindSpeakers binds each diarized speaker to a roster participant: by the participant ID
+// its segments carry, else by matching its name with rosterMatch in order of first
+// speaking. Matching by name binds each participant at most once; speakers left over
+// become "speaker-N" participants under their own name.
+func BindSpeakers(segments []TranscriptSegment, roster []SessionParticipant) map[int]SessionParticipant {
+	bindings := make(map[int]SessionParticipant)
+	taken := make(map[string]bool)
+	for _, segment := range segments {
+		if _, ok := bindings[segment.Speaker]; ok || segment.ParticipantID == "" {
+			continue
+		}
+		participant, ok := rosterParticipant(segment.ParticipantID, roster, nil)
+		if !ok {
+			participant = SessionParticipant{ID: segment.ParticipantID, Name: segment.Name}
+		}
+		bindings[segment.Speaker] = participant
+		taken[participant.ID] = true
+	}
+	for _, segment := range segments {
+		if _, ok := bindings[segment.Speaker]; ok {
+			continue
+		}
+		if participant, ok := rosterMatch(segment.Name, roster, taken); ok {
+			bindings[segment.Speaker] = participant
+			taken[participant.ID] = true
+			continue
+		}
+		bindings[segment.Speaker] = SessionParticipant{ID: fmt.Sprintf("speaker-%d", segment.Speaker), Name: segment.Name}
+	}
+	return bindings
+}
+
+// rosterMatch finds the participant not yet taken whose name is name or, if only one
+// fits, ends with it ("Minh" for "Nguyễn Văn Minh"), ignoring case and spacing
+func rosterMatch(name string, roster []SessionParticipant, taken map[string]bool) (SessionParticipant, bool) {
+	name = normalizeName(name)
+	if name == "" {
+		return SessionParticipant{}, false
+	}
+	matches := []func(participant string) bool{
+		func(participant string) bool { return participant == name },
+		func(participant string) bool { return strings.HasSuffix(participant, " "+name) },
+	}
+	for _, match := range matches {
+		var found []SessionParticipant
+		for _, participant := range roster {
+			if !taken[participant.ID] && match(normalizeName(participant.Name)) {
+				found = append(found, participant)
+			}
+		}
+		if len(found) == 1 {
+			return found[0], true
+		}
+		if len(found) > 1 {
+			return SessionParticipant{}, false // Ambiguous
+		}
+	}
+	return SessionParticipant{}, false
+}
+
+func normalizeName(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
+
+// ConversationTurns merges consecutive segments of the same speaker into turns, naming
+// each after the participant its speaker is bound to
+func ConversationTurns(segments []TranscriptSegment, bindings map[int]SessionParticipant) []ConversationTurn {
+	turns := []ConversationTurn{}
+	for _, segment := range segments {
+		text := strings.TrimSpace(segment.Text)
+		if text == "" {
+			continue
+		}
+		if n := len(turns); n > 0 && turns[n-1].Speaker == segment.Speaker {
+			turns[n-1].Text += " " + text
+			turns[n-1].EndTime = segment.EndTime
+			continue
+		}
+		participant := bindings[segment.Speaker]
+		turns = append(turns, ConversationTurn{
+			Speaker:       segment.Speaker,
+			ParticipantID: participant.ID,
+			Name:          participant.Name,
+			Role:          participant.Role,
+			Text:          text,
+			StartTime:     segment.StartTime,
+			EndTime:       segment.EndTime,
+		})
+	}
+	return turns
+}
+
+// FormatConversation renders turns in order as "[Name - Role]: text" lines
+func FormatConversation(turns []ConversationTurn) string {
+	var b strings.Builder
+	for _, turn := range turns {
+		if turn.Role != "" {
+			fmt.Fprintf(&b, "[%s - %s]: %s\n", turn.Name, turn.Role, turn.Text)
+		} else {
+			fmt.Fprintf(&b, "[%s]: %s\n", turn.Name, turn.Text)
+		}
+	}
+	return b.String()
+}
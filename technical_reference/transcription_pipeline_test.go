@@ -0,0 +1,240 @@
+package assessment
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// testRoster is the roster the pipeline tests bind speakers against
+var testRoster = []SessionParticipant{
+	{ID: "p1", Name: "Nguyễn Văn Minh", Role: "GT Channel Manager"},
+	{ID: "p2", Name: "Trần Thị Lan", Role: "Ecommerce Manager"},
+	{ID: "p3", Name: "Lê Văn Minh", Role: "MT Channel Manager"},
+}
+
+func TestNewPipelineStatus(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name          string
+		transcription TranscriptionJob
+		assessment    *Job
+		wantStage     string
+		wantError     string
+	}{
+		{name: "transcribing", transcription: TranscriptionJob{Status: TranscriptionProcessing}, wantStage: PipelineTranscribing},
+		{name: "transcription failed", transcription: TranscriptionJob{Status: TranscriptionFailed, ErrorMessage: "audio decode failed"}, wantStage: PipelineFailed, wantError: "audio decode failed"},
+		{name: "naming speakers", transcription: TranscriptionJob{Status: TranscriptionCompleted}, wantStage: PipelineNamingSpeakers},
+		{name: "transcribed", transcription: TranscriptionJob{Status: TranscriptionCompleted, SpeakersNamedAt: &now}, wantStage: PipelineTranscribed},
+		{name: "assessing", transcription: TranscriptionJob{Status: TranscriptionCompleted}, assessment: &Job{ID: "job", Status: JobStatusRunning}, wantStage: PipelineAssessing},
+		{name: "assessment queued for retry", transcription: TranscriptionJob{Status: TranscriptionCompleted}, assessment: &Job{ID: "job", Status: JobStatusQueued, LastError: "rate limited"}, wantStage: PipelineAssessing},
+		{name: "completed", transcription: TranscriptionJob{Status: TranscriptionCompleted}, assessment: &Job{ID: "job", Status: JobStatusSucceeded, FinishedAt: &now}, wantStage: PipelineCompleted},
+		{name: "assessment failed", transcription: TranscriptionJob{Status: TranscriptionCompleted}, assessment: &Job{ID: "job", Status: JobStatusFailed, LastError: "invalid JSON"}, wantStage: PipelineFailed, wantError: "invalid JSON"},
+		{name: "assessment cancelled", transcription: TranscriptionJob{Status: TranscriptionCompleted}, assessment: &Job{ID: "job", Status: JobStatusCancelled}, wantStage: PipelineFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status := NewPipelineStatus(&tt.transcription, tt.assessment)
+			if status.Stage != tt.wantStage {
+				t.Errorf("stage %s, want %s", status.Stage, tt.wantStage)
+			}
+			if status.Error != tt.wantError {
+				t.Errorf("error %q, want %q", status.Error, tt.wantError)
+			}
+			if tt.assessment != nil && (status.AssessmentJobID != tt.assessment.ID || status.AssessmentStatus != tt.assessment.Status) {
+				t.Errorf("assessment %s %s, want %s %s", status.AssessmentJobID, status.AssessmentStatus, tt.assessment.ID, tt.assessment.Status)
+			}
+			if tt.wantStage == PipelineCompleted && status.AssessedAt == nil {
+				t.Error("completed pipeline has no assessment time")
+			}
+		})
+	}
+}
+
+func TestBindSpeakers(t *testing.T) {
+	tests := []struct {
+		name     string
+		segments []TranscriptSegment
+		want     map[int]string // speaker to participant ID
+	}{
+		{
+			name:     "bound participant IDs",
+			segments: []TranscriptSegment{{Speaker: 1, Name: "Speaker 1", ParticipantID: "p2"}, {Speaker: 2, Name: "Speaker 2", ParticipantID: "p1"}},
+			want:     map[int]string{1: "p2", 2: "p1"},
+		},
+		{
+			name:     "full names",
+			segments: []TranscriptSegment{{Speaker: 1, Name: "nguyễn  văn minh"}, {Speaker: 2, Name: "Trần Thị Lan"}},
+			want:     map[int]string{1: "p1", 2: "p2"},
+		},
+		{
+			name:     "unique given name",
+			segments: []TranscriptSegment{{Speaker: 1, Name: "Lan"}},
+			want:     map[int]string{1: "p2"},
+		},
+		{
+			name:     "ambiguous given name",
+			segments: []TranscriptSegment{{Speaker: 1, Name: "Minh"}},
+			want:     map[int]string{1: "speaker-1"},
+		},
+		{
+			name:     "given name unambiguous once one is bound",
+			segments: []TranscriptSegment{{Speaker: 2, Name: "Minh"}, {Speaker: 1, ParticipantID: "p1"}},
+			want:     map[int]string{1: "p1", 2: "p3"},
+		},
+		{
+			name:     "participant matched by name only once",
+			segments: []TranscriptSegment{{Speaker: 1, Name: "Lan"}, {Speaker: 2, Name: "Lan"}},
+			want:     map[int]string{1: "p2", 2: "speaker-2"},
+		},
+		{
+			name:     "participant ID off the roster",
+			segments: []TranscriptSegment{{Speaker: 1, Name: "Guest", ParticipantID: "observer"}},
+			want:     map[int]string{1: "observer"},
+		},
+		{
+			name:     "unknown speaker",
+			segments: []TranscriptSegment{{Speaker: 3, Name: "Speaker 3"}},
+			want:     map[int]string{3: "speaker-3"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bindings := BindSpeakers(tt.segments, testRoster)
+			if len(bindings) != len(tt.want) {
+				t.Fatalf("bound %d speakers, want %d: %+v", len(bindings), len(tt.want), bindings)
+			}
+			for speaker, id := range tt.want {
+				if bindings[speaker].ID != id {
+					t.Errorf("speaker %d bound to %q, want %q", speaker, bindings[speaker].ID, id)
+				}
+			}
+		})
+	}
+}
+
+func TestConversationTurns(t *testing.T) {
+	bindings := map[int]SessionParticipant{1: testRoster[0], 2: testRoster[1]}
+	tests := []struct {
+		name     string
+		segments []TranscriptSegment
+		want     []ConversationTurn
+		wantText string
+	}{
+		{
+			name: "alternating speakers",
+			segments: []TranscriptSegment{
+				{Speaker: 1, Text: "Let's start with GT.", StartTime: 0, EndTime: 2},
+				{Speaker: 2, Text: "Ecommerce grows faster.", StartTime: 2.5, EndTime: 4},
+			},
+			want: []ConversationTurn{
+				{Speaker: 1, ParticipantID: "p1", Name: "Nguyễn Văn Minh", Role: "GT Channel Manager", Text: "Let's start with GT.", StartTime: 0, EndTime: 2},
+				{Speaker: 2, ParticipantID: "p2", Name: "Trần Thị Lan", Role: "Ecommerce Manager", Text: "Ecommerce grows faster.", StartTime: 2.5, EndTime: 4},
+			},
+			wantText: "[Nguyễn Văn Minh - GT Channel Manager]: Let's start with GT.\n[Trần Thị Lan - Ecommerce Manager]: Ecommerce grows faster.\n",
+		},
+		{
+			name: "consecutive segments merged",
+			segments: []TranscriptSegment{
+				{Speaker: 1, Text: "First point.", StartTime: 0, EndTime: 2},
+				{Speaker: 1, Text: " ", StartTime: 2, EndTime: 3},
+				{Speaker: 1, Text: "Second point. ", StartTime: 4, EndTime: 6},
+			},
+			want: []ConversationTurn{
+				{Speaker: 1, ParticipantID: "p1", Name: "Nguyễn Văn Minh", Role: "GT Channel Manager", Text: "First point. Second point.", StartTime: 0, EndTime: 6},
+			},
+			wantText: "[Nguyễn Văn Minh - GT Channel Manager]: First point. Second point.\n",
+		},
+		{
+			name:     "unbound speaker",
+			segments: []TranscriptSegment{{Speaker: 3, Text: "Hello.", StartTime: 0, EndTime: 1}},
+			want:     []ConversationTurn{{Speaker: 3, Text: "Hello.", StartTime: 0, EndTime: 1}},
+			wantText: "[]: Hello.\n",
+		},
+		{
+			name:     "silence",
+			segments: []TranscriptSegment{{Speaker: 1, Text: ""}},
+			want:     []ConversationTurn{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			turns := ConversationTurns(tt.segments, bindings)
+			assertSameJSON(t, turns, tt.want)
+			if text := FormatConversation(turns); text != tt.wantText {
+				t.Errorf("formatted %q, want %q", text, tt.wantText)
+			}
+		})
+	}
+}
+
+func TestValidateSpeakerBindings(t *testing.T) {
+	tests := []struct {
+		name     string
+		bindings map[int]string
+		wantErr  bool
+	}{
+		{name: "no bindings"},
+		{name: "every speaker bound", bindings: map[int]string{1: "p1", 2: "p2", 3: "p3"}},
+		{name: "participant split across speakers", bindings: map[int]string{1: "p1", 4: "p1"}},
+		{name: "participant not in the session", bindings: map[int]string{1: "p1", 2: "p9"}, wantErr: true},
+		{name: "empty participant", bindings: map[int]string{1: ""}, wantErr: true},
+		{name: "negative speaker", bindings: map[int]string{-1: "p1"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateSpeakerBindings(tt.bindings, testRoster)
+			if tt.wantErr != errors.Is(err, ErrInvalidSpeakerBinding) || (!tt.wantErr && err != nil) {
+				t.Errorf("ValidateSpeakerBindings() = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTranscriptionJobSegmentsUseBindings(t *testing.T) {
+	tokens := []TranscriptToken{
+		{Text: "Chào", StartMs: 0, EndMs: 300, Speaker: "1"},
+		{Text: " Đồng ý", StartMs: 400, EndMs: 800, Speaker: "2"},
+		{Text: " Okay", StartMs: 900, EndMs: 1200, Speaker: "3"},
+	}
+	tests := []struct {
+		name string
+		job  TranscriptionJob
+		want []string // "name|participant" per segment
+	}{
+		{
+			name: "bound speakers named after their participant",
+			job:  TranscriptionJob{SpeakerBindings: map[int]string{1: "p2", 2: "p9"}, SpeakerMapping: map[int]string{2: "Hùng", 3: "Hoa"}},
+			want: []string{"Trần Thị Lan|p2", "Hùng|p9", "Hoa|"},
+		},
+		{
+			name: "manual correction names a bound speaker",
+			job:  TranscriptionJob{SpeakerBindings: map[int]string{1: "p2"}, ManualCorrections: map[int]string{1: "chị Lan"}},
+			want: []string{"chị Lan|p2", "Speaker 2|", "Speaker 3|"},
+		},
+		{
+			name: "resolved names win",
+			job: TranscriptionJob{SpeakerBindings: map[int]string{1: "p2"}, SpeakerNames: []SpeakerName{
+				{Speaker: 1, Name: "Trần Thị Lan", ParticipantID: "p2"},
+				{Speaker: 2, Name: "Nguyễn Văn Minh", ParticipantID: "p1"},
+				{Speaker: 3, Name: "Speaker 3"},
+			}},
+			want: []string{"Trần Thị Lan|p2", "Nguyễn Văn Minh|p1", "Speaker 3|"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.job.Tokens, tt.job.Participants = tokens, testRoster
+			got := []string{}
+			for _, segment := range tt.job.Segments(SegmentationConfig{}) {
+				got = append(got, segment.Name+"|"+segment.ParticipantID)
+			}
+			assertSameJSON(t, got, tt.want)
+		})
+	}
+}
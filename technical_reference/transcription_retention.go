@@ -0,0 +1,397 @@
+package assessment
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Remote resources an async transcription leaves with the transcriber
+const (
+	RemoteFile          = "file"
+	RemoteTranscription = "transcription"
+)
+
+// Reasons remote transcription data is deleted
+const (
+	// DeletionRetrieved follows storing the finished transcript
+	DeletionRetrieved = "retrieved"
+	// DeletionExpired is the sweeper enforcing a retention period
+	DeletionExpired = "expired"
+	// DeletionReplaced is a session's transcription superseded by a new submission
+	DeletionReplaced = "replaced"
+	// DeletionAbandoned is audio uploaded for a submission that then failed
+	DeletionAbandoned = "abandoned"
+)
+
+// RetentionPolicy is how long a client's recordings and transcripts may stay with the
+// transcriber
+type RetentionPolicy struct {
+	// DeleteAfterRetrieval deletes the remote file and transcription once the finished
+	// transcript is stored
+	DeleteAfterRetrieval bool
+	// MaxAge deletes them this long after submission whatever their state; 0 disables
+	MaxAge time.Duration
+}
+
+// RetentionConfig holds the default retention policy and per-client overrides
+type RetentionConfig struct {
+	Default RetentionPolicy
+	Clients map[string]RetentionPolicy
+	// SweepInterval is how often expired remote data is looked for
+	SweepInterval time.Duration
+}
+
+// RetentionConfigFromEnv reads TRANSCRIPTION_RETENTION_DELETE_AFTER_RETRIEVAL,
+// TRANSCRIPTION_RETENTION_DAYS, TRANSCRIPTION_RETENTION_SWEEP_INTERVAL and
+// TRANSCRIPTION_RETENTION_CLIENTS, a JSON object of client ID to
+// {"delete_after_retrieval": bool, "days": n}; fields a client leaves out keep the default
+func RetentionConfigFromEnv() (RetentionConfig, error) {
+	config := RetentionConfig{
+		Default: RetentionPolicy{
+			DeleteAfterRetrieval: true,
+			MaxAge:               30 * 24 * time.Hour,
+		},
+		Clients:       make(map[string]RetentionPolicy),
+		SweepInterval: time.Hour,
+	}
+	if deleteAfter, err := strconv.ParseBool(os.Getenv("TRANSCRIPTION_RETENTION_DELETE_AFTER_RETRIEVAL")); err == nil {
+		config.Default.DeleteAfterRetrieval = deleteAfter
+	}
+	if days, err := strconv.Atoi(os.Getenv("TRANSCRIPTION_RETENTION_DAYS")); err == nil && days >= 0 {
+		config.Default.MaxAge = time.Duration(days) * 24 * time.Hour
+	}
+	if interval, err := time.ParseDuration(os.Getenv("TRANSCRIPTION_RETENTION_SWEEP_INTERVAL")); err == nil && interval > 0 {
+		config.SweepInterval = interval
+	}
+
+	raw := os.Getenv("TRANSCRIPTION_RETENTION_CLIENTS")
+	if raw == "" {
+		return config, nil
+	}
+	var clients map[string]struct {
+		DeleteAfterRetrieval *bool `json:"delete_after_retrieval"`
+		Days                 *int  `json:"days"`
+	}
+	if err := json.Unmarshal([]byte(raw), &clients); err != nil {
+		return config, fmt.Errorf("invalid TRANSCRIPTION_RETENTION_CLIENTS: %w", err)
+	}
+	for clientID, client := range clients {
+		policy := config.Default
+		if client.DeleteAfterRetrieval != nil {
+			policy.DeleteAfterRetrieval = *client.DeleteAfterRetrieval
+		}
+		if client.Days != nil {
+			if *client.Days < 0 {
+				return config, fmt.Errorf("invalid TRANSCRIPTION_RETENTION_CLIENTS: negative days for client %q", clientID)
+			}
+			policy.MaxAge = time.Duration(*client.Days) * 24 * time.Hour
+		}
+		config.Clients[clientID] = policy
+	}
+	return config, nil
+}
+
+// Policy returns a client's retention policy, or the default
+func (c RetentionConfig) Policy(clientID string) RetentionPolicy {
+	if policy, ok := c.Clients[clientID]; ok {
+		return policy
+	}
+	return c.Default
+}
+
+// RemoteDeletion is one entry of the deletion log: a file or transcription deleted from
+// the transcriber, kept for compliance after the job itself is replaced
+type RemoteDeletion struct {
+	ID        string    `json:"id"`
+	SessionID string    `json:"session_id"`
+	ClientID  string    `json:"client_id,omitempty"`
+	Provider  string    `json:"provider"`
+	Resource  string    `json:"resource"`
+	RemoteID  string    `json:"remote_id"`
+	Reason    string    `json:"reason"`
+	DeletedAt time.Time `json:"deleted_at"`
+}
+
+// PendingRemoteDeletion is remote data no job may refer to any more, kept until its
+// deletion is logged so the sweep retries a deletion that failed
+type PendingRemoteDeletion struct {
+	ID        string    `json:"id"`
+	SessionID string    `json:"session_id"`
+	Resource  string    `json:"resource"`
+	RemoteID  string    `json:"remote_id"`
+	Reason    string    `json:"reason"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// HasRemoteData reports whether the job's file or transcription may still be with the
+// transcriber
+func (job *TranscriptionJob) HasRemoteData() bool {
+	return (job.FileID != "" && job.FileDeletedAt == nil) || (job.TranscriptionID != "" && job.TranscriptionDeletedAt == nil)
+}
+
+// TranscriptionRetention deletes async transcription files and transcriptions from the
+// transcriber as each client's retention policy says: after the transcript is retrieved,
+// and from a periodic sweep once they are too old. Every deletion is logged.
+type TranscriptionRetention struct {
+	results       ResultRepository
+	transcriber   Transcriber
+	config        RetentionConfig
+	resolveClient SessionClientResolver
+	logger        *AssessmentLogger
+
+	stop context.CancelFunc
+	wg   sync.WaitGroup
+}
+
+// NewTranscriptionRetention creates the retention enforcer; a nil resolveClient applies
+// the default policy to every session
+func NewTranscriptionRetention(results ResultRepository, transcriber Transcriber, config RetentionConfig, resolveClient SessionClientResolver, logger *AssessmentLogger) *TranscriptionRetention {
+	if resolveClient == nil {
+		resolveClient = func(ctx context.Context, sessionID string) (string, error) { return "", nil }
+	}
+	return &TranscriptionRetention{
+		results:       results,
+		transcriber:   transcriber,
+		config:        config,
+		resolveClient: resolveClient,
+		logger:        logger,
+	}
+}
+
+// AfterRetrieval deletes a job's remote data if its client's policy deletes it once the
+// transcript is stored
+func (r *TranscriptionRetention) AfterRetrieval(ctx context.Context, job *TranscriptionJob) error {
+	clientID, err := r.resolveClient(ctx, job.SessionID)
+	if err != nil {
+		return fmt.Errorf("failed to resolve session client: %w", err)
+	}
+	if !r.config.Policy(clientID).DeleteAfterRetrieval {
+		return nil
+	}
+	return r.delete(ctx, job, clientID, DeletionRetrieved)
+}
+
+// Delete deletes whatever of a job's file and transcription is still with the transcriber
+func (r *TranscriptionRetention) Delete(ctx context.Context, job *TranscriptionJob, reason string) error {
+	clientID, err := r.resolveClient(ctx, job.SessionID)
+	if err != nil {
+		return fmt.Errorf("failed to resolve session client: %w", err)
+	}
+	return r.delete(ctx, job, clientID, reason)
+}
+
+// Orphan records whatever of a job's file and transcription is still with the
+// transcriber as pending deletion. It is called before the job's row is overwritten or
+// given up on, so the remote data stays findable until Delete or the sweep removes it.
+func (r *TranscriptionRetention) Orphan(ctx context.Context, job *TranscriptionJob, reason string) error {
+	now := time.Now().UTC()
+	var pending []PendingRemoteDeletion
+	for _, resource := range []struct {
+		resource, remoteID string
+		deletedAt          *time.Time
+	}{
+		{RemoteTranscription, job.TranscriptionID, job.TranscriptionDeletedAt},
+		{RemoteFile, job.FileID, job.FileDeletedAt},
+	} {
+		if resource.remoteID == "" || resource.deletedAt != nil {
+			continue
+		}
+		pending = append(pending, PendingRemoteDeletion{
+			ID:        newRecordID(),
+			SessionID: job.SessionID,
+			Resource:  resource.resource,
+			RemoteID:  resource.remoteID,
+			Reason:    reason,
+			CreatedAt: now,
+		})
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+	if err := r.results.SavePendingRemoteDeletions(ctx, pending); err != nil {
+		r.logger.ForSession(job.SessionID).Error("failed to record pending remote deletion", slog.String("error", err.Error()))
+		return err
+	}
+	return nil
+}
+
+// Abandon deletes the remote data of a submission that failed before its job was stored,
+// leaving it pending deletion for the sweep if that fails
+func (r *TranscriptionRetention) Abandon(ctx context.Context, job *TranscriptionJob) error {
+	// Orphan logs its own failure, and a deletion that succeeds needs no pending entry
+	r.Orphan(ctx, job, DeletionAbandoned)
+	return r.Delete(ctx, job, DeletionAbandoned)
+}
+
+// delete removes the transcription before the file it was made from, recording each
+// deletion as it succeeds so a failure part way is finished by the next sweep
+func (r *TranscriptionRetention) delete(ctx context.Context, job *TranscriptionJob, clientID, reason string) error {
+	resources := []struct {
+		resource, remoteID string
+		deletedAt          *time.Time
+		remove             func(ctx context.Context, id string) error
+	}{
+		{RemoteTranscription, job.TranscriptionID, job.TranscriptionDeletedAt, r.transcriber.DeleteTranscription},
+		{RemoteFile, job.FileID, job.FileDeletedAt, r.transcriber.DeleteFile},
+	}
+
+	logger := r.logger.ForSession(job.SessionID).With(slog.String("transcription_id", job.TranscriptionID))
+	for _, resource := range resources {
+		if resource.remoteID == "" || resource.deletedAt != nil {
+			continue
+		}
+		if err := resource.remove(ctx, resource.remoteID); err != nil {
+			logger.Error("failed to delete remote transcription data", slog.String("resource", resource.resource), slog.String("error", err.Error()))
+			return err
+		}
+
+		deletion := RemoteDeletion{
+			ID:        newRecordID(),
+			SessionID: job.SessionID,
+			ClientID:  clientID,
+			Provider:  r.transcriber.Name(),
+			Resource:  resource.resource,
+			RemoteID:  resource.remoteID,
+			Reason:    reason,
+			DeletedAt: time.Now().UTC(),
+		}
+		if err := r.results.RecordRemoteDeletion(ctx, deletion); err != nil {
+			logger.Error("failed to record remote deletion", slog.String("resource", resource.resource), slog.String("error", err.Error()))
+			return err
+		}
+		if resource.resource == RemoteFile {
+			job.FileDeletedAt = &deletion.DeletedAt
+		} else {
+			job.TranscriptionDeletedAt = &deletion.DeletedAt
+		}
+		logger.Info("remote transcription data deleted", slog.String("resource", resource.resource),
+			slog.String("remote_id", resource.remoteID), slog.String("reason", reason))
+	}
+	return nil
+}
+
+// Sweep deletes the remote data pending deletion, of jobs past their client's retention
+// period, and of finished jobs whose deletion after retrieval failed. It returns the
+// first error after trying every job.
+func (r *TranscriptionRetention) Sweep(ctx context.Context) error {
+	firstErr := r.sweepPending(ctx)
+	jobs, err := r.results.ListTranscriptionJobsWithRemoteData(ctx)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for i := range jobs {
+		job := &jobs[i]
+		clientID, err := r.resolveClient(ctx, job.SessionID)
+		if err != nil {
+			r.logger.ForSession(job.SessionID).Error("failed to resolve session client", slog.String("error", err.Error()))
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+
+		policy := r.config.Policy(clientID)
+		reason := ""
+		switch {
+		case policy.MaxAge > 0 && now.Sub(job.CreatedAt) > policy.MaxAge:
+			reason = DeletionExpired
+		case policy.DeleteAfterRetrieval && job.Status == TranscriptionCompleted && job.CompletedAt != nil:
+			reason = DeletionRetrieved
+		}
+		if reason == "" {
+			continue
+		}
+		if err := r.delete(ctx, job, clientID, reason); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// sweepPending deletes pending remote data the session's job doesn't refer to. Data the
+// job does refer to, because the submission replacing it failed to store, is left to the
+// job's own policy; its deletion clears the pending entry.
+func (r *TranscriptionRetention) sweepPending(ctx context.Context) error {
+	pending, err := r.results.ListPendingRemoteDeletions(ctx)
+	if err != nil {
+		return err
+	}
+
+	var firstErr error
+	for _, deletion := range pending {
+		current, err := r.results.GetTranscriptionJob(ctx, deletion.SessionID)
+		if err != nil && !errors.Is(err, ErrResultNotFound) {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		if current != nil && ((deletion.Resource == RemoteFile && current.FileID == deletion.RemoteID) ||
+			(deletion.Resource == RemoteTranscription && current.TranscriptionID == deletion.RemoteID)) {
+			continue
+		}
+
+		// A job holding just the pending resource deletes it alone
+		orphan := &TranscriptionJob{SessionID: deletion.SessionID}
+		if deletion.Resource == RemoteFile {
+			orphan.FileID = deletion.RemoteID
+		} else {
+			orphan.TranscriptionID = deletion.RemoteID
+		}
+		if err := r.Delete(ctx, orphan, deletion.Reason); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// Start sweeps every SweepInterval until ctx is cancelled or Shutdown is called
+func (r *TranscriptionRetention) Start(ctx context.Context) {
+	sweepCtx, stop := context.WithCancel(ctx)
+	r.stop = stop
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		ticker := time.NewTicker(r.config.SweepInterval)
+		defer ticker.Stop()
+		for {
+			if err := r.Sweep(sweepCtx); err != nil && sweepCtx.Err() == nil {
+				r.logger.Warn("retention sweep incomplete", slog.String("error", err.Error()))
+			}
+			select {
+			case <-sweepCtx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Shutdown stops the sweeper, waiting for a sweep in progress until ctx expires
+func (r *TranscriptionRetention) Shutdown(ctx context.Context) error {
+	if r.stop == nil {
+		return nil
+	}
+	r.stop()
+
+	stopped := make(chan struct{})
+	go func() {
+		r.wg.Wait()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,111 @@
+package assessment
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTranscriptionRetentionSweep(t *testing.T) {
+	now := time.Now().UTC()
+	completed := func(age time.Duration) TranscriptionJob {
+		return TranscriptionJob{Status: TranscriptionCompleted, CreatedAt: now.Add(-age), CompletedAt: &now}
+	}
+	processing := func(age time.Duration) TranscriptionJob {
+		return TranscriptionJob{Status: TranscriptionProcessing, CreatedAt: now.Add(-age)}
+	}
+	config := RetentionConfig{
+		Default: RetentionPolicy{MaxAge: 30 * 24 * time.Hour},
+		Clients: map[string]RetentionPolicy{"strict": {DeleteAfterRetrieval: true, MaxAge: 24 * time.Hour}},
+	}
+	tests := []struct {
+		name        string
+		client      string
+		job         TranscriptionJob
+		fileDeleted bool // whether the file was deleted before the sweep
+		pending     *PendingRemoteDeletion
+		want        []string // "resource reason" deletions, in order
+		wantLeft    bool     // whether the job still has remote data
+	}{
+		{name: "recent transcription", job: processing(time.Hour), want: []string{}, wantLeft: true},
+		{name: "retrieved, kept by the default policy", job: completed(time.Hour), want: []string{}, wantLeft: true},
+		{name: "past the default retention", job: completed(31 * 24 * time.Hour), want: []string{"transcription expired", "file expired"}},
+		{name: "retrieved, deleted by the client policy", client: "strict", job: completed(time.Hour), want: []string{"transcription retrieved", "file retrieved"}},
+		{name: "past the client retention", client: "strict", job: processing(2 * 24 * time.Hour), want: []string{"transcription expired", "file expired"}},
+		{name: "recent, within the client retention", client: "strict", job: processing(time.Hour), want: []string{}, wantLeft: true},
+		{name: "file already deleted", job: completed(31 * 24 * time.Hour), fileDeleted: true, want: []string{"file retrieved", "transcription expired"}},
+		{
+			name:     "pending deletion of replaced data",
+			job:      processing(time.Hour),
+			pending:  &PendingRemoteDeletion{Resource: RemoteFile, RemoteID: "old-file", Reason: DeletionReplaced},
+			want:     []string{"file replaced"},
+			wantLeft: true,
+		},
+	}
+
+	for _, repository := range testResultRepositories {
+		for _, tt := range tests {
+			t.Run(repository.name+"/"+tt.name, func(t *testing.T) {
+				ctx := context.Background()
+				results := repository.open(t)
+				sessionID := "session"
+				if tt.client != "" {
+					sessionID = tt.client + "-session"
+				}
+				job := tt.job
+				job.SessionID, job.FileID, job.TranscriptionID = sessionID, "file", "transcription"
+				if err := results.SaveTranscriptionJob(ctx, job); err != nil {
+					t.Fatal(err)
+				}
+				if tt.fileDeleted {
+					deletion := RemoteDeletion{ID: newRecordID(), SessionID: sessionID, Provider: "fake", Resource: RemoteFile, RemoteID: "file", Reason: DeletionRetrieved, DeletedAt: now}
+					if err := results.RecordRemoteDeletion(ctx, deletion); err != nil {
+						t.Fatal(err)
+					}
+				}
+				if tt.pending != nil {
+					pending := *tt.pending
+					pending.ID, pending.SessionID, pending.CreatedAt = newRecordID(), sessionID, now
+					if err := results.SavePendingRemoteDeletions(ctx, []PendingRemoteDeletion{pending}); err != nil {
+						t.Fatal(err)
+					}
+				}
+
+				resolveClient := func(ctx context.Context, sessionID string) (string, error) {
+					client, _, _ := strings.Cut(sessionID, "-session")
+					if client == sessionID {
+						return "", nil
+					}
+					return client, nil
+				}
+				retention := NewTranscriptionRetention(results, NewFakeTranscriber(), config, resolveClient, NewAssessmentLogger(io.Discard, LoggingConfig{}))
+				if err := retention.Sweep(ctx); err != nil {
+					t.Fatal(err)
+				}
+
+				deletions, err := results.ListRemoteDeletions(ctx, sessionID)
+				if err != nil {
+					t.Fatal(err)
+				}
+				got := []string{}
+				for _, deletion := range deletions {
+					got = append(got, deletion.Resource+" "+deletion.Reason)
+				}
+				assertSameJSON(t, got, tt.want)
+
+				stored, err := results.GetTranscriptionJob(ctx, sessionID)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if stored.HasRemoteData() != tt.wantLeft {
+					t.Errorf("job has remote data %v, want %v", stored.HasRemoteData(), tt.wantLeft)
+				}
+				if pending, err := results.ListPendingRemoteDeletions(ctx); err != nil || len(pending) != 0 {
+					t.Errorf("pending deletions %+v, %v; want none", pending, err)
+				}
+			})
+		}
+	}
+}
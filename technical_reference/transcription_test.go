@@ -0,0 +1,103 @@
+package assessment
+
+import (
+	"math"
+	"testing"
+)
+
+// testTokens is a short Vietnamese and English exchange: speaker 1 greets, pauses, goes
+// on in English and laughs, then speaker 2 agrees
+var testTokens = func() []TranscriptToken {
+	audioEvent := true
+	return []TranscriptToken{
+		{Text: "Xin", StartMs: 0, EndMs: 200, Confidence: 0.9, Speaker: "1", Language: "vi"},
+		{Text: " chào.", StartMs: 200, EndMs: 600, Confidence: 0.7, Speaker: "1", Language: "vi"},
+		{Text: " Bắt", StartMs: 700, EndMs: 900, Confidence: 0.9, Speaker: "1", Language: "vi"},
+		{Text: " đầu", StartMs: 900, EndMs: 1200, Confidence: 0.9, Speaker: "1", Language: "vi"},
+		{Text: " now", StartMs: 3000, EndMs: 3300, Confidence: 0.95, Speaker: "1", Language: "en"},
+		{Text: " ok", StartMs: 3300, EndMs: 3500, Confidence: 0.5, Speaker: "1", Language: "en"},
+		{Text: "ay", StartMs: 3500, EndMs: 3600, Confidence: 0.6, Speaker: "1", Language: "en"},
+		{Text: " [laughter]", StartMs: 3600, EndMs: 3800, Confidence: 1, Speaker: "1", IsAudioEvent: &audioEvent},
+		{Text: " Đồng", StartMs: 3900, EndMs: 4100, Confidence: 0.8, Speaker: "2", Language: "vi"},
+		{Text: " ý.", StartMs: 4100, EndMs: 4400, Confidence: 0.9, Speaker: "2", Language: "vi"},
+	}
+}()
+
+func TestSegmentTranscriptSplits(t *testing.T) {
+	tests := []struct {
+		name   string
+		config SegmentationConfig
+		want   []string
+	}{
+		{name: "speaker changes only", want: []string{"Xin chào. Bắt đầu now okay", "Đồng ý."}},
+		{name: "pause", config: SegmentationConfig{PauseMs: 1000}, want: []string{"Xin chào. Bắt đầu", "now okay", "Đồng ý."}},
+		{name: "pause shorter than the limit", config: SegmentationConfig{PauseMs: 2000}, want: []string{"Xin chào. Bắt đầu now okay", "Đồng ý."}},
+		{name: "sentences", config: SegmentationConfig{SplitSentences: true}, want: []string{"Xin chào.", "Bắt đầu now okay", "Đồng ý."}},
+		{name: "max duration", config: SegmentationConfig{MaxDurationMs: 1000}, want: []string{"Xin chào. Bắt", "đầu", "now okay", "Đồng ý."}},
+		{name: "max duration keeps words whole", config: SegmentationConfig{MaxDurationMs: 400}, want: []string{"Xin", "chào.", "Bắt", "đầu", "now", "okay", "Đồng", "ý."}},
+		{name: "all splits", config: SegmentationConfig{PauseMs: 1000, SplitSentences: true, MaxDurationMs: 30000}, want: []string{"Xin chào.", "Bắt đầu", "now okay", "Đồng ý."}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			segments := SegmentTranscript(testTokens, nil, nil, tt.config)
+			got := []string{}
+			for _, segment := range segments {
+				got = append(got, segment.Text)
+			}
+			assertSameJSON(t, got, tt.want)
+		})
+	}
+}
+
+func TestSegmentTranscriptDetails(t *testing.T) {
+	segments := SegmentTranscript(testTokens, map[int]string{1: "Minh", 2: "Lan"}, map[int]string{2: "Trần Thị Lan"}, SegmentationConfig{PauseMs: 1000})
+	if len(segments) != 3 {
+		t.Fatalf("%d segments, want 3", len(segments))
+	}
+	tests := []struct {
+		name    string
+		segment TranscriptSegment
+		want    TranscriptSegment
+	}{
+		{
+			name:    "vietnamese greeting",
+			segment: segments[0],
+			want: TranscriptSegment{Speaker: 1, Name: "Minh", Text: "Xin chào. Bắt đầu", StartTime: 0, EndTime: 1.2,
+				AvgConfidence: 0.85, MinConfidence: 0.7, Language: "vi", Words: []TranscriptWord{
+					{Text: "Xin", StartMs: 0, EndMs: 200, Confidence: 0.9},
+					{Text: "chào.", StartMs: 200, EndMs: 600, Confidence: 0.7},
+					{Text: "Bắt", StartMs: 700, EndMs: 900, Confidence: 0.9},
+					{Text: "đầu", StartMs: 900, EndMs: 1200, Confidence: 0.9},
+				}},
+		},
+		{
+			name:    "english after a pause, without the laughter",
+			segment: segments[1],
+			want: TranscriptSegment{Speaker: 1, Name: "Minh", Text: "now okay", StartTime: 3, EndTime: 3.6,
+				AvgConfidence: (0.95 + 0.5 + 0.6) / 3, MinConfidence: 0.5, Language: "en", Words: []TranscriptWord{
+					{Text: "now", StartMs: 3000, EndMs: 3300, Confidence: 0.95},
+					{Text: "okay", StartMs: 3300, EndMs: 3600, Confidence: 0.5},
+				}},
+		},
+		{
+			name:    "manual correction names the speaker",
+			segment: segments[2],
+			want: TranscriptSegment{Speaker: 2, Name: "Trần Thị Lan", Text: "Đồng ý.", StartTime: 3.9, EndTime: 4.4,
+				AvgConfidence: 0.85, MinConfidence: 0.8, Language: "vi", Words: []TranscriptWord{
+					{Text: "Đồng", StartMs: 3900, EndMs: 4100, Confidence: 0.8},
+					{Text: "ý.", StartMs: 4100, EndMs: 4400, Confidence: 0.9},
+				}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if math.Abs(tt.segment.AvgConfidence-tt.want.AvgConfidence) > 1e-9 {
+				t.Errorf("average confidence %v, want %v", tt.segment.AvgConfidence, tt.want.AvgConfidence)
+			}
+			tt.segment.AvgConfidence = tt.want.AvgConfidence
+			assertSameJSON(t, tt.segment, tt.want)
+		})
+	}
+}
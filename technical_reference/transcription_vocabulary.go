@@ -0,0 +1,146 @@
+package assessment
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// ErrUnknownCaseStudy is returned for a case study with no vocabulary definition
+var ErrUnknownCaseStudy = errors.New("unknown case study")
+
+// CaseStudyVocabulary is the part of a case study definition that tunes recognition: the
+// languages spoken and the names and jargon of the case
+type CaseStudyVocabulary struct {
+	ID            string   `json:"id"`
+	Name          string   `json:"name,omitempty"`
+	LanguageHints []string `json:"language_hints"`
+	Terms         []string `json:"terms"`
+}
+
+// builtinCaseStudies are the case studies run before vocabulary was configurable
+var builtinCaseStudies = []CaseStudyVocabulary{
+	{
+		ID:            "glovia",
+		Name:          "GLOVIA (Heineken)",
+		LanguageHints: []string{"en", "vi"},
+		Terms:         []string{"GLOVIA", "Heineken", "MT", "GT", "ecommerce", "assessment center"},
+	},
+	{
+		ID:            "vietinbank",
+		Name:          "VietinBank",
+		LanguageHints: []string{"vi", "en"},
+		Terms:         []string{"VietinBank", "CASA", "NPL", "Basel III", "assessment center"},
+	},
+}
+
+// VocabularyConfig holds the case study vocabularies and the one sessions without a case
+// study use
+type VocabularyConfig struct {
+	CaseStudies map[string]CaseStudyVocabulary
+	Default     string
+}
+
+// VocabularyConfigFromEnv reads TRANSCRIPTION_CASE_STUDIES, a JSON array of
+// {"id", "name", "language_hints", "terms"} case studies that are added to or replace the
+// built-in ones, and TRANSCRIPTION_DEFAULT_CASE_STUDY (default "glovia")
+func VocabularyConfigFromEnv() (VocabularyConfig, error) {
+	config := VocabularyConfig{
+		CaseStudies: make(map[string]CaseStudyVocabulary),
+		Default:     "glovia",
+	}
+	for _, caseStudy := range builtinCaseStudies {
+		config.CaseStudies[caseStudy.ID] = caseStudy
+	}
+
+	if raw := os.Getenv("TRANSCRIPTION_CASE_STUDIES"); raw != "" {
+		var caseStudies []CaseStudyVocabulary
+		if err := json.Unmarshal([]byte(raw), &caseStudies); err != nil {
+			return config, fmt.Errorf("invalid TRANSCRIPTION_CASE_STUDIES: %w", err)
+		}
+		for _, caseStudy := range caseStudies {
+			caseStudy.ID = normalizeCaseStudyID(caseStudy.ID)
+			if caseStudy.ID == "" {
+				return config, fmt.Errorf("invalid TRANSCRIPTION_CASE_STUDIES: case study without an id")
+			}
+			config.CaseStudies[caseStudy.ID] = caseStudy
+		}
+	}
+	if id := normalizeCaseStudyID(os.Getenv("TRANSCRIPTION_DEFAULT_CASE_STUDY")); id != "" {
+		if _, ok := config.CaseStudies[id]; !ok {
+			return config, fmt.Errorf("invalid TRANSCRIPTION_DEFAULT_CASE_STUDY: %w: %q", ErrUnknownCaseStudy, id)
+		}
+		config.Default = id
+	}
+	return config, nil
+}
+
+// SessionVocabulary is what the system of record knows about a session's recognition
+// needs: its case study, and terms and languages particular to the session
+type SessionVocabulary struct {
+	CaseStudyID   string
+	LanguageHints []string
+	Terms         []string
+}
+
+// SessionVocabularyResolver returns a session's case study and vocabulary from the system
+// of record
+type SessionVocabularyResolver func(ctx context.Context, sessionID string) (SessionVocabulary, error)
+
+// TranscriptionVocabulary is the language hints and context sent with a transcription
+type TranscriptionVocabulary struct {
+	CaseStudyID   string   `json:"case_study"`
+	LanguageHints []string `json:"language_hints"`
+	Terms         []string `json:"terms"`
+}
+
+// Context renders the terms as the free-text context the transcriber biases recognition with
+func (v TranscriptionVocabulary) Context() string {
+	return strings.Join(v.Terms, ", ")
+}
+
+// Vocabulary builds a session's transcription vocabulary from its case study, or the
+// default one when caseStudyID is empty. The session's language hints, when it has any,
+// replace the case study's; its terms are added to the case study's.
+func (c VocabularyConfig) Vocabulary(caseStudyID string, session SessionVocabulary) (TranscriptionVocabulary, error) {
+	caseStudyID = normalizeCaseStudyID(caseStudyID)
+	if caseStudyID == "" {
+		caseStudyID = c.Default
+	}
+	caseStudy, ok := c.CaseStudies[caseStudyID]
+	if !ok {
+		return TranscriptionVocabulary{}, fmt.Errorf("%w: %q", ErrUnknownCaseStudy, caseStudyID)
+	}
+
+	vocabulary := TranscriptionVocabulary{
+		CaseStudyID:   caseStudyID,
+		LanguageHints: uniqueTerms(caseStudy.LanguageHints),
+		Terms:         uniqueTerms(append(append([]string(nil), caseStudy.Terms...), session.Terms...)),
+	}
+	if hints := uniqueTerms(session.LanguageHints); len(hints) > 0 {
+		vocabulary.LanguageHints = hints
+	}
+	return vocabulary, nil
+}
+
+func normalizeCaseStudyID(id string) string {
+	return strings.ToLower(strings.TrimSpace(id))
+}
+
+// uniqueTerms trims terms and drops empty ones and repeats, ignoring case, keeping order
+func uniqueTerms(terms []string) []string {
+	unique := []string{}
+	seen := make(map[string]bool, len(terms))
+	for _, term := range terms {
+		term = strings.Join(strings.Fields(term), " ")
+		if term == "" || seen[strings.ToLower(term)] {
+			continue
+		}
+		seen[strings.ToLower(term)] = true
+		unique = append(unique, term)
+	}
+	return unique
+}
@@ -0,0 +1,138 @@
+package assessment
+
+import (
+	"errors"
+	"sort"
+	"testing"
+)
+
+func TestVocabularyConfigFromEnv(t *testing.T) {
+	tests := []struct {
+		name            string
+		caseStudies     string
+		defaultStudy    string
+		wantErr         bool
+		wantDefault     string
+		wantCaseStudies []string
+		wantGloviaTerms []string
+	}{
+		{
+			name:            "built-in case studies",
+			wantDefault:     "glovia",
+			wantCaseStudies: []string{"glovia", "vietinbank"},
+			wantGloviaTerms: []string{"GLOVIA", "Heineken", "MT", "GT", "ecommerce", "assessment center"},
+		},
+		{
+			name:            "case study added and made the default",
+			caseStudies:     `[{"id": " ACB ", "language_hints": ["vi"], "terms": ["ACB", "KYC"]}]`,
+			defaultStudy:    "acb",
+			wantDefault:     "acb",
+			wantCaseStudies: []string{"acb", "glovia", "vietinbank"},
+			wantGloviaTerms: []string{"GLOVIA", "Heineken", "MT", "GT", "ecommerce", "assessment center"},
+		},
+		{
+			name:            "built-in case study replaced",
+			caseStudies:     `[{"id": "GLOVIA", "language_hints": ["en"], "terms": ["Tiger"]}]`,
+			wantDefault:     "glovia",
+			wantCaseStudies: []string{"glovia", "vietinbank"},
+			wantGloviaTerms: []string{"Tiger"},
+		},
+		{name: "malformed case studies", caseStudies: `{"id": "acb"}`, wantErr: true},
+		{name: "case study without an id", caseStudies: `[{"terms": ["ACB"]}]`, wantErr: true},
+		{name: "unknown default", defaultStudy: "acb", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("TRANSCRIPTION_CASE_STUDIES", tt.caseStudies)
+			t.Setenv("TRANSCRIPTION_DEFAULT_CASE_STUDY", tt.defaultStudy)
+			config, err := VocabularyConfigFromEnv()
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("got %+v, want an error", config)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if config.Default != tt.wantDefault {
+				t.Errorf("default %q, want %q", config.Default, tt.wantDefault)
+			}
+			ids := []string{}
+			for id := range config.CaseStudies {
+				ids = append(ids, id)
+			}
+			sort.Strings(ids)
+			assertSameJSON(t, ids, tt.wantCaseStudies)
+			assertSameJSON(t, config.CaseStudies["glovia"].Terms, tt.wantGloviaTerms)
+		})
+	}
+}
+
+func TestVocabularyConfigVocabulary(t *testing.T) {
+	t.Setenv("TRANSCRIPTION_CASE_STUDIES", "")
+	t.Setenv("TRANSCRIPTION_DEFAULT_CASE_STUDY", "")
+	config, err := VocabularyConfigFromEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name        string
+		caseStudyID string
+		session     SessionVocabulary
+		want        TranscriptionVocabulary
+		wantContext string
+		wantErr     error
+	}{
+		{
+			name:        "default case study",
+			want:        TranscriptionVocabulary{CaseStudyID: "glovia", LanguageHints: []string{"en", "vi"}, Terms: []string{"GLOVIA", "Heineken", "MT", "GT", "ecommerce", "assessment center"}},
+			wantContext: "GLOVIA, Heineken, MT, GT, ecommerce, assessment center",
+		},
+		{
+			name:        "case study by ID",
+			caseStudyID: " VietinBank ",
+			want:        TranscriptionVocabulary{CaseStudyID: "vietinbank", LanguageHints: []string{"vi", "en"}, Terms: []string{"VietinBank", "CASA", "NPL", "Basel III", "assessment center"}},
+			wantContext: "VietinBank, CASA, NPL, Basel III, assessment center",
+		},
+		{
+			name:        "session terms added once",
+			caseStudyID: "vietinbank",
+			session:     SessionVocabulary{Terms: []string{"casa", " Lê  Thị Hoa ", "", "LÊ THỊ HOA"}},
+			want:        TranscriptionVocabulary{CaseStudyID: "vietinbank", LanguageHints: []string{"vi", "en"}, Terms: []string{"VietinBank", "CASA", "NPL", "Basel III", "assessment center", "Lê Thị Hoa"}},
+			wantContext: "VietinBank, CASA, NPL, Basel III, assessment center, Lê Thị Hoa",
+		},
+		{
+			name:        "session language hints replace the case study's",
+			caseStudyID: "glovia",
+			session:     SessionVocabulary{LanguageHints: []string{"vi", " vi", ""}},
+			want:        TranscriptionVocabulary{CaseStudyID: "glovia", LanguageHints: []string{"vi"}, Terms: []string{"GLOVIA", "Heineken", "MT", "GT", "ecommerce", "assessment center"}},
+			wantContext: "GLOVIA, Heineken, MT, GT, ecommerce, assessment center",
+		},
+		{
+			name:        "unknown case study",
+			caseStudyID: "acb",
+			wantErr:     ErrUnknownCaseStudy,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vocabulary, err := config.Vocabulary(tt.caseStudyID, tt.session)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("got %+v, %v; want %v", vocabulary, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			assertSameJSON(t, vocabulary, tt.want)
+			if context := vocabulary.Context(); context != tt.wantContext {
+				t.Errorf("context %q, want %q", context, tt.wantContext)
+			}
+		})
+	}
+}
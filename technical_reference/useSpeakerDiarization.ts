@@ -15,8 +15,18 @@ export interface SpeakerSegment {
   tokens: Token[];
 }
 
+// Temporary key response; the server adds the session's case study vocabulary
+export interface TemporaryKey {
+  apiKey: string;
+  languageHints?: string[];
+  context?: string;
+}
+
+const toTemporaryKey = (key: string | TemporaryKey): TemporaryKey =>
+  typeof key === 'string' ? { apiKey: key } : key;
+
 interface UseSpeakerDiarizationOptions {
-  getApiKey: (sessionId: string) => Promise<string>;
+  getApiKey: (sessionId: string) => Promise<string | TemporaryKey>;
   sessionId: string;
   onTranscriptUpdate?: (segments: SpeakerSegment[], speakerMapping: Map<number, string>) => void;
   onSessionStarted?: () => void;
@@ -46,12 +56,17 @@ export default function useSpeakerDiarization({
   const confirmedSpeakersRef = useRef<Set<number>>(new Set()); // Track confirmed speakers
   const sessionStartTimeRef = useRef<number>(0);
   const manualCorrectionsRef = useRef<Map<number, string>>(new Map()); // Track manual corrections
+  const temporaryKeyRef = useRef<TemporaryKey | null>(null); // Key fetched for the vocabulary, used once
 
   // Initialize client
   useEffect(() => {
     if (!clientRef.current) {
       clientRef.current = new SonioxClient({
-        apiKey: () => getApiKey(sessionId),
+        apiKey: async () => {
+          const key = temporaryKeyRef.current;
+          temporaryKeyRef.current = null;
+          return key ? key.apiKey : toTemporaryKey(await getApiKey(sessionId)).apiKey;
+        },
       });
     }
 
@@ -291,9 +306,13 @@ export default function useSpeakerDiarization({
     }
 
     try {
+      // Fetch the key up front: it carries the session's language hints and vocabulary
+      const key = toTemporaryKey(await getApiKey(sessionId));
+      temporaryKeyRef.current = key;
       await clientRef.current.start({
         model: "stt-rt-preview-v2",
-        languageHints: ['vi', 'en'], // Vietnamese and English
+        languageHints: key.languageHints?.length ? key.languageHints : ['vi', 'en'], // Vietnamese and English by default
+        context: key.context || undefined,
         enableLanguageIdentification: true,
         enableSpeakerDiarization: true, // Enable speaker diarization
         // Speaker diarization is enabled by default settings
@@ -419,7 +438,7 @@ export default function useSpeakerDiarization({
         errorCode: undefined
       });
     }
-  }, [processTokensIntoSegments, identifySpeakersFromTranscript, onSessionStarted, onSessionFinished, onTranscriptUpdate]);
+  }, [processTokensIntoSegments, identifySpeakersFromTranscript, onSessionStarted, onSessionFinished, onTranscriptUpdate, getApiKey, sessionId]);
 
   // Stop transcription
   const stopTranscription = useCallback(() => {
@@ -0,0 +1,119 @@
+package assessment
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	assessmentService "newing.vn/competency/backend/internal/service/assessment"
+)
+
+// webhookEventTypes are the events a subscription may ask for
+var webhookEventTypes = map[string]bool{
+	assessmentService.EventParticipantAssessed:    true,
+	assessmentService.EventGroupAssessed:          true,
+	assessmentService.EventSessionConsolidated:    true,
+	assessmentService.EventTranscriptionCompleted: true,
+	assessmentService.EventTranscriptionFailed:    true,
+	assessmentService.EventJobFailed:              true,
+}
+
+// WebhookHandler manages webhook subscriptions and their delivery log
+type WebhookHandler struct {
+	dispatcher *assessmentService.WebhookDispatcher
+}
+
+// NewWebhookHandler creates a new webhook handler
+func NewWebhookHandler(dispatcher *assessmentService.WebhookDispatcher) *WebhookHandler {
+	return &WebhookHandler{dispatcher: dispatcher}
+}
+
+// CreateWebhookRequest registers a client's webhook. Empty events subscribes to every event.
+type CreateWebhookRequest struct {
+	ClientID string   `json:"client_id" binding:"required"`
+	URL      string   `json:"url" binding:"required"`
+	Events   []string `json:"events"`
+}
+
+// CreateWebhook handles POST /api/v1/webhooks. The signing secret is only returned here.
+func (h *WebhookHandler) CreateWebhook(c *gin.Context) {
+	var req CreateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	for _, event := range req.Events {
+		if !webhookEventTypes[event] {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "unknown event type: " + event})
+			return
+		}
+	}
+
+	subscription, err := h.dispatcher.Subscribe(c.Request.Context(), req.ClientID, req.URL, req.Events)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"webhook": subscription,
+		"secret":  subscription.Secret,
+	})
+}
+
+// ListWebhooks handles GET /api/v1/webhooks
+func (h *WebhookHandler) ListWebhooks(c *gin.Context) {
+	subscriptions, err := h.dispatcher.Store().ListSubscriptions(c.Request.Context(), c.Query("client_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list webhooks"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"webhooks": subscriptions})
+}
+
+// DeleteWebhook handles DELETE /api/v1/webhooks/:webhookId
+func (h *WebhookHandler) DeleteWebhook(c *gin.Context) {
+	err := h.dispatcher.Store().DeleteSubscription(c.Request.Context(), c.Param("webhookId"))
+	if errors.Is(err, assessmentService.ErrWebhookNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "webhook not found"})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete webhook"})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// ListWebhookDeliveries handles GET /api/v1/webhooks/:webhookId/deliveries
+func (h *WebhookHandler) ListWebhookDeliveries(c *gin.Context) {
+	webhookID := c.Param("webhookId")
+	if _, err := h.dispatcher.Store().GetSubscription(c.Request.Context(), webhookID); errors.Is(err, assessmentService.ErrWebhookNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "webhook not found"})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to load webhook"})
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	deliveries, err := h.dispatcher.Store().ListDeliveries(c.Request.Context(), webhookID, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list deliveries"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"deliveries": deliveries})
+}
+
+// TestWebhook handles POST /api/v1/webhooks/:webhookId/test
+func (h *WebhookHandler) TestWebhook(c *gin.Context) {
+	delivery, err := h.dispatcher.SendTest(c.Request.Context(), c.Param("webhookId"))
+	if errors.Is(err, assessmentService.ErrWebhookNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "webhook not found"})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to send test webhook"})
+		return
+	}
+	c.JSON(http.StatusOK, delivery)
+}
@@ -0,0 +1,556 @@
+package assessment
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// JobTypeWebhookDelivery delivers one event to one webhook subscription
+const JobTypeWebhookDelivery = "webhook_delivery"
+
+// Webhook request headers. The signature is "v1=" followed by the hex HMAC-SHA256 of
+// "<timestamp>.<body>" keyed with the subscription secret.
+const (
+	WebhookIDHeader        = "X-Webhook-Id"
+	WebhookEventHeader     = "X-Webhook-Event"
+	WebhookTimestampHeader = "X-Webhook-Timestamp"
+	WebhookSignatureHeader = "X-Webhook-Signature"
+)
+
+var (
+	// ErrWebhookNotFound is returned when a subscription ID is unknown
+	ErrWebhookNotFound = errors.New("webhook subscription not found")
+	// ErrInvalidWebhookSignature is returned by VerifyWebhookSignature
+	ErrInvalidWebhookSignature = errors.New("invalid webhook signature")
+	// ErrInvalidWebhookURL is returned for webhook URLs deliveries may not be sent to
+	ErrInvalidWebhookURL = errors.New("invalid webhook url")
+	// ErrWebhookClientRequired is returned when a subscription names no client
+	ErrWebhookClientRequired = errors.New("webhook client_id is required")
+)
+
+// WebhookSubscription registers a client URL for assessment events
+type WebhookSubscription struct {
+	ID       string `json:"id"`
+	ClientID string `json:"client_id"` // Receives events for this client's sessions only
+	URL      string `json:"url"`
+	// Secret signs deliveries; it is only returned to the caller when the subscription is created
+	Secret    string    `json:"-"`
+	Events    []string  `json:"events"` // Empty subscribes to every event type
+	Active    bool      `json:"active"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Wants reports whether the subscription receives the event type
+func (s *WebhookSubscription) Wants(eventType string) bool {
+	if !s.Active {
+		return false
+	}
+	if len(s.Events) == 0 || eventType == EventPing {
+		return true
+	}
+	for _, wanted := range s.Events {
+		if wanted == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookDelivery is one attempt to deliver an event, kept as the delivery log
+type WebhookDelivery struct {
+	ID             string    `json:"id"`
+	SubscriptionID string    `json:"subscription_id"`
+	EventID        string    `json:"event_id"`
+	EventType      string    `json:"event_type"`
+	SessionID      string    `json:"session_id"`
+	Attempt        int       `json:"attempt"`
+	StatusCode     int       `json:"status_code,omitempty"`
+	Error          string    `json:"error,omitempty"`
+	DurationMs     int64     `json:"duration_ms"`
+	Succeeded      bool      `json:"succeeded"`
+	DeliveredAt    time.Time `json:"delivered_at"`
+}
+
+// WebhookStore persists subscriptions and the delivery log
+type WebhookStore interface {
+	CreateSubscription(ctx context.Context, subscription *WebhookSubscription) error
+	GetSubscription(ctx context.Context, subscriptionID string) (*WebhookSubscription, error)
+	// ListSubscriptions returns a client's subscriptions, or every subscription when clientID is empty
+	ListSubscriptions(ctx context.Context, clientID string) ([]*WebhookSubscription, error)
+	DeleteSubscription(ctx context.Context, subscriptionID string) error
+
+	RecordDelivery(ctx context.Context, delivery WebhookDelivery) error
+	// ListDeliveries returns a subscription's most recent deliveries, newest first
+	ListDeliveries(ctx context.Context, subscriptionID string, limit int) ([]WebhookDelivery, error)
+}
+
+// SessionClientResolver returns the client that owns a session so client webhooks
+// only receive their own sessions' events. Events of a session without a client are
+// not delivered to any webhook.
+type SessionClientResolver func(ctx context.Context, sessionID string) (string, error)
+
+// WebhookConfig limits where webhooks may be delivered. By default only https URLs of
+// public hosts are accepted, so deliveries cannot be pointed at internal services.
+type WebhookConfig struct {
+	// AllowPrivateURLs accepts http URLs and loopback, private and link-local targets,
+	// for local development with the webhook receiver
+	AllowPrivateURLs bool
+	// AllowedHosts are hostnames that may resolve to private addresses, e.g. a client
+	// HRIS reached over a private network; they still need https
+	AllowedHosts []string
+}
+
+// WebhookConfigFromEnv reads WEBHOOK_ALLOW_PRIVATE_URLS ("true" for local development)
+// and WEBHOOK_ALLOWED_HOSTS (comma separated hostnames)
+func WebhookConfigFromEnv() WebhookConfig {
+	config := WebhookConfig{AllowPrivateURLs: os.Getenv("WEBHOOK_ALLOW_PRIVATE_URLS") == "true"}
+	for _, host := range strings.Split(os.Getenv("WEBHOOK_ALLOWED_HOSTS"), ",") {
+		if host = strings.ToLower(strings.TrimSpace(host)); host != "" {
+			config.AllowedHosts = append(config.AllowedHosts, host)
+		}
+	}
+	return config
+}
+
+// nonPublicPrefixes are address ranges outside the net.IP private, loopback and
+// link-local checks that are still not reachable on the public internet
+var nonPublicPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("64:ff9b::/96"),
+}
+
+// publicAddress reports whether ip is a public unicast address
+func publicAddress(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified() {
+		return false
+	}
+	addr, ok := netip.AddrFromSlice(ip)
+	if !ok {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, prefix := range nonPublicPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// allowedHost reports whether a hostname may resolve to private addresses
+func (c WebhookConfig) allowedHost(host string) bool {
+	if c.AllowPrivateURLs {
+		return true
+	}
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	for _, allowed := range c.AllowedHosts {
+		if host == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// checkURL rejects webhook URLs that are not https, or whose host is or resolves to a
+// non-public address, unless the config allows them
+func (c WebhookConfig) checkURL(ctx context.Context, rawURL string) error {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.Host == "" || parsed.User != nil {
+		return fmt.Errorf("%w: must be an absolute https URL", ErrInvalidWebhookURL)
+	}
+	switch {
+	case parsed.Scheme == "https":
+	case parsed.Scheme == "http" && c.AllowPrivateURLs:
+	default:
+		return fmt.Errorf("%w: must be an absolute https URL", ErrInvalidWebhookURL)
+	}
+
+	host := parsed.Hostname()
+	if c.allowedHost(host) {
+		return nil
+	}
+	if strings.EqualFold(strings.TrimSuffix(host, "."), "localhost") {
+		return fmt.Errorf("%w: %s is not a public host", ErrInvalidWebhookURL, host)
+	}
+	addrs := []net.IP{net.ParseIP(host)}
+	if addrs[0] == nil {
+		// Hosts that do not resolve yet are checked again on every delivery
+		resolved, err := net.DefaultResolver.LookupIP(ctx, "ip", host)
+		if err != nil {
+			return nil
+		}
+		addrs = resolved
+	}
+	for _, addr := range addrs {
+		if !publicAddress(addr) {
+			return fmt.Errorf("%w: %s is not a public host", ErrInvalidWebhookURL, host)
+		}
+	}
+	return nil
+}
+
+// dialContext connects deliveries to public addresses only, resolving the host itself
+// so a hostname cannot be re-pointed at an internal address after it was checked
+func (c WebhookConfig) dialContext(dialer *net.Dialer) func(ctx context.Context, network, address string) (net.Conn, error) {
+	return func(ctx context.Context, network, address string) (net.Conn, error) {
+		host, port, err := net.SplitHostPort(address)
+		if err != nil {
+			return nil, err
+		}
+		if c.allowedHost(host) {
+			return dialer.DialContext(ctx, network, address)
+		}
+
+		addrs, err := net.DefaultResolver.LookupIP(ctx, "ip", host)
+		if err != nil {
+			return nil, err
+		}
+		for _, addr := range addrs {
+			if !publicAddress(addr) {
+				return nil, fmt.Errorf("%w: %s resolves to non-public address %s", ErrInvalidWebhookURL, host, addr)
+			}
+		}
+		var dialErr error
+		for _, addr := range addrs {
+			conn, err := dialer.DialContext(ctx, network, net.JoinHostPort(addr.String(), port))
+			if err == nil {
+				return conn, nil
+			}
+			dialErr = err
+		}
+		return nil, dialErr
+	}
+}
+
+// SignWebhook returns the signature header value for a payload
+func SignWebhook(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "v1=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyWebhookSignature checks a delivery's signature and rejects timestamps older
+// than tolerance to stop replays
+func VerifyWebhookSignature(secret, timestampHeader, signatureHeader string, body []byte, tolerance time.Duration) error {
+	timestamp, err := strconv.ParseInt(timestampHeader, 10, 64)
+	if err != nil {
+		return fmt.Errorf("%w: bad timestamp", ErrInvalidWebhookSignature)
+	}
+	if age := time.Since(time.Unix(timestamp, 0)); tolerance > 0 && (age > tolerance || age < -tolerance) {
+		return fmt.Errorf("%w: timestamp outside tolerance", ErrInvalidWebhookSignature)
+	}
+
+	expected := SignWebhook(secret, timestamp, body)
+	if !hmac.Equal([]byte(expected), []byte(signatureHeader)) {
+		return ErrInvalidWebhookSignature
+	}
+	return nil
+}
+
+// webhookDeliveryPayload is the queued payload of a delivery job
+type webhookDeliveryPayload struct {
+	SubscriptionID string          `json:"subscription_id"`
+	Event          AssessmentEvent `json:"event"`
+}
+
+// WebhookDispatcher turns assessment events into signed webhook deliveries. Deliveries
+// run on the job queue, so failed deliveries are retried with its retry policy.
+type WebhookDispatcher struct {
+	store         WebhookStore
+	queue         *JobQueue
+	resolveClient SessionClientResolver
+	config        WebhookConfig
+	client        *http.Client
+	logger        *AssessmentLogger
+}
+
+// NewWebhookDispatcher creates a dispatcher and registers its delivery handler on queue.
+// Without resolveClient no session has a client, so no events are delivered.
+func NewWebhookDispatcher(store WebhookStore, queue *JobQueue, resolveClient SessionClientResolver, config WebhookConfig, logger *AssessmentLogger) *WebhookDispatcher {
+	if resolveClient == nil {
+		logger.Warn("no session client resolver configured, webhook events will not be delivered")
+		resolveClient = func(ctx context.Context, sessionID string) (string, error) { return "", nil }
+	}
+
+	// No proxy: deliveries must go through the dialer's address checks
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = config.dialContext(&net.Dialer{Timeout: 5 * time.Second})
+	d := &WebhookDispatcher{
+		store:         store,
+		queue:         queue,
+		resolveClient: resolveClient,
+		config:        config,
+		client: &http.Client{
+			Timeout:   10 * time.Second,
+			Transport: transport,
+			// A redirect would send the signed payload somewhere that was never checked
+			CheckRedirect: func(req *http.Request, via []*http.Request) error { return http.ErrUseLastResponse },
+		},
+		logger: logger,
+	}
+	queue.Register(JobTypeWebhookDelivery, d.deliverJob)
+	return d
+}
+
+// Subscribe registers a client's webhook and generates its signing secret
+func (d *WebhookDispatcher) Subscribe(ctx context.Context, clientID, rawURL string, events []string) (*WebhookSubscription, error) {
+	if strings.TrimSpace(clientID) == "" {
+		return nil, ErrWebhookClientRequired
+	}
+	if err := d.config.checkURL(ctx, rawURL); err != nil {
+		return nil, err
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, err
+	}
+
+	subscription := &WebhookSubscription{
+		ID:        newRecordID(),
+		ClientID:  clientID,
+		URL:       rawURL,
+		Secret:    "whsec_" + hex.EncodeToString(secret),
+		Events:    events,
+		Active:    true,
+		CreatedAt: time.Now().UTC(),
+	}
+	if err := d.store.CreateSubscription(ctx, subscription); err != nil {
+		return nil, err
+	}
+	return subscription, nil
+}
+
+// Store returns the subscription store
+func (d *WebhookDispatcher) Store() WebhookStore {
+	return d.store
+}
+
+// Publish queues a delivery to every active subscription of the session's client that
+// wants the event
+func (d *WebhookDispatcher) Publish(ctx context.Context, event AssessmentEvent) {
+	logger := d.logger.ForSession(event.SessionID).With(slog.String("event_type", event.Type), slog.String("event_id", event.ID))
+
+	clientID, err := d.resolveClient(ctx, event.SessionID)
+	if err != nil {
+		logger.Error("failed to resolve session client for webhooks", slog.String("error", err.Error()))
+		return
+	}
+	if clientID == "" {
+		logger.Debug("session has no client, no webhooks to deliver")
+		return
+	}
+
+	subscriptions, err := d.store.ListSubscriptions(ctx, clientID)
+	if err != nil {
+		logger.Error("failed to list webhook subscriptions", slog.String("error", err.Error()))
+		return
+	}
+
+	for _, subscription := range subscriptions {
+		if subscription.ClientID != clientID || !subscription.Wants(event.Type) {
+			continue
+		}
+
+		payload := webhookDeliveryPayload{SubscriptionID: subscription.ID, Event: event}
+		if _, err := d.queue.Enqueue(ctx, JobTypeWebhookDelivery, event.SessionID, "", payload); err != nil {
+			logger.Error("failed to queue webhook delivery", slog.String("subscription_id", subscription.ID), slog.String("error", err.Error()))
+		}
+	}
+}
+
+// SendTest delivers a ping event to a subscription immediately and returns the logged delivery
+func (d *WebhookDispatcher) SendTest(ctx context.Context, subscriptionID string) (*WebhookDelivery, error) {
+	subscription, err := d.store.GetSubscription(ctx, subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	event := NewAssessmentEvent(EventPing, "", map[string]string{"message": "webhook test"})
+	delivery := d.send(ctx, subscription, event, 1)
+	if err := d.store.RecordDelivery(ctx, delivery); err != nil {
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+// deliverJob is the queue handler for webhook deliveries
+func (d *WebhookDispatcher) deliverJob(ctx context.Context, job *Job) error {
+	var payload webhookDeliveryPayload
+	if err := json.Unmarshal(job.Payload, &payload); err != nil {
+		return fmt.Errorf("invalid webhook payload: %w", err)
+	}
+
+	subscription, err := d.store.GetSubscription(ctx, payload.SubscriptionID)
+	if errors.Is(err, ErrWebhookNotFound) {
+		// Deleted since the event was queued
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if !subscription.Active {
+		return nil
+	}
+
+	delivery := d.send(ctx, subscription, payload.Event, job.Attempts)
+	if err := d.store.RecordDelivery(ctx, delivery); err != nil {
+		d.logger.Error("failed to record webhook delivery", slog.String("subscription_id", subscription.ID), slog.String("error", err.Error()))
+	}
+	if !delivery.Succeeded {
+		return fmt.Errorf("webhook delivery failed: %s", delivery.Error)
+	}
+	return nil
+}
+
+// send makes one signed delivery attempt
+func (d *WebhookDispatcher) send(ctx context.Context, subscription *WebhookSubscription, event AssessmentEvent, attempt int) WebhookDelivery {
+	delivery := WebhookDelivery{
+		ID:             newRecordID(),
+		SubscriptionID: subscription.ID,
+		EventID:        event.ID,
+		EventType:      event.Type,
+		SessionID:      event.SessionID,
+		Attempt:        attempt,
+		DeliveredAt:    time.Now().UTC(),
+	}
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		delivery.Error = err.Error()
+		return delivery
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", subscription.URL, bytes.NewReader(body))
+	if err != nil {
+		delivery.Error = err.Error()
+		return delivery
+	}
+
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "competency-assessment-webhooks/1")
+	req.Header.Set(WebhookIDHeader, event.ID)
+	req.Header.Set(WebhookEventHeader, event.Type)
+	req.Header.Set(WebhookTimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(WebhookSignatureHeader, SignWebhook(subscription.Secret, timestamp, body))
+
+	started := time.Now()
+	resp, err := d.client.Do(req)
+	delivery.DurationMs = time.Since(started).Milliseconds()
+	if err != nil {
+		delivery.Error = err.Error()
+		return delivery
+	}
+	defer resp.Body.Close()
+
+	delivery.StatusCode = resp.StatusCode
+	delivery.Succeeded = resp.StatusCode >= 200 && resp.StatusCode < 300
+	if !delivery.Succeeded {
+		snippet, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		delivery.Error = fmt.Sprintf("receiver returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(snippet)))
+	}
+	return delivery
+}
+
+// MemoryWebhookStore is a process-local WebhookStore for demos and local development
+type MemoryWebhookStore struct {
+	mu            sync.RWMutex
+	subscriptions map[string]*WebhookSubscription
+	deliveries    map[string][]WebhookDelivery
+}
+
+// NewMemoryWebhookStore creates an empty in-memory webhook store
+func NewMemoryWebhookStore() *MemoryWebhookStore {
+	return &MemoryWebhookStore{
+		subscriptions: make(map[string]*WebhookSubscription),
+		deliveries:    make(map[string][]WebhookDelivery),
+	}
+}
+
+func (s *MemoryWebhookStore) CreateSubscription(ctx context.Context, subscription *WebhookSubscription) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stored := *subscription
+	s.subscriptions[subscription.ID] = &stored
+	return nil
+}
+
+func (s *MemoryWebhookStore) GetSubscription(ctx context.Context, subscriptionID string) (*WebhookSubscription, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	subscription, ok := s.subscriptions[subscriptionID]
+	if !ok {
+		return nil, ErrWebhookNotFound
+	}
+	found := *subscription
+	return &found, nil
+}
+
+func (s *MemoryWebhookStore) ListSubscriptions(ctx context.Context, clientID string) ([]*WebhookSubscription, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	subscriptions := []*WebhookSubscription{}
+	for _, subscription := range s.subscriptions {
+		if clientID == "" || subscription.ClientID == clientID {
+			found := *subscription
+			subscriptions = append(subscriptions, &found)
+		}
+	}
+	sort.Slice(subscriptions, func(i, j int) bool { return subscriptions[i].CreatedAt.Before(subscriptions[j].CreatedAt) })
+	return subscriptions, nil
+}
+
+func (s *MemoryWebhookStore) DeleteSubscription(ctx context.Context, subscriptionID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.subscriptions[subscriptionID]; !ok {
+		return ErrWebhookNotFound
+	}
+	delete(s.subscriptions, subscriptionID)
+	delete(s.deliveries, subscriptionID)
+	return nil
+}
+
+func (s *MemoryWebhookStore) RecordDelivery(ctx context.Context, delivery WebhookDelivery) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.deliveries[delivery.SubscriptionID] = append(s.deliveries[delivery.SubscriptionID], delivery)
+	return nil
+}
+
+func (s *MemoryWebhookStore) ListDeliveries(ctx context.Context, subscriptionID string, limit int) ([]WebhookDelivery, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	all := s.deliveries[subscriptionID]
+	deliveries := make([]WebhookDelivery, 0, len(all))
+	for i := len(all) - 1; i >= 0 && (limit <= 0 || len(deliveries) < limit); i-- {
+		deliveries = append(deliveries, all[i])
+	}
+	return deliveries, nil
+}
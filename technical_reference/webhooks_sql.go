@@ -0,0 +1,148 @@
+package assessment
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// SQLWebhookStore is a WebhookStore backed by PostgreSQL or SQLite
+type SQLWebhookStore struct {
+	db      *sql.DB
+	dialect SQLDialect
+}
+
+// NewSQLWebhookStore creates a webhook store on an open database
+func NewSQLWebhookStore(db *sql.DB, dialect SQLDialect) *SQLWebhookStore {
+	return &SQLWebhookStore{db: db, dialect: dialect}
+}
+
+// Migrate applies the embedded migrations that have not been applied yet
+func (s *SQLWebhookStore) Migrate(ctx context.Context) error {
+	return migrate(ctx, s.db, s.dialect)
+}
+
+func (s *SQLWebhookStore) CreateSubscription(ctx context.Context, subscription *WebhookSubscription) error {
+	events, err := json.Marshal(subscription.Events)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.db.ExecContext(ctx, rebind(s.dialect, `
+		INSERT INTO webhook_subscriptions (id, client_id, url, secret, events_json, active, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`),
+		subscription.ID, subscription.ClientID, subscription.URL, subscription.Secret, string(events),
+		subscription.Active, subscription.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save webhook subscription: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLWebhookStore) GetSubscription(ctx context.Context, subscriptionID string) (*WebhookSubscription, error) {
+	subscription, err := scanWebhookSubscription(s.db.QueryRowContext(ctx, rebind(s.dialect, `
+		SELECT id, client_id, url, secret, events_json, active, created_at
+		FROM webhook_subscriptions WHERE id = ?`), subscriptionID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrWebhookNotFound
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to load webhook subscription: %w", err)
+	}
+	return subscription, nil
+}
+
+func (s *SQLWebhookStore) ListSubscriptions(ctx context.Context, clientID string) ([]*WebhookSubscription, error) {
+	query := `SELECT id, client_id, url, secret, events_json, active, created_at FROM webhook_subscriptions`
+	args := []any{}
+	if clientID != "" {
+		query += ` WHERE client_id = ?`
+		args = append(args, clientID)
+	}
+
+	rows, err := s.db.QueryContext(ctx, rebind(s.dialect, query+` ORDER BY created_at`), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhook subscriptions: %w", err)
+	}
+	defer rows.Close()
+
+	subscriptions := []*WebhookSubscription{}
+	for rows.Next() {
+		subscription, err := scanWebhookSubscription(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load webhook subscription: %w", err)
+		}
+		subscriptions = append(subscriptions, subscription)
+	}
+	return subscriptions, rows.Err()
+}
+
+func (s *SQLWebhookStore) DeleteSubscription(ctx context.Context, subscriptionID string) error {
+	result, err := s.db.ExecContext(ctx, rebind(s.dialect, `DELETE FROM webhook_subscriptions WHERE id = ?`), subscriptionID)
+	if err != nil {
+		return fmt.Errorf("failed to delete webhook subscription: %w", err)
+	}
+	if deleted, err := result.RowsAffected(); err == nil && deleted == 0 {
+		return ErrWebhookNotFound
+	}
+
+	if _, err := s.db.ExecContext(ctx, rebind(s.dialect, `DELETE FROM webhook_deliveries WHERE subscription_id = ?`), subscriptionID); err != nil {
+		return fmt.Errorf("failed to delete webhook deliveries: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLWebhookStore) RecordDelivery(ctx context.Context, delivery WebhookDelivery) error {
+	_, err := s.db.ExecContext(ctx, rebind(s.dialect, `
+		INSERT INTO webhook_deliveries
+			(id, subscription_id, event_id, event_type, session_id, attempt, status_code, error, duration_ms, succeeded, delivered_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`),
+		delivery.ID, delivery.SubscriptionID, delivery.EventID, delivery.EventType, delivery.SessionID,
+		delivery.Attempt, delivery.StatusCode, delivery.Error, delivery.DurationMs, delivery.Succeeded, delivery.DeliveredAt)
+	if err != nil {
+		return fmt.Errorf("failed to record webhook delivery: %w", err)
+	}
+	return nil
+}
+
+func (s *SQLWebhookStore) ListDeliveries(ctx context.Context, subscriptionID string, limit int) ([]WebhookDelivery, error) {
+	if limit <= 0 {
+		limit = 100
+	}
+
+	rows, err := s.db.QueryContext(ctx, rebind(s.dialect, `
+		SELECT id, subscription_id, event_id, event_type, session_id, attempt, status_code, error, duration_ms, succeeded, delivered_at
+		FROM webhook_deliveries
+		WHERE subscription_id = ?
+		ORDER BY delivered_at DESC
+		LIMIT ?`), subscriptionID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhook deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	deliveries := []WebhookDelivery{}
+	for rows.Next() {
+		var delivery WebhookDelivery
+		if err := rows.Scan(&delivery.ID, &delivery.SubscriptionID, &delivery.EventID, &delivery.EventType, &delivery.SessionID,
+			&delivery.Attempt, &delivery.StatusCode, &delivery.Error, &delivery.DurationMs, &delivery.Succeeded, &delivery.DeliveredAt); err != nil {
+			return nil, fmt.Errorf("failed to load webhook delivery: %w", err)
+		}
+		deliveries = append(deliveries, delivery)
+	}
+	return deliveries, rows.Err()
+}
+
+func scanWebhookSubscription(row rowScanner) (*WebhookSubscription, error) {
+	var subscription WebhookSubscription
+	var events string
+	if err := row.Scan(&subscription.ID, &subscription.ClientID, &subscription.URL, &subscription.Secret,
+		&events, &subscription.Active, &subscription.CreatedAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(events), &subscription.Events); err != nil {
+		return nil, fmt.Errorf("failed to decode webhook events: %w", err)
+	}
+	return &subscription, nil
+}